@@ -0,0 +1,42 @@
+#!/bin/bash
+# Generates a throwaway CA and broker keystore for the secured Kafka stack.
+
+set -e
+
+cd "$(dirname "$0")"
+mkdir -p secrets
+cd secrets
+
+PASSWORD="changeit"
+
+echo "Generating CA..."
+openssl req -x509 -newkey rsa:2048 -nodes -days 365 \
+    -subj "/CN=reportmaxxing-local-ca" \
+    -keyout ca.key -out ca.crt
+
+echo "Generating broker certificate..."
+openssl req -newkey rsa:2048 -nodes \
+    -subj "/CN=localhost" \
+    -keyout broker.key -out broker.csr
+openssl x509 -req -days 365 -in broker.csr \
+    -CA ca.crt -CAkey ca.key -CAcreateserial \
+    -extfile <(printf "subjectAltName=DNS:localhost,DNS:kafka-secure,IP:127.0.0.1") \
+    -out broker.crt
+
+openssl pkcs12 -export -name broker \
+    -in broker.crt -inkey broker.key -certfile ca.crt \
+    -passout "pass:${PASSWORD}" -out broker.keystore.p12
+
+echo "${PASSWORD}" > keystore_creds
+echo "${PASSWORD}" > key_creds
+
+cat > kafka_server_jaas.conf <<JAAS
+KafkaServer {
+    org.apache.kafka.common.security.scram.ScramLoginModule required;
+    org.apache.kafka.common.security.plain.PlainLoginModule required
+        user_reportsvc="reportsvc-secret";
+};
+JAAS
+
+rm -f broker.csr ca.srl
+echo "Secrets written to $(pwd)"
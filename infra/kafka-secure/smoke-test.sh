@@ -0,0 +1,26 @@
+#!/bin/bash
+# Starts the secured Kafka stack and checks the report service's Kafka client
+# can produce and consume over SASL_SSL with PLAIN, SCRAM-SHA-256 and
+# SCRAM-SHA-512.
+
+set -e
+
+cd "$(dirname "$0")"
+[ -f secrets/ca.crt ] || ./gen-certs.sh
+docker compose up -d
+
+echo "Waiting for the SASL_SSL listener on localhost:9094..."
+for _ in $(seq 1 60); do
+    if openssl s_client -connect localhost:9094 -CAfile secrets/ca.crt </dev/null >/dev/null 2>&1; then
+        break
+    fi
+    sleep 2
+done
+# The SCRAM credentials are added by a one-shot container after the broker
+# starts.
+docker wait reportmaxxing-kafka-secure-users >/dev/null
+
+cd ../../services/report-management-service
+KAFKA_SECURE_BROKERS=localhost:9094 \
+KAFKA_SECURE_CA_FILE="$(pwd)/../../infra/kafka-secure/secrets/ca.crt" \
+    go test -count=1 -run TestSecureRoundTrip -v ./kafka
@@ -124,8 +124,55 @@ curl -s -X POST "${KEYCLOAK_URL}/admin/realms/${REALM}/roles" \
     -H "Authorization: Bearer ${ADMIN_TOKEN}" \
     -H "Content-Type: application/json" \
     -d '{"name": "DEPARTMENT_STAFF", "description": "Can update report status and view all reports"}' 2>/dev/null || true
+
+for role in "SUPERVISOR:Can manage reports across all departments" \
+            "AUDITOR:Read-only access to all reports" \
+            "ADMIN:Full access"; do
+    curl -s -X POST "${KEYCLOAK_URL}/admin/realms/${REALM}/roles" \
+        -H "Authorization: Bearer ${ADMIN_TOKEN}" \
+        -H "Content-Type: application/json" \
+        -d "{\"name\": \"${role%%:*}\", \"description\": \"${role#*:}\"}" 2>/dev/null || true
+done
 echo "Roles created"
 
+# Department groups scope staff to report categories (/departments/<category>)
+echo "Creating department groups..."
+curl -s -X POST "${KEYCLOAK_URL}/admin/realms/${REALM}/groups" \
+    -H "Authorization: Bearer ${ADMIN_TOKEN}" \
+    -H "Content-Type: application/json" \
+    -d '{"name": "departments"}' 2>/dev/null || true
+
+DEPARTMENTS_GROUP_ID=$(curl -s "${KEYCLOAK_URL}/admin/realms/${REALM}/groups?search=departments&exact=true" \
+    -H "Authorization: Bearer ${ADMIN_TOKEN}" | python3 -c "import sys, json; groups=json.load(sys.stdin); print(groups[0]['id'] if groups else '')")
+
+for DEPARTMENT in crime sanitation health; do
+    curl -s -X POST "${KEYCLOAK_URL}/admin/realms/${REALM}/groups/${DEPARTMENTS_GROUP_ID}/children" \
+        -H "Authorization: Bearer ${ADMIN_TOKEN}" \
+        -H "Content-Type: application/json" \
+        -d "{\"name\": \"${DEPARTMENT}\"}" 2>/dev/null || true
+done
+echo "Department groups created"
+
+# Expose group membership (full path) in access tokens
+CLIENT_DB_ID=$(curl -s "${KEYCLOAK_URL}/admin/realms/${REALM}/clients?clientId=${CLIENT_ID}" \
+    -H "Authorization: Bearer ${ADMIN_TOKEN}" | python3 -c "import sys, json; clients=json.load(sys.stdin); print(clients[0]['id'] if clients else '')")
+curl -s -X POST "${KEYCLOAK_URL}/admin/realms/${REALM}/clients/${CLIENT_DB_ID}/protocol-mappers/models" \
+    -H "Authorization: Bearer ${ADMIN_TOKEN}" \
+    -H "Content-Type: application/json" \
+    -d '{
+        "name": "groups",
+        "protocol": "openid-connect",
+        "protocolMapper": "oidc-group-membership-mapper",
+        "config": {
+            "claim.name": "groups",
+            "full.path": "true",
+            "access.token.claim": "true",
+            "id.token.claim": "false",
+            "userinfo.token.claim": "true"
+        }
+    }' 2>/dev/null || true
+echo "Groups mapper configured"
+
 # Get role IDs
 CITIZEN_ROLE=$(curl -s "${KEYCLOAK_URL}/admin/realms/${REALM}/roles/CITIZEN" \
     -H "Authorization: Bearer ${ADMIN_TOKEN}")
@@ -213,6 +260,38 @@ else
     echo "Staff user already exists or failed to create"
 fi
 
+# Confidential clients for partner integrations (client credentials grant)
+echo "Creating service-account clients..."
+for SERVICE_CLIENT in call-center city-dashboard; do
+    curl -s -X POST "${KEYCLOAK_URL}/admin/realms/${REALM}/clients" \
+        -H "Authorization: Bearer ${ADMIN_TOKEN}" \
+        -H "Content-Type: application/json" \
+        -d "{
+            \"clientId\": \"${SERVICE_CLIENT}\",
+            \"enabled\": true,
+            \"publicClient\": false,
+            \"serviceAccountsEnabled\": true,
+            \"standardFlowEnabled\": false,
+            \"directAccessGrantsEnabled\": false,
+            \"protocol\": \"openid-connect\"
+        }" 2>/dev/null || true
+done
+echo "Service-account clients created (fetch their secrets from the admin console)"
+
+# Confidential client the API uses to introspect tokens on sensitive routes
+curl -s -X POST "${KEYCLOAK_URL}/admin/realms/${REALM}/clients" \
+    -H "Authorization: Bearer ${ADMIN_TOKEN}" \
+    -H "Content-Type: application/json" \
+    -d '{
+        "clientId": "report-api",
+        "enabled": true,
+        "publicClient": false,
+        "standardFlowEnabled": false,
+        "directAccessGrantsEnabled": false,
+        "protocol": "openid-connect"
+    }' 2>/dev/null || true
+echo "Introspection client report-api created"
+
 echo ""
 echo "=========================================="
 echo "Keycloak setup complete!"
@@ -225,6 +304,8 @@ echo "Test Users:"
 echo "  Citizen: citizen@test.com / citizen123"
 echo "  Staff:   staff@test.com / staff123"
 echo ""
+echo "Service-account clients: call-center, city-dashboard"
+echo ""
 echo "Keycloak Admin Console: ${KEYCLOAK_URL}/admin"
 echo "  Username: ${ADMIN_USER}"
 echo "  Password: ${ADMIN_PASS}"
@@ -0,0 +1,89 @@
+// Generates src/api/schema.ts from the report service's OpenAPI document, so
+// the API types can't drift from the Go JSON. Needs Go; run it with
+// `npm run generate:api` after changing services/report-management-service/openapi/openapi.yaml.
+import { execFileSync } from 'node:child_process';
+import { writeFileSync } from 'node:fs';
+import { dirname, resolve } from 'node:path';
+import { fileURLToPath } from 'node:url';
+
+const root = resolve(dirname(fileURLToPath(import.meta.url)), '..');
+const serviceDir = resolve(root, '../services/report-management-service');
+const outFile = resolve(root, 'src/api/schema.ts');
+
+const doc = JSON.parse(
+  execFileSync('go', ['run', '.', 'openapi'], { cwd: serviceDir, encoding: 'utf8', maxBuffer: 16 * 1024 * 1024 })
+);
+
+function refName(ref) {
+  return ref.slice(ref.lastIndexOf('/') + 1);
+}
+
+function indent(text, depth) {
+  return text.replace(/\n/g, '\n' + '  '.repeat(depth));
+}
+
+function comment(schema) {
+  if (!schema.description) return '';
+  const lines = schema.description.trim().split('\n');
+  return '/** ' + lines.join('\n * ') + ' */\n';
+}
+
+function resolveRef(schema) {
+  return schema.$ref ? doc.components.schemas[refName(schema.$ref)] : schema;
+}
+
+function typeOf(schema, inherited = {}) {
+  let type;
+  if (schema.$ref) {
+    type = refName(schema.$ref);
+  } else if (schema.allOf) {
+    // A member may mark a field from another member as required.
+    const properties = Object.assign({}, ...schema.allOf.map((member) => resolveRef(member).properties));
+    type = schema.allOf.map((member) => typeOf(member, properties)).join(' & ');
+  } else if (schema.enum) {
+    type = schema.enum.map((value) => JSON.stringify(value).replace(/"/g, "'")).join(' | ');
+  } else if (schema.type === 'array') {
+    const items = typeOf(schema.items);
+    type = /[ |&]/.test(items) ? `(${items})[]` : `${items}[]`;
+  } else if (schema.type === 'object' || schema.properties) {
+    type = objectType(schema, inherited);
+  } else if (schema.type === 'string') {
+    type = 'string';
+  } else if (schema.type === 'integer' || schema.type === 'number') {
+    type = 'number';
+  } else if (schema.type === 'boolean') {
+    type = 'boolean';
+  } else {
+    type = 'unknown';
+  }
+  return schema.nullable ? `${type} | null` : type;
+}
+
+function objectType(schema, inherited) {
+  const required = new Set(schema.required ?? []);
+  const fields = Object.entries(schema.properties ?? {}).map(([name, property]) => {
+    const optional = required.has(name) ? '' : '?';
+    return comment(property) + `${name}${optional}: ${typeOf(property)};`;
+  });
+  for (const name of required) {
+    if (!schema.properties?.[name]) {
+      fields.push(`${name}: ${inherited[name] ? typeOf(inherited[name]) : 'unknown'};`);
+    }
+  }
+  if (schema.additionalProperties && typeof schema.additionalProperties === 'object') {
+    fields.push(`[key: string]: ${typeOf(schema.additionalProperties)};`);
+  }
+  if (fields.length === 0) return 'Record<string, unknown>';
+  return '{\n  ' + fields.map((field) => indent(field, 1)).join('\n  ') + '\n}';
+}
+
+let out = `// Generated by scripts/generate-api-types.mjs from the report service's
+// OpenAPI document (${doc.info.title} ${doc.info.version}). Do not edit;
+// run \`npm run generate:api\` instead.
+`;
+for (const [name, schema] of Object.entries(doc.components.schemas).sort(([a], [b]) => a.localeCompare(b))) {
+  out += '\n' + comment(schema) + `export type ${name} = ${typeOf(schema)};\n`;
+}
+
+writeFileSync(outFile, out);
+console.log(`wrote ${outFile}`);
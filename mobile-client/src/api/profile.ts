@@ -1,19 +1,7 @@
 import { apiClient } from './client';
+import { Profile, ProfileResponse } from './schema';
 
-export type UserProfile = {
-  id: string;
-  email: string;
-  name: string;
-  role: string;
-  roles: string[];
-  open_reports: number;
-  resolved_reports: number;
-};
-
-type ProfileResponse = {
-  data: UserProfile;
-  success: boolean;
-};
+export type UserProfile = Profile;
 
 export async function fetchProfile(): Promise<UserProfile> {
   const response = await apiClient.request<ProfileResponse>('/api/profile');
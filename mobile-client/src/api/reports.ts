@@ -1,44 +1,15 @@
 import { apiClient } from './client';
 import { CreateReportInput, Report } from '../types/report';
 import { fetchProfile, UserProfile } from './profile';
-
-interface ApiReport {
-	id: string;
-	title: string;
-	description: string;
-	category: string;
-	status: string;
-	visibility: string;
-	image_url?: string;
-	created_at: string;
-	user_id: string;
-	updates: ApiReportUpdate[];
-}
-
-interface ApiReportUpdate {
-	id: string;
-	report_id: string;
-	title: string;
-	date: string;
-	is_active: boolean;
-	created_at: string;
-}
-
-interface ApiResponse<T> {
-	success: boolean;
-	message?: string;
-	data: T;
-	error?: {
-		code: string;
-		message: string;
-	};
-}
-
-interface UploadUrlResponse {
-	upload_url: string;
-	image_url: string;
-	object_key: string;
-}
+import {
+	CreateReportRequest,
+	Report as ApiReport,
+	ReportListResponse,
+	ReportResponse,
+	UploadURL,
+	UploadURLRequest,
+	UploadURLResponse,
+} from './schema';
 
 let cachedProfile: UserProfile | null = null;
 
@@ -53,13 +24,13 @@ function transformReport(apiReport: ApiReport, isMine: boolean): Report {
 		id: apiReport.id,
 		title: apiReport.title,
 		description: apiReport.description,
-		category: apiReport.category as Report['category'],
-		status: apiReport.status as Report['status'],
+		category: apiReport.category,
+		status: apiReport.status,
 		createdAt: apiReport.created_at,
-		visibility: apiReport.visibility as Report['visibility'],
+		visibility: apiReport.visibility,
 		imageUri: apiReport.image_url,
 		isMine,
-		updates: apiReport.updates.map((u) => ({
+		updates: (apiReport.updates ?? []).map((u) => ({
 			date: u.date,
 			title: u.title,
 			active: u.is_active,
@@ -70,27 +41,28 @@ function transformReport(apiReport: ApiReport, isMine: boolean): Report {
 export const reportsApi = {
 	async getAllReports(): Promise<Report[]> {
 		const profile = await getProfile();
-		const response = await apiClient.request<ApiResponse<ApiReport[]>>('/api/reports');
-		return response.data.map((r) => transformReport(r, r.user_id === profile.id));
+		const response = await apiClient.request<ReportListResponse>('/api/reports');
+		return (response.data ?? []).map((r) => transformReport(r, r.user_id === profile.id));
 	},
 
 	async getReportById(id: string): Promise<Report | null> {
 		const profile = await getProfile();
-		const response = await apiClient.request<ApiResponse<ApiReport>>(`/api/reports/${id}`);
+		const response = await apiClient.request<ReportResponse>(`/api/reports/${id}`);
 		return transformReport(response.data, response.data.user_id === profile.id);
 	},
 
 	async createReport(input: CreateReportInput): Promise<Report> {
 		try {
-			const response = await apiClient.request<ApiResponse<ApiReport>>('/api/reports', {
+			const body: CreateReportRequest = {
+				title: input.title,
+				description: input.description,
+				category: input.category,
+				visibility: input.visibility,
+				image_url: input.imageUrl,
+			};
+			const response = await apiClient.request<ReportResponse>('/api/reports', {
 				method: 'POST',
-				body: JSON.stringify({
-					title: input.title,
-					description: input.description,
-					category: input.category,
-					visibility: input.visibility,
-					image_url: input.imageUrl,
-				}),
+				body: JSON.stringify(body),
 			});
 			return transformReport(response.data, true);
 		} catch (error) {
@@ -99,14 +71,15 @@ export const reportsApi = {
 		}
 	},
 
-	async requestUploadUrl(fileName: string, contentType: string): Promise<UploadUrlResponse> {
+	async requestUploadUrl(fileName: string, contentType: string): Promise<UploadURL> {
 		try {
-			const response = await apiClient.request<ApiResponse<UploadUrlResponse>>('/api/reports/upload-url', {
+			const body: UploadURLRequest = {
+				file_name: fileName,
+				content_type: contentType,
+			};
+			const response = await apiClient.request<UploadURLResponse>('/api/reports/upload-url', {
 				method: 'POST',
-				body: JSON.stringify({
-					file_name: fileName,
-					content_type: contentType,
-				}),
+				body: JSON.stringify(body),
 			});
 			try {
 				const parsed = new URL(response.data.upload_url);
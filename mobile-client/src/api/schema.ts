@@ -0,0 +1,293 @@
+// Generated by scripts/generate-api-types.mjs from the report service's
+// OpenAPI document (Report Management API 1.0.0). Do not edit;
+// run `npm run generate:api` instead.
+
+export type AddAttachmentRequest = {
+  content_type?: string;
+  url: string;
+};
+
+export type AddCommentRequest = {
+  body: string;
+};
+
+export type AssignReportRequest = {
+  assignee_id?: string;
+};
+
+export type CreateReportOnBehalfRequest = CreateReportRequest & {
+  channel: 'PHONE' | 'EMAIL' | 'WALK_IN' | 'PARTNER';
+  reporter: ReporterRequest;
+};
+
+export type CreateReportRequest = {
+  category: ReportCategory;
+  description: string;
+  /** The `image_url` from POST /api/reports/upload-url. */
+  image_url?: string;
+  title: string;
+  visibility: ReportVisibility;
+};
+
+export type ErrorInfo = {
+  code: string;
+  message: string;
+};
+
+export type ErrorResponse = Response & {
+  success?: false;
+  error: ErrorInfo;
+};
+
+export type HealthReport = {
+  checked_at: string;
+  components: {
+    [key: string]: {
+      error?: string;
+      latency_ms: number;
+      status: 'up' | 'down';
+    };
+  };
+  status: 'up' | 'down';
+};
+
+export type IntakeChannel = 'APP' | 'PHONE' | 'EMAIL' | 'WALK_IN' | 'PARTNER';
+
+export type LinkWorkOrderRequest = {
+  work_order_id: string;
+};
+
+export type MergeReportRequest = {
+  target_report_id: string;
+};
+
+export type MessageResponse = Response & {
+  message: string;
+};
+
+export type ModerationHistory = {
+  history: UserModerationAction[] | null;
+  user: User;
+};
+
+export type ModerationHistoryResponse = Response & {
+  data: ModerationHistory;
+};
+
+export type ProbeStatus = {
+  status: 'up' | 'down';
+};
+
+export type Profile = {
+  email: string;
+  id: string;
+  name: string;
+  open_reports: number;
+  /** Granted actions with their scope, e.g. `report:read:own`. */
+  permissions: string[] | null;
+  resolved_reports: number;
+  /** The caller's most privileged role. */
+  role: string;
+  roles: string[];
+};
+
+export type ProfileResponse = Response & {
+  data: Profile;
+};
+
+export type Report = {
+  assignee_id?: string;
+  /** Only returned by GET /api/reports/{id}. */
+  attachments?: ReportAttachment[];
+  category: ReportCategory;
+  /** Only returned by GET /api/reports/{id}. */
+  comments?: ReportComment[];
+  created_at: string;
+  /** Whoever filed it, when that differs from the resident. */
+  created_by_id?: string;
+  description: string;
+  id: string;
+  image_url?: string;
+  intake_channel: IntakeChannel;
+  /** Set when the report was resolved as a duplicate. */
+  merged_into_id?: string;
+  priority: ReportPriority;
+  status: ReportStatus;
+  title: string;
+  updated_at: string;
+  updates?: ReportUpdate[];
+  /** The resident the report belongs to. */
+  user_id: string;
+  visibility: ReportVisibility;
+};
+
+export type ReportAttachment = {
+  content_type?: string;
+  created_at: string;
+  id: string;
+  report_id: string;
+  uploader_id: string;
+  url: string;
+};
+
+export type ReportCategory = 'CRIME' | 'SANITATION' | 'HEALTH';
+
+export type ReportComment = {
+  author_id: string;
+  body: string;
+  created_at: string;
+  id: string;
+  report_id: string;
+};
+
+/** At least one of email or phone is required. */
+export type ReporterRequest = {
+  email?: string;
+  name?: string;
+  phone?: string;
+};
+
+export type ReportListResponse = Response & {
+  data: Report[] | null;
+};
+
+export type ReportPriority = 'LOW' | 'NORMAL' | 'HIGH' | 'URGENT';
+
+export type ReportResponse = Response & {
+  data: Report;
+};
+
+export type ReportStatus = 'OPEN' | 'IN_PROGRESS' | 'RESOLVED' | 'WITHDRAWN';
+
+export type ReportUpdate = {
+  created_at: string;
+  /** A display label, e.g. "Mar 01, 2025" or "Pending". */
+  date: string;
+  id: string;
+  is_active: boolean;
+  report_id: string;
+  title: string;
+};
+
+export type ReportVisibility = 'PUBLIC' | 'PRIVATE' | 'ANONYMOUS';
+
+/** The envelope every /api response is wrapped in. */
+export type Response = {
+  data?: unknown;
+  error?: ErrorInfo;
+  message?: string;
+  success: boolean;
+};
+
+export type RevocationListResponse = Response & {
+  data: TokenRevocation[] | null;
+};
+
+export type RevocationResponse = Response & {
+  data: TokenRevocation;
+};
+
+/** Exactly one of subject or session_id. */
+export type RevokeTokensRequest = {
+  reason?: string;
+  session_id?: string;
+  subject?: string;
+};
+
+export type SuspendUserRequest = {
+  reason: string;
+  /** Omit to suspend indefinitely. */
+  until?: string;
+};
+
+export type TokenRevocation = {
+  created_at: string;
+  id: string;
+  reason?: string;
+  revoked_by?: string;
+  session_id?: string;
+  source: string;
+  subject?: string;
+};
+
+export type UnsuspendUserRequest = {
+  reason: string;
+};
+
+export type UpdatePriorityRequest = {
+  priority: ReportPriority;
+};
+
+export type UpdateReportRequest = {
+  category?: ReportCategory;
+  description?: string;
+  title?: string;
+  visibility?: ReportVisibility;
+};
+
+export type UpdateStatusRequest = {
+  status: ReportStatus;
+};
+
+export type UploadURL = {
+  /** Where the image is served from once uploaded. */
+  image_url: string;
+  object_key: string;
+  /** Presigned PUT URL. */
+  upload_url: string;
+};
+
+export type UploadURLRequest = {
+  content_type: string;
+  file_name: string;
+};
+
+export type UploadURLResponse = Response & {
+  data: UploadURL;
+};
+
+export type User = {
+  created_at: string;
+  email: string;
+  id: string;
+  locale?: string;
+  name: string;
+  phone?: string;
+  preferred_username?: string;
+  status: UserStatus;
+  /** Omitted for an indefinite suspension. */
+  suspended_until?: string;
+  suspension_reason?: string;
+  updated_at: string;
+};
+
+export type UserModerationAction = {
+  action: 'SUSPEND' | 'UNSUSPEND';
+  actor_id: string;
+  correlation_id?: string;
+  created_at: string;
+  id: string;
+  reason: string;
+  suspended_until?: string;
+  user_id: string;
+};
+
+export type UserResponse = Response & {
+  data: User;
+};
+
+export type UserStatus = 'ACTIVE' | 'SUSPENDED';
+
+export type WorkOrderLink = {
+  created_at: string;
+  /** updated_at of the newest work-order event applied through the link. */
+  last_event_at?: string;
+  last_state?: string;
+  report_id: string;
+  updated_at: string;
+  work_order_id: string;
+};
+
+export type WorkOrderLinkResponse = Response & {
+  data: WorkOrderLink;
+};
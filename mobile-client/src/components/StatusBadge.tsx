@@ -5,6 +5,7 @@ const STATUS_STYLES: Record<ReportStatus, { label: string; color: string; backgr
   OPEN: { label: 'Open', color: '#1d4ed8', background: '#dbeafe' },
   IN_PROGRESS: { label: 'In Progress', color: '#b45309', background: '#fef3c7' },
   RESOLVED: { label: 'Resolved', color: '#047857', background: '#d1fae5' },
+  WITHDRAWN: { label: 'Withdrawn', color: '#4b5563', background: '#e5e7eb' },
 };
 
 export function StatusBadge({ status }: { status: ReportStatus }) {
@@ -8,6 +8,9 @@ import { fetchProfile, UserProfile } from '../api/profile';
 const ROLE_DISPLAY_NAMES: Record<string, string> = {
   CITIZEN: 'Citizen',
   DEPARTMENT_STAFF: 'Department Staff',
+  SUPERVISOR: 'Supervisor',
+  AUDITOR: 'Auditor',
+  ADMIN: 'Administrator',
 };
 
 export function ProfileScreen() {
@@ -1,6 +1,8 @@
-export type ReportStatus = 'OPEN' | 'IN_PROGRESS' | 'RESOLVED';
-export type ReportVisibility = 'PUBLIC' | 'PRIVATE' | 'ANONYMOUS';
-export type ReportCategory = 'CRIME' | 'SANITATION' | 'HEALTH';
+// The app's own view of a report. The API's shapes are generated into
+// src/api/schema.ts; src/api/reports.ts maps between the two.
+import { ReportCategory, ReportStatus, ReportVisibility } from '../api/schema';
+
+export type { ReportCategory, ReportStatus, ReportVisibility };
 
 export interface ReportUpdate {
   date: string;
@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+
+	"reportmaxxing/services/report-management-service/config"
+	"reportmaxxing/services/report-management-service/kafka"
+	"reportmaxxing/services/report-management-service/middleware"
+	"reportmaxxing/services/report-management-service/migrations"
+	"reportmaxxing/services/report-management-service/openapi"
+	"reportmaxxing/services/report-management-service/repository"
+	"reportmaxxing/services/report-management-service/services"
+)
+
+// runCommand executes a one-off maintenance subcommand instead of starting
+// the HTTP server, e.g. `go run . snapshot`.
+func runCommand(args []string, store repository.Store, producer *kafka.Producer) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	replayService := services.NewEventReplayService(store, producer)
+
+	switch args[0] {
+	case "snapshot":
+		fs := flag.NewFlagSet("snapshot", flag.ExitOnError)
+		topic := fs.String("topic", kafka.ReportsSnapshotTopic, "compacted topic to publish report state to")
+		fs.Parse(args[1:])
+
+		count, tombstones, err := replayService.PublishSnapshot(ctx, *topic)
+		if err != nil {
+			return err
+		}
+		slog.Info("snapshot: published", "reports", count, "tombstones", tombstones, "topic", *topic)
+		return nil
+
+	case "replay":
+		fs := flag.NewFlagSet("replay", flag.ExitOnError)
+		topic := fs.String("topic", "", "topic to republish events to (required)")
+		from := fs.String("from", "", "replay events created at or after this RFC3339 time (required)")
+		to := fs.String("to", "", "replay events created before this RFC3339 time (default: now)")
+		eventTypes := fs.String("event-types", "", "comma-separated event types to include (default: all)")
+		batchSize := fs.Int("batch-size", 200, "number of events per Kafka write")
+		fs.Parse(args[1:])
+
+		if *from == "" {
+			return fmt.Errorf("-from is required")
+		}
+		opts := services.ReplayOptions{
+			Topic:     *topic,
+			BatchSize: *batchSize,
+		}
+		var err error
+		if opts.From, err = time.Parse(time.RFC3339, *from); err != nil {
+			return fmt.Errorf("invalid -from: %w", err)
+		}
+		if *to != "" {
+			if opts.To, err = time.Parse(time.RFC3339, *to); err != nil {
+				return fmt.Errorf("invalid -to: %w", err)
+			}
+		}
+		if *eventTypes != "" {
+			opts.EventTypes = strings.Split(*eventTypes, ",")
+		}
+
+		count, err := replayService.Replay(ctx, opts)
+		if err != nil {
+			return err
+		}
+		slog.Info("replay: published", "events", count, "topic", opts.Topic)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown command %q (available: snapshot, replay, migrate, dev-token, config, openapi)", args[0])
+	}
+}
+
+// runMigrate manages the schema: `migrate up`, `migrate down [-steps n]`,
+// `migrate status` and `migrate create <name>`.
+func runMigrate(args []string, dbConfig config.DatabaseConfig) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate up|down|status|create")
+	}
+
+	switch args[0] {
+	case "up", "down", "status":
+	case "create":
+		fs := flag.NewFlagSet("migrate create", flag.ExitOnError)
+		dir := fs.String("dir", migrations.Dir, "directory to write the migration files to")
+		fs.Parse(args[1:])
+		if fs.NArg() != 1 {
+			return fmt.Errorf("usage: migrate create [-dir dir] <name>")
+		}
+
+		up, down, err := migrations.Create(*dir, fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		slog.Info("migrate: created", "up", up, "down", down)
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate command %q (available: up, down, status, create)", args[0])
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	db, err := openDatabase(dbConfig)
+	if err != nil {
+		return err
+	}
+	migrator, err := migrations.New(db)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		for _, migration := range applied {
+			slog.Info("migrate: applied", "version", migration.Version, "name", migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		slog.Info("migrate: schema up to date", "version", migrator.Latest())
+		return nil
+
+	case "down":
+		fs := flag.NewFlagSet("migrate down", flag.ExitOnError)
+		steps := fs.Int("steps", 1, "number of migrations to roll back")
+		fs.Parse(args[1:])
+
+		reverted, err := migrator.Down(ctx, *steps)
+		for _, migration := range reverted {
+			slog.Info("migrate: reverted", "version", migration.Version, "name", migration.Name)
+		}
+		return err
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			applied := "pending"
+			if status.AppliedAt != nil {
+				applied = "applied " + status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%04d_%-40s %s\n", status.Version, status.Name, applied)
+		}
+	}
+	return nil
+}
+
+// runOpenAPI prints the OpenAPI document as JSON, for client generators
+// that can't read the YAML or reach a running server.
+func runOpenAPI() error {
+	doc, err := openapi.Load()
+	if err != nil {
+		return err
+	}
+	out, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+// runDevToken mints a signed access token for local development. The signing
+// key is created on first use, together with the JWKS file the API should be
+// started with (KEYCLOAK_JWKS_FILE).
+func runDevToken(args []string, authConfig middleware.AuthConfig) error {
+	fs := flag.NewFlagSet("dev-token", flag.ExitOnError)
+	keyPath := fs.String("key", ".dev/jwt-key.pem", "RSA private key used to sign tokens (created if missing)")
+	jwksPath := fs.String("jwks", ".dev/jwks.json", "JWKS file written alongside a newly created key")
+	subject := fs.String("sub", "", "token subject, i.e. the user ID (default: a new UUID)")
+	email := fs.String("email", "dev@example.com", "email claim")
+	name := fs.String("name", "Dev User", "name claim")
+	roles := fs.String("roles", "CITIZEN", "comma-separated realm roles")
+	clientRoles := fs.String("client-roles", "", "comma-separated client roles for the azp client")
+	groups := fs.String("groups", "", "comma-separated group paths, e.g. /departments/sanitation")
+	client := fs.String("client", "", "azp claim (default: first of KEYCLOAK_ALLOWED_CLIENTS, or KEYCLOAK_SERVICE_CLIENTS with -service-account)")
+	serviceAccount := fs.Bool("service-account", false, "mint a client-credentials token for a service client")
+	ttl := fs.Duration("ttl", time.Hour, "token lifetime")
+	fs.Parse(args)
+
+	key, err := middleware.LoadOrCreateDevKey(*keyPath, *jwksPath)
+	if err != nil {
+		return err
+	}
+
+	opts := middleware.DevTokenOptions{
+		Issuer:      authConfig.ExpectedIssuer(),
+		Client:      *client,
+		Audience:    authConfig.Audiences,
+		Subject:     *subject,
+		Email:       *email,
+		Name:        *name,
+		Roles:       config.SplitList(*roles),
+		ClientRoles: config.SplitList(*clientRoles),
+		Groups:      config.SplitList(*groups),
+		TTL:         *ttl,
+
+		ServiceAccount: *serviceAccount,
+	}
+	if opts.Subject == "" {
+		opts.Subject = uuid.New().String()
+	}
+	clients := authConfig.AllowedClients
+	if opts.ServiceAccount {
+		clients = authConfig.ServiceClients
+	}
+	if opts.Client == "" && len(clients) > 0 {
+		opts.Client = clients[0]
+	}
+	if opts.ServiceAccount && !flagSet(fs, "roles") {
+		// Service accounts are authorised by their client's policy entry;
+		// don't hand them the CITIZEN default.
+		opts.Roles = nil
+	}
+	if opts.ServiceAccount && opts.Client == "" {
+		return fmt.Errorf("-service-account needs -client or KEYCLOAK_SERVICE_CLIENTS")
+	}
+
+	token, err := middleware.MintDevToken(key, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Println(token)
+	return nil
+}
+
+func flagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
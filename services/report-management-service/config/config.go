@@ -0,0 +1,296 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"reportmaxxing/services/report-management-service/kafka"
+	"reportmaxxing/services/report-management-service/logging"
+	"reportmaxxing/services/report-management-service/middleware"
+	"reportmaxxing/services/report-management-service/openapi"
+	"reportmaxxing/services/report-management-service/ratelimit"
+	"reportmaxxing/services/report-management-service/services"
+	"reportmaxxing/services/report-management-service/tracing"
+)
+
+// Config is every setting the service reads at startup. Sections that
+// belong to a package use that package's own config type so they can be
+// handed straight to its constructor.
+type Config struct {
+	Server     ServerConfig
+	Database   DatabaseConfig
+	Auth       middleware.AuthConfig
+	Kafka      kafka.Config
+	S3         services.S3Config
+	RateLimit  RateLimitConfig
+	Consumers  ConsumersConfig
+	Health     HealthConfig
+	Metrics    MetricsConfig
+	Tracing    tracing.Config
+	Logging    logging.Config
+	OpenAPI    openapi.Config
+	PolicyFile string
+}
+
+// ServerConfig holds the HTTP server settings. ShutdownTimeout bounds the
+// whole drain on SIGTERM, so keep it under the orchestrator's grace period.
+type ServerConfig struct {
+	Port              int
+	TrustedProxies    []string
+	ReadTimeout       time.Duration
+	ReadHeaderTimeout time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	MaxHeaderBytes    int
+	ShutdownTimeout   time.Duration
+}
+
+// DatabaseConfig describes the Postgres connection. URL, when set, is used
+// as-is and the individual fields are ignored. MigrateOnStart applies
+// pending migrations before serving instead of refusing to start.
+type DatabaseConfig struct {
+	URL      string
+	Host     string
+	Port     int
+	User     string
+	Password string
+	Name     string
+	SSLMode  string
+	TimeZone string
+
+	MigrateOnStart bool
+}
+
+type RateLimitConfig struct {
+	Enabled     bool
+	Store       string
+	PerIP       ratelimit.Limit
+	Read        ratelimit.Limit
+	Write       ratelimit.Limit
+	Reports     ratelimit.Limit
+	Uploads     ratelimit.Limit
+	ExemptRoles []string
+}
+
+type ConsumersConfig struct {
+	WorkOrdersEnabled     bool
+	WorkOrdersGroup       string
+	KeycloakEventsEnabled bool
+	KeycloakEventsTopic   string
+	KeycloakEventsGroup   string
+}
+
+// HealthConfig bounds each readiness check and how long a result is reused.
+type HealthConfig struct {
+	Timeout  time.Duration
+	CacheTTL time.Duration
+}
+
+// MetricsConfig controls /metrics. The SLA targets are how long a report of
+// each priority may stay open before it counts as breached; 0 turns the
+// check off for that priority.
+type MetricsConfig struct {
+	Enabled   bool
+	SLAUrgent time.Duration
+	SLAHigh   time.Duration
+	SLANormal time.Duration
+	SLALow    time.Duration
+}
+
+const (
+	RateLimitStoreMemory   = "memory"
+	RateLimitStorePostgres = "postgres"
+)
+
+// Default returns the settings used for local development.
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Port:              8081,
+			ReadTimeout:       15 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			MaxHeaderBytes:    1 << 20,
+			ShutdownTimeout:   25 * time.Second,
+		},
+		Database: DatabaseConfig{
+			Host:     "localhost",
+			Port:     9920,
+			User:     "gorm",
+			Password: "gorm",
+			Name:     "gorm",
+			SSLMode:  "disable",
+			TimeZone: "UTC",
+		},
+		Auth: middleware.AuthConfig{
+			KeycloakURL:           "http://localhost:8080",
+			Realm:                 "reportmaxxing",
+			AllowedClients:        []string{"mobile-app"},
+			Leeway:                30 * time.Second,
+			RoleSources:           []string{middleware.RoleSourceRealm},
+			RoleGroupPrefix:       middleware.DefaultRoleGroupPrefix,
+			DepartmentGroupPrefix: middleware.DefaultDepartmentGroupPrefix,
+			MaxTokenLifetime:      middleware.DefaultMaxTokenLifetime,
+			UserCacheTTL:          middleware.DefaultUserCacheTTL,
+			IntrospectionCacheTTL: middleware.DefaultIntrospectionCacheTTL,
+		},
+		Kafka: kafka.Config{Brokers: []string{"localhost:9092"}},
+		S3: services.S3Config{
+			Endpoint:  "http://localhost:9001",
+			Region:    "us-east-1",
+			AccessKey: "minioadmin",
+			SecretKey: "minioadmin",
+			Bucket:    "report-images",
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:     true,
+			Store:       RateLimitStoreMemory,
+			PerIP:       ratelimit.Limit{Requests: 300, Period: time.Minute},
+			Read:        ratelimit.Limit{Requests: 120, Period: time.Minute},
+			Write:       ratelimit.Limit{Requests: 30, Period: time.Minute},
+			Reports:     ratelimit.Limit{Requests: 20, Period: time.Hour},
+			Uploads:     ratelimit.Limit{Requests: 40, Period: time.Hour},
+			ExemptRoles: []string{"DEPARTMENT_STAFF", "SUPERVISOR", "ADMIN"},
+		},
+		Consumers: ConsumersConfig{
+			WorkOrdersEnabled:   true,
+			WorkOrdersGroup:     "report-management-service",
+			KeycloakEventsTopic: kafka.KeycloakEventsTopic,
+			KeycloakEventsGroup: "report-management-service",
+		},
+		Health: HealthConfig{
+			Timeout:  2 * time.Second,
+			CacheTTL: 5 * time.Second,
+		},
+		Tracing: tracing.Config{
+			ServiceName: "report-management-service",
+			Exporter:    tracing.ExporterNone,
+			SampleRatio: 1,
+		},
+		Logging: logging.Config{
+			Level:        "info",
+			Format:       logging.FormatJSON,
+			RedactFields: logging.DefaultRedactFields,
+		},
+		OpenAPI: openapi.Config{
+			DocsEnabled: true,
+		},
+		Metrics: MetricsConfig{
+			Enabled:   true,
+			SLAUrgent: 24 * time.Hour,
+			SLAHigh:   72 * time.Hour,
+			SLANormal: 7 * 24 * time.Hour,
+			SLALow:    14 * 24 * time.Hour,
+		},
+	}
+}
+
+// Validate reports every invalid setting at once.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(c.Server.Port > 0 && c.Server.Port < 65536, "server.port must be between 1 and 65535, got %d", c.Server.Port)
+	check(c.Server.ReadTimeout > 0, "server.read_timeout must be positive")
+	check(c.Server.ReadHeaderTimeout > 0, "server.read_header_timeout must be positive")
+	check(c.Server.WriteTimeout > 0, "server.write_timeout must be positive")
+	check(c.Server.IdleTimeout > 0, "server.idle_timeout must be positive")
+	check(c.Server.MaxHeaderBytes >= 4096, "server.max_header_bytes must be at least 4096, got %d", c.Server.MaxHeaderBytes)
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout must be positive")
+
+	if c.Database.URL == "" {
+		check(c.Database.Host != "", "database.host is required when database.url is not set")
+		check(c.Database.Name != "", "database.name is required when database.url is not set")
+		check(c.Database.User != "", "database.user is required when database.url is not set")
+		check(c.Database.Port > 0 && c.Database.Port < 65536, "database.port must be between 1 and 65535, got %d", c.Database.Port)
+	}
+
+	check(c.Auth.JWKSFile != "" || c.Auth.KeycloakURL != "", "auth.keycloak_url is required unless auth.jwks_file is set")
+	check(c.Auth.Realm != "" || c.Auth.Issuer != "", "auth.realm or auth.issuer is required")
+	check(c.Auth.Leeway >= 0, "auth.leeway must not be negative")
+	check(c.Auth.MaxTokenLifetime > 0, "auth.max_token_lifetime must be positive")
+	for _, source := range c.Auth.RoleSources {
+		check(source == middleware.RoleSourceRealm || source == middleware.RoleSourceClient || source == middleware.RoleSourceGroups,
+			"auth.role_sources: unknown source %q", source)
+	}
+	if slices.Contains(c.Auth.RoleSources, middleware.RoleSourceGroups) {
+		check(strings.HasPrefix(c.Auth.RoleGroupPrefix, "/") && strings.HasSuffix(c.Auth.RoleGroupPrefix, "/"),
+			"auth.role_group_prefix must start and end with \"/\", got %q", c.Auth.RoleGroupPrefix)
+	}
+	check(c.Auth.IntrospectionClientID == "" || c.Auth.IntrospectionClientSecret != "",
+		"auth.introspection_client_secret is required when auth.introspection_client_id is set")
+
+	if err := c.Kafka.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("kafka: %w", err))
+	}
+
+	check(c.S3.Endpoint != "", "s3.endpoint is required")
+	check(c.S3.Bucket != "", "s3.bucket is required")
+	check(c.S3.Region != "", "s3.region is required")
+
+	check(c.RateLimit.Store == RateLimitStoreMemory || c.RateLimit.Store == RateLimitStorePostgres,
+		"rate_limit.store must be %q or %q, got %q", RateLimitStoreMemory, RateLimitStorePostgres, c.RateLimit.Store)
+
+	check(!c.Consumers.WorkOrdersEnabled || c.Consumers.WorkOrdersGroup != "", "consumers.workorders_group is required when the work-order consumer is enabled")
+	check(!c.Consumers.KeycloakEventsEnabled || (c.Consumers.KeycloakEventsGroup != "" && c.Consumers.KeycloakEventsTopic != ""),
+		"consumers.keycloak_events_group and _topic are required when the Keycloak event consumer is enabled")
+
+	check(c.Health.Timeout > 0, "health.timeout must be positive")
+	check(c.Health.CacheTTL >= 0, "health.cache_ttl must not be negative")
+	check(c.Tracing.Exporter == tracing.ExporterNone || c.Tracing.Exporter == tracing.ExporterStdout || c.Tracing.Exporter == tracing.ExporterOTLP,
+		"tracing.exporter must be %q, %q or %q, got %q", tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP, c.Tracing.Exporter)
+	check(c.Tracing.ServiceName != "", "tracing.service_name is required")
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio must be between 0 and 1, got %g", c.Tracing.SampleRatio)
+	var level slog.Level
+	check(level.UnmarshalText([]byte(c.Logging.Level)) == nil, "logging.level must be debug, info, warn or error, got %q", c.Logging.Level)
+	check(c.Logging.Format == logging.FormatJSON || c.Logging.Format == logging.FormatText,
+		"logging.format must be %q or %q, got %q", logging.FormatJSON, logging.FormatText, c.Logging.Format)
+	check(c.Metrics.SLAUrgent >= 0 && c.Metrics.SLAHigh >= 0 && c.Metrics.SLANormal >= 0 && c.Metrics.SLALow >= 0,
+		"metrics.sla_* targets must not be negative")
+
+	return errors.Join(errs...)
+}
+
+// DSN returns the connection string for gorm's postgres driver.
+func (d DatabaseConfig) DSN() string {
+	if d.URL != "" {
+		return d.URL
+	}
+
+	params := []struct{ key, value string }{
+		{"host", d.Host},
+		{"port", fmt.Sprint(d.Port)},
+		{"user", d.User},
+		{"password", d.Password},
+		{"dbname", d.Name},
+		{"sslmode", d.SSLMode},
+		{"TimeZone", d.TimeZone},
+	}
+	parts := make([]string, 0, len(params))
+	for _, p := range params {
+		if p.value != "" {
+			parts = append(parts, p.key+"="+quoteDSNValue(p.value))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// quoteDSNValue quotes values containing spaces or quotes, as libpq's
+// key=value syntax requires.
+func quoteDSNValue(value string) string {
+	if !strings.ContainsAny(value, ` '\`) {
+		return value
+	}
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
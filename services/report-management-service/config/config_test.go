@@ -0,0 +1,356 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"reportmaxxing/services/report-management-service/middleware"
+	"reportmaxxing/services/report-management-service/ratelimit"
+)
+
+// clearEnv blanks every variable Load reads, so settings in the developer's
+// environment don't leak into the tests. Load ignores empty values.
+func clearEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("CONFIG_FILE", "")
+	cfg := Default()
+	for _, b := range cfg.bindings() {
+		for _, name := range b.env {
+			t.Setenv(name, "")
+		}
+	}
+}
+
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	file := writeConfigFile(t, `
+server:
+  port: 9000
+database:
+  host: db.internal
+  name: reports
+kafka:
+  brokers: [file-1:9092, file-2:9092]
+rate_limit:
+  read: 10/s
+`)
+
+	tests := []struct {
+		name  string
+		env   map[string]string
+		args  []string
+		check func(t *testing.T, cfg *Config)
+	}{
+		{
+			name: "defaults",
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Server.Port != 8081 {
+					t.Errorf("port = %d, want the default 8081", cfg.Server.Port)
+				}
+				if cfg.Auth.MaxTokenLifetime != middleware.DefaultMaxTokenLifetime {
+					t.Errorf("max token lifetime = %v, want the default", cfg.Auth.MaxTokenLifetime)
+				}
+			},
+		},
+		{
+			name: "file over defaults",
+			args: []string{"-config", file},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Server.Port != 9000 {
+					t.Errorf("port = %d, want 9000 from the file", cfg.Server.Port)
+				}
+				if cfg.Database.Host != "db.internal" || cfg.Database.Name != "reports" {
+					t.Errorf("database = %s/%s, want db.internal/reports from the file", cfg.Database.Host, cfg.Database.Name)
+				}
+				if want := []string{"file-1:9092", "file-2:9092"}; !slices.Equal(cfg.Kafka.Brokers, want) {
+					t.Errorf("brokers = %v, want %v", cfg.Kafka.Brokers, want)
+				}
+				if want := (ratelimit.Limit{Requests: 10, Period: time.Second}); cfg.RateLimit.Read != want {
+					t.Errorf("read limit = %v, want %v", cfg.RateLimit.Read, want)
+				}
+				if cfg.Database.User != "gorm" {
+					t.Errorf("database user = %q, want the default kept", cfg.Database.User)
+				}
+			},
+		},
+		{
+			name: "CONFIG_FILE names the file",
+			env:  map[string]string{"CONFIG_FILE": file},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Server.Port != 9000 {
+					t.Errorf("port = %d, want 9000 from the file", cfg.Server.Port)
+				}
+			},
+		},
+		{
+			name: "env over file",
+			env:  map[string]string{"PORT": "9100", "KAFKA_BROKERS": "env-1:9092, env-2:9092"},
+			args: []string{"-config", file},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Server.Port != 9100 {
+					t.Errorf("port = %d, want 9100 from the environment", cfg.Server.Port)
+				}
+				if want := []string{"env-1:9092", "env-2:9092"}; !slices.Equal(cfg.Kafka.Brokers, want) {
+					t.Errorf("brokers = %v, want %v", cfg.Kafka.Brokers, want)
+				}
+				if cfg.Database.Host != "db.internal" {
+					t.Errorf("database host = %q, want the file value kept", cfg.Database.Host)
+				}
+			},
+		},
+		{
+			name: "flag over env",
+			env:  map[string]string{"PORT": "9100"},
+			args: []string{"-config", file, "-server.port", "9200"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.Server.Port != 9200 {
+					t.Errorf("port = %d, want 9200 from the flag", cfg.Server.Port)
+				}
+			},
+		},
+		{
+			name: "disabled rate limits are cleared",
+			env:  map[string]string{"RATE_LIMIT_ENABLED": "false"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.RateLimit.Read != (ratelimit.Limit{}) || cfg.RateLimit.PerIP != (ratelimit.Limit{}) {
+					t.Errorf("rate limits = %v/%v, want both cleared", cfg.RateLimit.Read, cfg.RateLimit.PerIP)
+				}
+			},
+		},
+		{
+			name: "S3 public URL defaults to the endpoint",
+			env:  map[string]string{"S3_ENDPOINT": "http://minio:9000"},
+			check: func(t *testing.T, cfg *Config) {
+				if cfg.S3.PublicBaseURL != "http://minio:9000" {
+					t.Errorf("public base URL = %q, want the endpoint", cfg.S3.PublicBaseURL)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			cfg, _, err := Load(tt.args)
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestLoadReturnsRemainingArgs(t *testing.T) {
+	clearEnv(t)
+	_, args, err := Load([]string{"-server.port", "9000", "migrate", "up"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if want := []string{"migrate", "up"}; !slices.Equal(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		args    []string
+		wantErr string
+	}{
+		{name: "bad env value", env: map[string]string{"PORT": "eighty"}, wantErr: "PORT"},
+		{name: "bad flag value", args: []string{"-auth.leeway", "soon"}, wantErr: "-auth.leeway"},
+		{name: "bad file value", args: []string{"-config", writeConfigFile(t, "server:\n  port: eighty\n")}, wantErr: "server.port"},
+		{name: "invalid YAML", args: []string{"-config", writeConfigFile(t, "server: [")}, wantErr: "invalid config file"},
+		{name: "missing file", args: []string{"-config", filepath.Join(t.TempDir(), "missing.yaml")}, wantErr: "failed to read config file"},
+		{name: "unknown flag", args: []string{"-no.such.setting", "1"}, wantErr: "no.such.setting"},
+		{name: "fails validation", env: map[string]string{"PORT": "70000"}, wantErr: "server.port must be between 1 and 65535"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			_, _, err := Load(tt.args)
+			if err == nil {
+				t.Fatal("Load succeeded, want an error")
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %q, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSecretsAreRedacted(t *testing.T) {
+	clearEnv(t)
+	t.Setenv("DB_PASSWORD", "db-secret")
+	t.Setenv("S3_SECRET_KEY", "s3-secret")
+	t.Setenv("KAFKA_SASL_PASSWORD", "kafka-secret")
+	t.Setenv("KEYCLOAK_INTROSPECTION_CLIENT_ID", "report-api")
+	t.Setenv("KEYCLOAK_INTROSPECTION_CLIENT_SECRET", "introspection-secret")
+
+	cfg, _, err := Load(nil)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Database.Password != "db-secret" {
+		t.Fatalf("password = %q, want the loaded value unchanged", cfg.Database.Password)
+	}
+
+	out := cfg.String()
+	for _, secret := range []string{"db-secret", "s3-secret", "kafka-secret", "introspection-secret"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("String() contains secret %q", secret)
+		}
+	}
+	for _, line := range []string{"database.password=[REDACTED]", "auth.introspection_client_id=report-api", "database.url=\n"} {
+		if !strings.Contains(out, line) {
+			t.Errorf("String() is missing %q", line)
+		}
+	}
+	if strings.Contains(cfg.LogValue().String(), "db-secret") {
+		t.Error("LogValue() contains the database password")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr []string
+	}{
+		{name: "defaults", modify: func(c *Config) {}},
+		{
+			name:    "server port",
+			modify:  func(c *Config) { c.Server.Port = 0 },
+			wantErr: []string{"server.port must be between 1 and 65535, got 0"},
+		},
+		{
+			name: "database fields not needed with a URL",
+			modify: func(c *Config) {
+				c.Database = DatabaseConfig{URL: "postgres://localhost/reports"}
+			},
+		},
+		{
+			name:    "database fields needed without a URL",
+			modify:  func(c *Config) { c.Database.Host = ""; c.Database.Name = "" },
+			wantErr: []string{"database.host is required", "database.name is required"},
+		},
+		{
+			name: "JWKS file replaces the Keycloak URL",
+			modify: func(c *Config) {
+				c.Auth.KeycloakURL = ""
+				c.Auth.JWKSFile = "dev/jwks.json"
+			},
+		},
+		{
+			name:    "Keycloak URL needed without a JWKS file",
+			modify:  func(c *Config) { c.Auth.KeycloakURL = "" },
+			wantErr: []string{"auth.keycloak_url is required"},
+		},
+		{
+			name:    "unknown role source",
+			modify:  func(c *Config) { c.Auth.RoleSources = []string{"realm", "ldap"} },
+			wantErr: []string{`unknown source "ldap"`},
+		},
+		{
+			name: "role group prefix checked with the groups source",
+			modify: func(c *Config) {
+				c.Auth.RoleSources = []string{middleware.RoleSourceGroups}
+				c.Auth.RoleGroupPrefix = "roles"
+			},
+			wantErr: []string{"auth.role_group_prefix"},
+		},
+		{
+			name:    "role group prefix ignored without the groups source",
+			modify:  func(c *Config) { c.Auth.RoleGroupPrefix = "roles" },
+			wantErr: nil,
+		},
+		{
+			name:    "introspection secret",
+			modify:  func(c *Config) { c.Auth.IntrospectionClientID = "report-api" },
+			wantErr: []string{"auth.introspection_client_secret is required"},
+		},
+		{
+			name:    "kafka",
+			modify:  func(c *Config) { c.Kafka.Brokers = nil },
+			wantErr: []string{"kafka: at least one kafka broker is required"},
+		},
+		{
+			name:    "rate limit store",
+			modify:  func(c *Config) { c.RateLimit.Store = "redis" },
+			wantErr: []string{`rate_limit.store must be "memory" or "postgres", got "redis"`},
+		},
+		{
+			name:    "consumer group",
+			modify:  func(c *Config) { c.Consumers.WorkOrdersGroup = "" },
+			wantErr: []string{"consumers.workorders_group is required"},
+		},
+		{
+			name:    "logging",
+			modify:  func(c *Config) { c.Logging.Level = "loud"; c.Logging.Format = "xml" },
+			wantErr: []string{"logging.level", "logging.format"},
+		},
+		{
+			name: "every error is reported",
+			modify: func(c *Config) {
+				c.Server.Port = -1
+				c.S3.Bucket = ""
+				c.Tracing.SampleRatio = 2
+			},
+			wantErr: []string{"server.port", "s3.bucket is required", "tracing.sample_ratio"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			tt.modify(&cfg)
+			err := cfg.Validate()
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("Validate: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate succeeded, want errors mentioning %q", tt.wantErr)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error = %q, want it to mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	tests := map[string][]string{
+		"":              nil,
+		" , ,":          nil,
+		"a":             {"a"},
+		"a,b":           {"a", "b"},
+		" a , b ,, c ,": {"a", "b", "c"},
+	}
+	for input, want := range tests {
+		if got := SplitList(input); !slices.Equal(got, want) {
+			t.Errorf("SplitList(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,326 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"reportmaxxing/services/report-management-service/ratelimit"
+)
+
+// binding ties one setting to its YAML path (also used as its flag name),
+// the environment variables it is read from, and the field it fills.
+type binding struct {
+	key    string
+	env    []string
+	target interface{}
+	secret bool
+	usage  string
+}
+
+func (c *Config) bindings() []binding {
+	return []binding{
+		{key: "server.port", env: []string{"PORT"}, target: &c.Server.Port, usage: "HTTP listen port"},
+		{key: "server.trusted_proxies", env: []string{"TRUSTED_PROXIES"}, target: &c.Server.TrustedProxies, usage: "proxy IPs/CIDRs allowed to set X-Forwarded-For"},
+		{key: "server.read_timeout", env: []string{"SERVER_READ_TIMEOUT"}, target: &c.Server.ReadTimeout, usage: "max time to read a request, body included"},
+		{key: "server.read_header_timeout", env: []string{"SERVER_READ_HEADER_TIMEOUT"}, target: &c.Server.ReadHeaderTimeout},
+		{key: "server.write_timeout", env: []string{"SERVER_WRITE_TIMEOUT"}, target: &c.Server.WriteTimeout, usage: "max time to write a response"},
+		{key: "server.idle_timeout", env: []string{"SERVER_IDLE_TIMEOUT"}, target: &c.Server.IdleTimeout, usage: "how long keep-alive connections stay open"},
+		{key: "server.max_header_bytes", env: []string{"SERVER_MAX_HEADER_BYTES"}, target: &c.Server.MaxHeaderBytes},
+		{key: "server.shutdown_timeout", env: []string{"SERVER_SHUTDOWN_TIMEOUT"}, target: &c.Server.ShutdownTimeout, usage: "time allowed to drain requests and flush Kafka on SIGTERM"},
+
+		{key: "database.url", env: []string{"DATABASE_URL"}, target: &c.Database.URL, secret: true, usage: "Postgres URL or DSN; overrides the other database settings"},
+		{key: "database.host", env: []string{"DB_HOST"}, target: &c.Database.Host},
+		{key: "database.port", env: []string{"DB_PORT"}, target: &c.Database.Port},
+		{key: "database.user", env: []string{"DB_USER"}, target: &c.Database.User},
+		{key: "database.password", env: []string{"DB_PASSWORD"}, target: &c.Database.Password, secret: true},
+		{key: "database.name", env: []string{"DB_NAME"}, target: &c.Database.Name},
+		{key: "database.sslmode", env: []string{"DB_SSLMODE"}, target: &c.Database.SSLMode},
+		{key: "database.timezone", env: []string{"DB_TIMEZONE"}, target: &c.Database.TimeZone, usage: "session time zone"},
+		{key: "database.migrate_on_start", env: []string{"DB_MIGRATE_ON_START"}, target: &c.Database.MigrateOnStart, usage: "apply pending migrations at startup"},
+
+		{key: "auth.keycloak_url", env: []string{"KEYCLOAK_URL"}, target: &c.Auth.KeycloakURL},
+		{key: "auth.realm", env: []string{"KEYCLOAK_REALM"}, target: &c.Auth.Realm},
+		{key: "auth.issuer", env: []string{"KEYCLOAK_ISSUER"}, target: &c.Auth.Issuer},
+		{key: "auth.audiences", env: []string{"KEYCLOAK_AUDIENCES"}, target: &c.Auth.Audiences},
+		{key: "auth.allowed_clients", env: []string{"KEYCLOAK_ALLOWED_CLIENTS"}, target: &c.Auth.AllowedClients},
+		{key: "auth.service_clients", env: []string{"KEYCLOAK_SERVICE_CLIENTS"}, target: &c.Auth.ServiceClients},
+		{key: "auth.leeway", env: []string{"KEYCLOAK_LEEWAY"}, target: &c.Auth.Leeway},
+		{key: "auth.jwks_file", env: []string{"KEYCLOAK_JWKS_FILE"}, target: &c.Auth.JWKSFile},
+		{key: "auth.role_sources", env: []string{"KEYCLOAK_ROLE_SOURCES"}, target: &c.Auth.RoleSources},
+		{key: "auth.role_client_id", env: []string{"KEYCLOAK_ROLE_CLIENT_ID"}, target: &c.Auth.RoleClientID},
+		{key: "auth.role_group_prefix", env: []string{"KEYCLOAK_ROLE_GROUP_PREFIX"}, target: &c.Auth.RoleGroupPrefix},
+		{key: "auth.department_group_prefix", env: []string{"KEYCLOAK_DEPARTMENT_GROUP_PREFIX"}, target: &c.Auth.DepartmentGroupPrefix},
+		{key: "auth.max_token_lifetime", env: []string{"KEYCLOAK_MAX_TOKEN_LIFETIME"}, target: &c.Auth.MaxTokenLifetime},
+		{key: "auth.user_cache_ttl", env: []string{"KEYCLOAK_USER_CACHE_TTL"}, target: &c.Auth.UserCacheTTL},
+		{key: "auth.introspection_client_id", env: []string{"KEYCLOAK_INTROSPECTION_CLIENT_ID"}, target: &c.Auth.IntrospectionClientID},
+		{key: "auth.introspection_client_secret", env: []string{"KEYCLOAK_INTROSPECTION_CLIENT_SECRET"}, target: &c.Auth.IntrospectionClientSecret, secret: true},
+		{key: "auth.introspection_cache_ttl", env: []string{"KEYCLOAK_INTROSPECTION_CACHE_TTL"}, target: &c.Auth.IntrospectionCacheTTL},
+
+		// KAFKA_BROKER_URL is kept for existing single-broker setups.
+		{key: "kafka.brokers", env: []string{"KAFKA_BROKERS", "KAFKA_BROKER_URL"}, target: &c.Kafka.Brokers},
+		{key: "kafka.tls_enabled", env: []string{"KAFKA_TLS_ENABLED"}, target: &c.Kafka.TLSEnabled},
+		{key: "kafka.tls_ca_file", env: []string{"KAFKA_TLS_CA_FILE"}, target: &c.Kafka.TLSCAFile},
+		{key: "kafka.tls_cert_file", env: []string{"KAFKA_TLS_CERT_FILE"}, target: &c.Kafka.TLSCertFile},
+		{key: "kafka.tls_key_file", env: []string{"KAFKA_TLS_KEY_FILE"}, target: &c.Kafka.TLSKeyFile},
+		{key: "kafka.tls_insecure_skip_verify", env: []string{"KAFKA_TLS_INSECURE_SKIP_VERIFY"}, target: &c.Kafka.TLSInsecureSkipVerify},
+		{key: "kafka.sasl_mechanism", env: []string{"KAFKA_SASL_MECHANISM"}, target: &c.Kafka.SASLMechanism},
+		{key: "kafka.sasl_username", env: []string{"KAFKA_SASL_USERNAME"}, target: &c.Kafka.SASLUsername},
+		{key: "kafka.sasl_password", env: []string{"KAFKA_SASL_PASSWORD"}, target: &c.Kafka.SASLPassword, secret: true},
+
+		{key: "s3.endpoint", env: []string{"S3_ENDPOINT"}, target: &c.S3.Endpoint},
+		{key: "s3.public_base_url", env: []string{"S3_PUBLIC_BASE_URL"}, target: &c.S3.PublicBaseURL, usage: "base URL clients load images from (default: s3.endpoint)"},
+		{key: "s3.region", env: []string{"S3_REGION"}, target: &c.S3.Region},
+		{key: "s3.access_key", env: []string{"S3_ACCESS_KEY"}, target: &c.S3.AccessKey, secret: true},
+		{key: "s3.secret_key", env: []string{"S3_SECRET_KEY"}, target: &c.S3.SecretKey, secret: true},
+		{key: "s3.bucket", env: []string{"S3_BUCKET"}, target: &c.S3.Bucket},
+
+		{key: "rate_limit.enabled", env: []string{"RATE_LIMIT_ENABLED"}, target: &c.RateLimit.Enabled},
+		{key: "rate_limit.store", env: []string{"RATE_LIMIT_STORE"}, target: &c.RateLimit.Store, usage: "memory or postgres"},
+		{key: "rate_limit.per_ip", env: []string{"RATE_LIMIT_PER_IP"}, target: &c.RateLimit.PerIP},
+		{key: "rate_limit.read", env: []string{"RATE_LIMIT_READ"}, target: &c.RateLimit.Read},
+		{key: "rate_limit.write", env: []string{"RATE_LIMIT_WRITE"}, target: &c.RateLimit.Write},
+		{key: "rate_limit.reports", env: []string{"RATE_LIMIT_REPORTS"}, target: &c.RateLimit.Reports},
+		{key: "rate_limit.uploads", env: []string{"RATE_LIMIT_UPLOADS"}, target: &c.RateLimit.Uploads},
+		{key: "rate_limit.exempt_roles", env: []string{"RATE_LIMIT_EXEMPT_ROLES"}, target: &c.RateLimit.ExemptRoles},
+
+		{key: "consumers.workorders_enabled", env: []string{"WORKORDERS_CONSUMER_ENABLED"}, target: &c.Consumers.WorkOrdersEnabled},
+		{key: "consumers.workorders_group", env: []string{"WORKORDERS_CONSUMER_GROUP"}, target: &c.Consumers.WorkOrdersGroup},
+		{key: "consumers.keycloak_events_enabled", env: []string{"KEYCLOAK_EVENTS_CONSUMER_ENABLED"}, target: &c.Consumers.KeycloakEventsEnabled},
+		{key: "consumers.keycloak_events_topic", env: []string{"KEYCLOAK_EVENTS_TOPIC"}, target: &c.Consumers.KeycloakEventsTopic},
+		{key: "consumers.keycloak_events_group", env: []string{"KEYCLOAK_EVENTS_CONSUMER_GROUP"}, target: &c.Consumers.KeycloakEventsGroup},
+
+		{key: "health.timeout", env: []string{"HEALTH_CHECK_TIMEOUT"}, target: &c.Health.Timeout, usage: "timeout for each readiness check"},
+		{key: "health.cache_ttl", env: []string{"HEALTH_CACHE_TTL"}, target: &c.Health.CacheTTL, usage: "how long readiness results are reused"},
+
+		{key: "metrics.enabled", env: []string{"METRICS_ENABLED"}, target: &c.Metrics.Enabled, usage: "serve Prometheus metrics on /metrics"},
+		{key: "metrics.sla_urgent", env: []string{"SLA_URGENT"}, target: &c.Metrics.SLAUrgent, usage: "resolution target for URGENT reports"},
+		{key: "metrics.sla_high", env: []string{"SLA_HIGH"}, target: &c.Metrics.SLAHigh, usage: "resolution target for HIGH reports"},
+		{key: "metrics.sla_normal", env: []string{"SLA_NORMAL"}, target: &c.Metrics.SLANormal, usage: "resolution target for NORMAL reports"},
+		{key: "metrics.sla_low", env: []string{"SLA_LOW"}, target: &c.Metrics.SLALow, usage: "resolution target for LOW reports"},
+
+		{key: "tracing.exporter", env: []string{"TRACING_EXPORTER"}, target: &c.Tracing.Exporter, usage: "none, stdout or otlp"},
+		{key: "tracing.otlp_endpoint", env: []string{"TRACING_OTLP_ENDPOINT"}, target: &c.Tracing.OTLPEndpoint, usage: "OTLP/HTTP collector URL (default: OTEL_EXPORTER_OTLP_ENDPOINT)"},
+		{key: "tracing.otlp_insecure", env: []string{"TRACING_OTLP_INSECURE"}, target: &c.Tracing.OTLPInsecure, usage: "send OTLP over plain HTTP"},
+		{key: "tracing.service_name", env: []string{"OTEL_SERVICE_NAME"}, target: &c.Tracing.ServiceName},
+		{key: "tracing.sample_ratio", env: []string{"TRACING_SAMPLE_RATIO"}, target: &c.Tracing.SampleRatio, usage: "fraction of new traces to sample, 0 to 1"},
+		{key: "logging.level", env: []string{"LOG_LEVEL"}, target: &c.Logging.Level, usage: "debug, info, warn or error"},
+		{key: "logging.format", env: []string{"LOG_FORMAT"}, target: &c.Logging.Format, usage: "json or text"},
+		{key: "logging.redact_fields", env: []string{"LOG_REDACT_FIELDS"}, target: &c.Logging.RedactFields, usage: "log attribute keys whose values are replaced with [REDACTED]"},
+		{key: "openapi.docs_enabled", env: []string{"OPENAPI_DOCS_ENABLED"}, target: &c.OpenAPI.DocsEnabled, usage: "serve /openapi.json and the /docs UI"},
+		{key: "openapi.validate_responses", env: []string{"OPENAPI_VALIDATE_RESPONSES"}, target: &c.OpenAPI.ValidateResponses, usage: "log responses that do not match the OpenAPI document"},
+
+		{key: "policy_file", env: []string{"POLICY_FILE"}, target: &c.PolicyFile, usage: "role-to-permission policy (default: built in)"},
+	}
+}
+
+// Load builds the configuration from, in increasing precedence: defaults,
+// the YAML file named by -config or CONFIG_FILE, environment variables and
+// command-line flags. Every setting has a flag named after its YAML path,
+// e.g. -server.port. Flags stop at the first non-flag argument; the
+// remaining arguments (a subcommand and its flags) are returned.
+func Load(args []string) (*Config, []string, error) {
+	cfg := Default()
+	bindings := cfg.bindings()
+
+	fs := flag.NewFlagSet("report-management-service", flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "YAML config file")
+	flagValues := make(map[string]*string, len(bindings))
+	for _, b := range bindings {
+		usage := b.usage
+		if usage == "" {
+			usage = b.key
+		}
+		flagValues[b.key] = fs.String(b.key, "", usage+" (env "+strings.Join(b.env, ", ")+")")
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+	setFlags := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+
+	var file map[string]interface{}
+	if *configFile != "" {
+		data, err := os.ReadFile(*configFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return nil, nil, fmt.Errorf("invalid config file %s: %w", *configFile, err)
+		}
+	}
+
+	for _, b := range bindings {
+		if raw, ok := lookupYAML(file, b.key); ok {
+			if err := setValue(b.target, raw); err != nil {
+				return nil, nil, fmt.Errorf("%s in %s: %w", b.key, *configFile, err)
+			}
+		}
+		for _, name := range b.env {
+			if raw := os.Getenv(name); raw != "" {
+				if err := setValue(b.target, raw); err != nil {
+					return nil, nil, fmt.Errorf("%s: %w", name, err)
+				}
+				break
+			}
+		}
+		if setFlags[b.key] {
+			if err := setValue(b.target, *flagValues[b.key]); err != nil {
+				return nil, nil, fmt.Errorf("-%s: %w", b.key, err)
+			}
+		}
+	}
+
+	if cfg.S3.PublicBaseURL == "" {
+		cfg.S3.PublicBaseURL = cfg.S3.Endpoint
+	}
+	if !cfg.RateLimit.Enabled {
+		cfg.RateLimit.PerIP = ratelimit.Limit{}
+		cfg.RateLimit.Read = ratelimit.Limit{}
+		cfg.RateLimit.Write = ratelimit.Limit{}
+		cfg.RateLimit.Reports = ratelimit.Limit{}
+		cfg.RateLimit.Uploads = ratelimit.Limit{}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return &cfg, fs.Args(), nil
+}
+
+// lookupYAML finds a dotted key such as "kafka.brokers" in a decoded YAML
+// document and returns it in the same string form as an env var.
+func lookupYAML(doc map[string]interface{}, key string) (string, bool) {
+	var node interface{} = doc
+	for _, part := range strings.Split(key, ".") {
+		m, ok := node.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		if node, ok = m[part]; !ok {
+			return "", false
+		}
+	}
+
+	switch v := node.(type) {
+	case nil:
+		return "", false
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, ","), true
+	default:
+		return fmt.Sprint(v), true
+	}
+}
+
+func setValue(target interface{}, raw string) error {
+	raw = strings.TrimSpace(raw)
+	switch t := target.(type) {
+	case *string:
+		*t = raw
+	case *int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", raw)
+		}
+		*t = n
+	case *bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", raw)
+		}
+		*t = b
+	case *float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", raw)
+		}
+		*t = f
+	case *time.Duration:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a duration like 30s or 5m", raw)
+		}
+		*t = d
+	case *[]string:
+		*t = SplitList(raw)
+	case *ratelimit.Limit:
+		limit, err := ratelimit.ParseLimit(raw)
+		if err != nil {
+			return err
+		}
+		*t = limit
+	default:
+		return fmt.Errorf("unsupported setting type %T", target)
+	}
+	return nil
+}
+
+// SplitList splits a comma-separated setting, trimming spaces and dropping
+// empty items.
+func SplitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// String lists every setting with secrets redacted, for the config command.
+func (c *Config) String() string {
+	var b strings.Builder
+	for _, binding := range c.bindings() {
+		fmt.Fprintf(&b, "%s=%s\n", binding.key, binding.display())
+	}
+	return b.String()
+}
+
+// LogValue logs every setting as one attribute per key, secrets redacted.
+func (c *Config) LogValue() slog.Value {
+	bindings := c.bindings()
+	attrs := make([]slog.Attr, 0, len(bindings))
+	for _, binding := range bindings {
+		attrs = append(attrs, slog.String(binding.key, binding.display()))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+func (b binding) display() string {
+	value := formatValue(b.target)
+	if b.secret && value != "" {
+		return "[REDACTED]"
+	}
+	return value
+}
+
+func formatValue(target interface{}) string {
+	switch t := target.(type) {
+	case *string:
+		return *t
+	case *[]string:
+		return strings.Join(*t, ",")
+	case *int:
+		return strconv.Itoa(*t)
+	case *bool:
+		return strconv.FormatBool(*t)
+	case *float64:
+		return strconv.FormatFloat(*t, 'g', -1, 64)
+	case *time.Duration:
+		return t.String()
+	case *ratelimit.Limit:
+		return t.String()
+	default:
+		return fmt.Sprint(target)
+	}
+}
@@ -0,0 +1,344 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"reportmaxxing/services/report-management-service/models"
+	"reportmaxxing/services/report-management-service/repository"
+	"reportmaxxing/services/report-management-service/services"
+)
+
+// The fakes keep just enough state for the routes to return realistic
+// bodies, and reject the same inputs the real services reject before
+// touching the database.
+
+var fixtureTime = time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+
+type fakeReports struct {
+	mu      sync.Mutex
+	reports map[string]*models.Report
+	// listed records which list query the last GET /api/reports used.
+	listed string
+}
+
+func newFakeReports(reports ...models.Report) *fakeReports {
+	f := &fakeReports{reports: map[string]*models.Report{}}
+	for i := range reports {
+		f.reports[reports[i].ID] = &reports[i]
+	}
+	return f
+}
+
+func (f *fakeReports) GetReportByID(id string) (*models.Report, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	report, ok := f.reports[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *report
+	return &copied, nil
+}
+
+func (f *fakeReports) list(query string, keep func(*models.Report) bool) []models.Report {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.listed = query
+	reports := []models.Report{}
+	for _, report := range f.reports {
+		if keep(report) {
+			reports = append(reports, *report)
+		}
+	}
+	return reports
+}
+
+func (f *fakeReports) GetAllReports() ([]models.Report, error) {
+	return f.list("all", func(*models.Report) bool { return true }), nil
+}
+
+func (f *fakeReports) GetVisibleReports(departments []string) ([]models.Report, error) {
+	return f.list("visible", func(r *models.Report) bool {
+		if r.Visibility == models.VisibilityPrivate {
+			return false
+		}
+		for _, department := range departments {
+			if strings.EqualFold(department, string(r.Category)) {
+				return true
+			}
+		}
+		return len(departments) == 0
+	}), nil
+}
+
+func (f *fakeReports) GetReportsByUserID(userID string) ([]models.Report, error) {
+	return f.list("own", func(r *models.Report) bool { return r.UserID == userID || r.CreatedByID == userID }), nil
+}
+
+func (f *fakeReports) GetReportStats(ctx context.Context, userID string) (*services.ReportStats, error) {
+	return &services.ReportStats{Open: 1}, nil
+}
+
+func (f *fakeReports) CreateReport(ctx context.Context, actor services.Actor, req models.CreateReportRequest) (*models.Report, error) {
+	return f.create(actor.UserID, actor.UserID, models.IntakeChannelApp, req), nil
+}
+
+func (f *fakeReports) CreateReportOnBehalf(ctx context.Context, actor services.Actor, reporterID string, channel models.IntakeChannel, req models.CreateReportRequest) (*models.Report, error) {
+	switch channel {
+	case models.IntakeChannelPhone, models.IntakeChannelEmail, models.IntakeChannelWalkIn, models.IntakeChannelPartner:
+	default:
+		return nil, services.ErrInvalidIntakeChannel
+	}
+	return f.create(reporterID, actor.UserID, channel, req), nil
+}
+
+// create stores the new report as RPT-NEW, replacing the last one created.
+func (f *fakeReports) create(userID, createdByID string, channel models.IntakeChannel, req models.CreateReportRequest) *models.Report {
+	report := &models.Report{
+		ID:            "RPT-NEW",
+		Title:         req.Title,
+		Description:   req.Description,
+		Category:      models.ReportCategory(req.Category),
+		Status:        models.StatusOpen,
+		Visibility:    models.ReportVisibility(req.Visibility),
+		Priority:      models.PriorityNormal,
+		ImageURL:      req.ImageURL,
+		CreatedAt:     fixtureTime,
+		UpdatedAt:     fixtureTime,
+		UserID:        userID,
+		CreatedByID:   createdByID,
+		IntakeChannel: channel,
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	copied := *report
+	f.reports[report.ID] = &copied
+	return report
+}
+
+// mutate applies change to a copy of the report, leaving the fixture as it
+// was for the next request.
+func (f *fakeReports) mutate(id string, change func(*models.Report) error) (*models.Report, error) {
+	report, err := f.GetReportByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := change(report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (f *fakeReports) UpdateReportStatus(ctx context.Context, actor services.Actor, reportID string, newStatus models.ReportStatus) (*models.Report, error) {
+	return f.mutate(reportID, func(r *models.Report) error {
+		r.Status = newStatus
+		return nil
+	})
+}
+
+func (f *fakeReports) EditReport(ctx context.Context, actor services.Actor, reportID string, req models.UpdateReportRequest) (*models.Report, error) {
+	return f.mutate(reportID, func(r *models.Report) error {
+		if req.Title == nil && req.Description == nil && req.Category == nil && req.Visibility == nil {
+			return services.ErrNoChanges
+		}
+		if req.Title != nil {
+			if strings.TrimSpace(*req.Title) == "" {
+				return services.ErrEmptyTitle
+			}
+			r.Title = *req.Title
+		}
+		if req.Description != nil {
+			r.Description = *req.Description
+		}
+		return nil
+	})
+}
+
+func (f *fakeReports) WithdrawReport(ctx context.Context, actor services.Actor, reportID string) (*models.Report, error) {
+	return f.mutate(reportID, func(r *models.Report) error {
+		r.Status = models.StatusWithdrawn
+		return nil
+	})
+}
+
+func (f *fakeReports) AddComment(ctx context.Context, actor services.Actor, reportID, body string) (*models.Report, error) {
+	return f.mutate(reportID, func(r *models.Report) error {
+		r.Comments = append(r.Comments, models.ReportComment{ID: "CMT-1", ReportID: r.ID, AuthorID: actor.UserID, Body: body, CreatedAt: fixtureTime})
+		return nil
+	})
+}
+
+func (f *fakeReports) AddAttachment(ctx context.Context, actor services.Actor, reportID string, req models.AddAttachmentRequest) (*models.Report, error) {
+	return f.mutate(reportID, func(r *models.Report) error {
+		r.Attachments = append(r.Attachments, models.ReportAttachment{ID: "ATT-1", ReportID: r.ID, UploaderID: actor.UserID, URL: req.URL, ContentType: req.ContentType, CreatedAt: fixtureTime})
+		return nil
+	})
+}
+
+func (f *fakeReports) AssignReport(ctx context.Context, actor services.Actor, reportID, assigneeID string) (*models.Report, error) {
+	return f.mutate(reportID, func(r *models.Report) error {
+		r.AssigneeID = assigneeID
+		return nil
+	})
+}
+
+func (f *fakeReports) ChangePriority(ctx context.Context, actor services.Actor, reportID string, priority models.ReportPriority) (*models.Report, error) {
+	return f.mutate(reportID, func(r *models.Report) error {
+		switch priority {
+		case models.PriorityLow, models.PriorityNormal, models.PriorityHigh, models.PriorityUrgent:
+		default:
+			return services.ErrInvalidPriority
+		}
+		r.Priority = priority
+		return nil
+	})
+}
+
+func (f *fakeReports) MergeReport(ctx context.Context, actor services.Actor, reportID, targetID string) (*models.Report, error) {
+	if _, err := f.GetReportByID(targetID); err != nil {
+		return nil, err
+	}
+	return f.mutate(reportID, func(r *models.Report) error {
+		if targetID == reportID {
+			return services.ErrInvalidMerge
+		}
+		r.MergedIntoID = targetID
+		r.Status = models.StatusResolved
+		return nil
+	})
+}
+
+func (f *fakeReports) ReopenReport(ctx context.Context, actor services.Actor, reportID string) (*models.Report, error) {
+	return f.mutate(reportID, func(r *models.Report) error {
+		r.Status = models.StatusOpen
+		return nil
+	})
+}
+
+func (f *fakeReports) DeleteReport(ctx context.Context, actor services.Actor, reportID string) error {
+	_, err := f.GetReportByID(reportID)
+	return err
+}
+
+type fakeUsers struct {
+	users map[string]models.User
+}
+
+func newFakeUsers(users ...models.User) *fakeUsers {
+	f := &fakeUsers{users: map[string]models.User{}}
+	for _, user := range users {
+		f.users[user.ID] = user
+	}
+	return f
+}
+
+func (f *fakeUsers) GetUserByID(ctx context.Context, userID string) (*models.User, error) {
+	user, ok := f.users[userID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &user, nil
+}
+
+func (f *fakeUsers) FindOrCreateReporter(ctx context.Context, req models.ReporterRequest) (*models.User, error) {
+	if req.Email == "" && req.Phone == "" {
+		return nil, services.ErrReporterRequired
+	}
+	return &models.User{ID: "resident-1", Email: req.Email, Name: req.Name, Phone: req.Phone, Status: models.UserStatusActive, CreatedAt: fixtureTime, UpdatedAt: fixtureTime}, nil
+}
+
+func (f *fakeUsers) GetModerationHistory(ctx context.Context, userID string) ([]models.UserModerationAction, error) {
+	return []models.UserModerationAction{{
+		ID:        "MOD-1",
+		UserID:    userID,
+		Action:    models.ModerationActionSuspend,
+		Reason:    "spam",
+		ActorID:   "supervisor-1",
+		CreatedAt: fixtureTime,
+	}}, nil
+}
+
+func (f *fakeUsers) SuspendUser(ctx context.Context, actor services.Actor, userID string, req models.SuspendUserRequest) (*models.User, error) {
+	if req.Until != nil && !req.Until.After(time.Now()) {
+		return nil, services.ErrInvalidSuspension
+	}
+	user, err := f.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	user.Status = models.UserStatusSuspended
+	user.SuspendedUntil = req.Until
+	user.SuspensionReason = req.Reason
+	return user, nil
+}
+
+func (f *fakeUsers) UnsuspendUser(ctx context.Context, actor services.Actor, userID string, req models.UnsuspendUserRequest) (*models.User, error) {
+	user, err := f.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	user.Status = models.UserStatusActive
+	return user, nil
+}
+
+type fakeUploads struct{}
+
+func (fakeUploads) GenerateUploadURL(ctx context.Context, userID, fileName, contentType string) (string, string, string, error) {
+	key := userID + "/" + fileName
+	return "https://uploads.example.test/" + key + "?signature=x", "https://images.example.test/" + key, key, nil
+}
+
+type fakeWorkOrders struct {
+	reports *fakeReports
+}
+
+func (f fakeWorkOrders) LinkWorkOrder(ctx context.Context, reportID, workOrderID string) (*models.WorkOrderLink, error) {
+	if _, err := f.reports.GetReportByID(reportID); err != nil {
+		return nil, err
+	}
+	return &models.WorkOrderLink{WorkOrderID: workOrderID, ReportID: reportID, CreatedAt: fixtureTime, UpdatedAt: fixtureTime}, nil
+}
+
+type fakeRevocations struct {
+	revocations []models.TokenRevocation
+}
+
+func (f *fakeRevocations) ListRevocations(ctx context.Context) ([]models.TokenRevocation, error) {
+	return f.revocations, nil
+}
+
+func (f *fakeRevocations) Revoke(ctx context.Context, actor services.Actor, req models.RevokeTokensRequest) (*models.TokenRevocation, error) {
+	if (req.Subject == "") == (req.SessionID == "") {
+		return nil, services.ErrInvalidRevocation
+	}
+	return &models.TokenRevocation{
+		ID:        "REV-NEW",
+		Subject:   req.Subject,
+		SessionID: req.SessionID,
+		Reason:    req.Reason,
+		Source:    services.RevocationSourceAdmin,
+		RevokedBy: actor.UserID,
+		CreatedAt: fixtureTime,
+	}, nil
+}
+
+func (f *fakeRevocations) DeleteRevocation(ctx context.Context, id string) error {
+	for _, revocation := range f.revocations {
+		if revocation.ID == id {
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+type fakeDenyList struct {
+	refreshes int
+}
+
+func (f *fakeDenyList) Refresh(ctx context.Context) error {
+	f.refreshes++
+	return nil
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"reportmaxxing/services/report-management-service/middleware"
+	"reportmaxxing/services/report-management-service/repository"
+	"reportmaxxing/services/report-management-service/response"
+	"reportmaxxing/services/report-management-service/services"
+)
+
+func actorFromContext(c *gin.Context) services.Actor {
+	return services.Actor{
+		UserID:        c.GetString("userID"),
+		ClientID:      c.GetString("clientID"),
+		CorrelationID: c.GetString("correlationID"),
+		Roles:         middleware.PrincipalFromContext(c).Roles,
+	}
+}
+
+// primaryRole picks the role shown in the profile, preferring the most
+// privileged one.
+func primaryRole(roles []string) string {
+	for _, role := range []string{"ADMIN", "SUPERVISOR", "DEPARTMENT_STAFF", "AUDITOR"} {
+		if middleware.HasRole(roles, role) {
+			return role
+		}
+	}
+	return "CITIZEN"
+}
+
+func respondMutationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		response.NotFound(c, "Report not found")
+	case errors.Is(err, services.ErrInvalidTransition), errors.Is(err, services.ErrInvalidMerge):
+		response.Conflict(c, err.Error())
+	case errors.Is(err, services.ErrInvalidPriority), errors.Is(err, services.ErrNoChanges),
+		errors.Is(err, services.ErrInvalidCategory), errors.Is(err, services.ErrInvalidVisibility),
+		errors.Is(err, services.ErrEmptyTitle), errors.Is(err, services.ErrEmptyDescription):
+		response.BadRequest(c, err.Error())
+	default:
+		middleware.Logger(c).Error("report-mutation: failed", "report_id", c.Param("id"), "error", err)
+		response.InternalError(c, "Failed to update report")
+	}
+}
+
+func respondModerationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		response.NotFound(c, "User not found")
+	case errors.Is(err, services.ErrNotSuspended):
+		response.Conflict(c, err.Error())
+	case errors.Is(err, services.ErrModerationRank):
+		response.Forbidden(c, err.Error())
+	case errors.Is(err, services.ErrInvalidSuspension), errors.Is(err, services.ErrSelfModeration):
+		response.BadRequest(c, err.Error())
+	default:
+		middleware.Logger(c).Error("user-moderation: failed", "target_user_id", c.Param("id"), "error", err)
+		response.InternalError(c, "Failed to update user")
+	}
+}
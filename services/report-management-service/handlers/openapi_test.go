@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+
+	"reportmaxxing/services/report-management-service/config"
+	"reportmaxxing/services/report-management-service/openapi"
+)
+
+// probeTests are the documented routes outside /api, which need no token.
+var probeTests = []routeTest{
+	{operation: "livez", method: "GET", path: "/livez", want: http.StatusOK},
+	{operation: "readyz", method: "GET", path: "/readyz", want: http.StatusOK},
+	{operation: "health", method: "GET", path: "/health", want: http.StatusOK},
+	{operation: "metrics", method: "GET", path: "/metrics", want: http.StatusOK},
+	{operation: "openapiSpec", method: "GET", path: "/openapi.json", want: http.StatusOK},
+	{operation: "openapiDocs", method: "GET", path: "/docs", want: http.StatusOK},
+}
+
+// TestResponsesMatchOpenAPI sends every request of the route tests through
+// the router and checks each response, errors included, against
+// openapi.yaml the same way openapi.ValidateResponses does in a running
+// service. It also fails when a documented operation has no test.
+func TestResponsesMatchOpenAPI(t *testing.T) {
+	s := newTestServer(t, func(cfg *config.Config) {
+		cfg.Metrics.Enabled = true
+		cfg.OpenAPI.DocsEnabled = true
+	})
+	doc, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("load openapi.yaml: %v", err)
+	}
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	covered := map[string]bool{}
+	check := func(t *testing.T, tt routeTest, outcome, token, body string, want int) {
+		t.Helper()
+		rec := s.do(t, tt.method, tt.path, token, body)
+		if rec.Code != want {
+			t.Fatalf("%s: status = %d, want %d: %s", outcome, rec.Code, want, rec.Body)
+		}
+
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		route, pathParams, err := router.FindRoute(req)
+		if err != nil {
+			t.Fatalf("%s %s is not in openapi.yaml: %v", tt.method, tt.path, err)
+		}
+		if route.Operation.OperationID != tt.operation {
+			t.Fatalf("%s %s is operation %q in openapi.yaml, want %q", tt.method, tt.path, route.Operation.OperationID, tt.operation)
+		}
+		covered[tt.operation] = true
+
+		if err := validateResponse(req, route, pathParams, rec); err != nil {
+			t.Errorf("%s: %d response does not match openapi.yaml: %v\nbody: %s", outcome, rec.Code, err, rec.Body)
+		}
+	}
+
+	for _, tt := range probeTests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			check(t, tt, "probe", "", "", tt.want)
+		})
+	}
+	for _, tt := range routeTests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			check(t, tt, "unauthenticated", "", tt.body, http.StatusUnauthorized)
+			if tt.forbidden != nil {
+				check(t, tt, "forbidden", s.token(t, *tt.forbidden), tt.body, http.StatusForbidden)
+			}
+			if tt.invalid != "" {
+				check(t, tt, "invalid", s.token(t, tt.allowed), tt.invalid, http.StatusBadRequest)
+			}
+			check(t, tt, "allowed", s.token(t, tt.allowed), tt.body, tt.want)
+		})
+	}
+
+	var missing []string
+	for path, item := range doc.Paths.Map() {
+		for method, operation := range item.Operations() {
+			if !covered[operation.OperationID] {
+				missing = append(missing, method+" "+path+" ("+operation.OperationID+")")
+			}
+		}
+	}
+	sort.Strings(missing)
+	if len(missing) > 0 {
+		t.Errorf("documented operations without a test request:\n%s", strings.Join(missing, "\n"))
+	}
+}
+
+func validateResponse(req *http.Request, route *routers.Route, pathParams map[string]string, rec *httptest.ResponseRecorder) error {
+	input := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: &openapi3filter.RequestValidationInput{
+			Request:    req,
+			PathParams: pathParams,
+			Route:      route,
+		},
+		Status: rec.Code,
+		Header: rec.Header(),
+		Options: &openapi3filter.Options{
+			IncludeResponseStatus: true,
+			MultiError:            true,
+			ExcludeResponseBody:   !strings.HasPrefix(rec.Header().Get("Content-Type"), "application/json"),
+		},
+	}
+	input.SetBodyBytes(rec.Body.Bytes())
+	return openapi3filter.ValidateResponse(context.Background(), input)
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"reportmaxxing/services/report-management-service/middleware"
+	"reportmaxxing/services/report-management-service/policy"
+	"reportmaxxing/services/report-management-service/response"
+)
+
+type profileHandler struct {
+	reports ReportService
+	policy  *policy.Policy
+}
+
+// get returns the current user's info with their report stats.
+func (h *profileHandler) get(c *gin.Context) {
+	userID := c.GetString("userID")
+	roles := c.MustGet("roles").([]string)
+
+	stats, err := h.reports.GetReportStats(c.Request.Context(), userID)
+	if err != nil {
+		response.InternalError(c, "Failed to fetch report stats")
+		return
+	}
+
+	response.Success(c, gin.H{
+		"id":               userID,
+		"email":            c.GetString("email"),
+		"name":             c.GetString("name"),
+		"role":             primaryRole(roles),
+		"roles":            roles,
+		"permissions":      h.policy.Permissions(middleware.PrincipalFromContext(c)),
+		"open_reports":     stats.Open,
+		"resolved_reports": stats.Resolved,
+	})
+}
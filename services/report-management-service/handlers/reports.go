@@ -0,0 +1,317 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"reportmaxxing/services/report-management-service/middleware"
+	"reportmaxxing/services/report-management-service/models"
+	"reportmaxxing/services/report-management-service/policy"
+	"reportmaxxing/services/report-management-service/response"
+	"reportmaxxing/services/report-management-service/services"
+)
+
+type reportHandler struct {
+	reports    ReportService
+	users      UserService
+	uploads    UploadService
+	workOrders WorkOrderService
+	policy     *policy.Policy
+}
+
+func (h *reportHandler) list(c *gin.Context) {
+	principal := middleware.PrincipalFromContext(c)
+
+	var reports []models.Report
+	var err error
+
+	switch h.policy.Scope(principal, policy.ActionReportRead) {
+	case policy.ScopeAny:
+		if h.policy.Allowed(principal, policy.ActionReportReadPrivate) {
+			reports, err = h.reports.GetAllReports()
+			break
+		}
+		reports, err = h.reports.GetVisibleReports(nil)
+	case policy.ScopeDepartment:
+		if len(principal.Departments) == 0 {
+			reports, err = h.reports.GetReportsByUserID(principal.UserID)
+			break
+		}
+		reports, err = h.reports.GetVisibleReports(principal.Departments)
+	default:
+		reports, err = h.reports.GetReportsByUserID(principal.UserID)
+	}
+
+	if err != nil {
+		response.InternalError(c, "Failed to fetch reports")
+		return
+	}
+	response.Success(c, reports)
+}
+
+// get serves the report loaded by Authorizer.RequireOnReport.
+func (h *reportHandler) get(c *gin.Context) {
+	response.Success(c, c.MustGet("report"))
+}
+
+func (h *reportHandler) create(c *gin.Context) {
+	var req models.CreateReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.Logger(c).Info("create-report: invalid payload", "error", err)
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	logger := middleware.Logger(c)
+	logger.Info("create-report: received",
+		"title", req.Title,
+		"category", req.Category,
+		"visibility", req.Visibility,
+		"has_image", req.ImageURL != "",
+	)
+	report, err := h.reports.CreateReport(c.Request.Context(), actorFromContext(c), req)
+	if err != nil {
+		logger.Error("create-report: failed", "error", err)
+		response.InternalError(c, "Failed to create report")
+		return
+	}
+	logger.Info("create-report: success", "report_id", report.ID)
+	response.CreatedWithMessage(c, "Report created successfully", report)
+}
+
+// createOnBehalf lets staff (and call-center integrations) file reports for
+// residents who phone or walk in. The report belongs to the resident; the
+// caller is recorded as its creator.
+func (h *reportHandler) createOnBehalf(c *gin.Context) {
+	var req models.CreateReportOnBehalfRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	reporter, err := h.users.FindOrCreateReporter(c.Request.Context(), req.Reporter)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrReporterRequired):
+			response.BadRequest(c, err.Error())
+		case errors.Is(err, services.ErrReporterSuspended):
+			response.Conflict(c, err.Error())
+		default:
+			middleware.Logger(c).Error("create-report-on-behalf: reporter lookup failed", "error", err)
+			response.InternalError(c, "Failed to resolve reporter")
+		}
+		return
+	}
+
+	channel := models.IntakeChannel(strings.ToUpper(req.Channel))
+	report, err := h.reports.CreateReportOnBehalf(c.Request.Context(), actorFromContext(c), reporter.ID, channel, req.CreateReportRequest)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidIntakeChannel) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		middleware.Logger(c).Error("create-report-on-behalf: failed", "error", err)
+		response.InternalError(c, "Failed to create report")
+		return
+	}
+	middleware.Logger(c).Info("create-report-on-behalf: success", "reporter_id", reporter.ID, "report_id", report.ID, "channel", channel)
+	response.CreatedWithMessage(c, "Report created successfully", report)
+}
+
+func (h *reportHandler) uploadURL(c *gin.Context) {
+	var req struct {
+		FileName    string `json:"file_name" binding:"required"`
+		ContentType string `json:"content_type" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		middleware.Logger(c).Info("upload-url: invalid payload", "error", err)
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	userID := c.GetString("userID")
+	logger := middleware.Logger(c)
+	logger.Info("upload-url: received", "file_name", req.FileName, "content_type", req.ContentType)
+	uploadURL, imageURL, objectKey, err := h.uploads.GenerateUploadURL(
+		c.Request.Context(),
+		userID,
+		req.FileName,
+		req.ContentType,
+	)
+	if err != nil {
+		logger.Error("upload-url: failed", "error", err)
+		response.InternalError(c, "Failed to create upload URL")
+		return
+	}
+	logger.Info("upload-url: success", "object_key", objectKey)
+
+	response.Success(c, gin.H{
+		"upload_url": uploadURL,
+		"image_url":  imageURL,
+		"object_key": objectKey,
+	})
+}
+
+func (h *reportHandler) updateStatus(c *gin.Context) {
+	id := c.Param("id")
+
+	var req struct {
+		Status string `json:"status" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	report, err := h.reports.UpdateReportStatus(c.Request.Context(), actorFromContext(c), id, models.ReportStatus(req.Status))
+	if err != nil {
+		response.NotFound(c, "Report not found")
+		return
+	}
+	response.Success(c, report)
+}
+
+func (h *reportHandler) edit(c *gin.Context) {
+	var req models.UpdateReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	report, err := h.reports.EditReport(c.Request.Context(), actorFromContext(c), c.Param("id"), req)
+	if err != nil {
+		respondMutationError(c, err)
+		return
+	}
+	response.Success(c, report)
+}
+
+func (h *reportHandler) withdraw(c *gin.Context) {
+	report, err := h.reports.WithdrawReport(c.Request.Context(), actorFromContext(c), c.Param("id"))
+	if err != nil {
+		respondMutationError(c, err)
+		return
+	}
+	response.Success(c, report)
+}
+
+func (h *reportHandler) addComment(c *gin.Context) {
+	var req models.AddCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	report, err := h.reports.AddComment(c.Request.Context(), actorFromContext(c), c.Param("id"), req.Body)
+	if err != nil {
+		respondMutationError(c, err)
+		return
+	}
+	response.CreatedWithMessage(c, "Comment added", report)
+}
+
+func (h *reportHandler) addAttachment(c *gin.Context) {
+	var req models.AddAttachmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	report, err := h.reports.AddAttachment(c.Request.Context(), actorFromContext(c), c.Param("id"), req)
+	if err != nil {
+		respondMutationError(c, err)
+		return
+	}
+	response.CreatedWithMessage(c, "Attachment added", report)
+}
+
+func (h *reportHandler) assign(c *gin.Context) {
+	var req models.AssignReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	report, err := h.reports.AssignReport(c.Request.Context(), actorFromContext(c), c.Param("id"), req.AssigneeID)
+	if err != nil {
+		respondMutationError(c, err)
+		return
+	}
+	response.Success(c, report)
+}
+
+func (h *reportHandler) changePriority(c *gin.Context) {
+	var req models.UpdatePriorityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	report, err := h.reports.ChangePriority(c.Request.Context(), actorFromContext(c), c.Param("id"), models.ReportPriority(req.Priority))
+	if err != nil {
+		respondMutationError(c, err)
+		return
+	}
+	response.Success(c, report)
+}
+
+// merge needs the merge permission on the target report too, which
+// RequireOnReport only checked for the report being merged.
+func (h *reportHandler) merge(c *gin.Context) {
+	var req models.MergeReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	target, err := h.reports.GetReportByID(req.TargetReportID)
+	if err != nil {
+		respondMutationError(c, err)
+		return
+	}
+	if !h.policy.AllowedOn(middleware.PrincipalFromContext(c), policy.ActionReportMerge, target) {
+		response.Forbidden(c, "Access denied")
+		return
+	}
+
+	report, err := h.reports.MergeReport(c.Request.Context(), actorFromContext(c), c.Param("id"), req.TargetReportID)
+	if err != nil {
+		respondMutationError(c, err)
+		return
+	}
+	response.Success(c, report)
+}
+
+func (h *reportHandler) reopen(c *gin.Context) {
+	report, err := h.reports.ReopenReport(c.Request.Context(), actorFromContext(c), c.Param("id"))
+	if err != nil {
+		respondMutationError(c, err)
+		return
+	}
+	response.Success(c, report)
+}
+
+func (h *reportHandler) linkWorkOrder(c *gin.Context) {
+	var req models.LinkWorkOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	link, err := h.workOrders.LinkWorkOrder(c.Request.Context(), c.Param("id"), req.WorkOrderID)
+	if err != nil {
+		respondMutationError(c, err)
+		return
+	}
+	response.Created(c, link)
+}
+
+func (h *reportHandler) delete(c *gin.Context) {
+	if err := h.reports.DeleteReport(c.Request.Context(), actorFromContext(c), c.Param("id")); err != nil {
+		respondMutationError(c, err)
+		return
+	}
+	response.SuccessWithMessage(c, "Report deleted", nil)
+}
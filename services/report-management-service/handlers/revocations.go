@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"reportmaxxing/services/report-management-service/middleware"
+	"reportmaxxing/services/report-management-service/models"
+	"reportmaxxing/services/report-management-service/repository"
+	"reportmaxxing/services/report-management-service/response"
+	"reportmaxxing/services/report-management-service/services"
+)
+
+type revocationHandler struct {
+	revocations RevocationService
+	denyList    DenyList
+}
+
+func (h *revocationHandler) list(c *gin.Context) {
+	revocations, err := h.revocations.ListRevocations(c.Request.Context())
+	if err != nil {
+		response.InternalError(c, "Failed to fetch revocations")
+		return
+	}
+	response.Success(c, revocations)
+}
+
+func (h *revocationHandler) create(c *gin.Context) {
+	var req models.RevokeTokensRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	revocation, err := h.revocations.Revoke(c.Request.Context(), actorFromContext(c), req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRevocation) {
+			response.BadRequest(c, err.Error())
+			return
+		}
+		response.InternalError(c, "Failed to revoke tokens")
+		return
+	}
+	h.refresh(c)
+	response.Created(c, revocation)
+}
+
+func (h *revocationHandler) delete(c *gin.Context) {
+	if err := h.revocations.DeleteRevocation(c.Request.Context(), c.Param("id")); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			response.NotFound(c, "Revocation not found")
+			return
+		}
+		response.InternalError(c, "Failed to delete revocation")
+		return
+	}
+	h.refresh(c)
+	response.SuccessWithMessage(c, "Revocation lifted", nil)
+}
+
+func (h *revocationHandler) refresh(c *gin.Context) {
+	if err := h.denyList.Refresh(c.Request.Context()); err != nil {
+		middleware.Logger(c).Error("revocations: refresh failed", "error", err)
+	}
+}
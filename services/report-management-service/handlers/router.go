@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+
+	"reportmaxxing/services/report-management-service/config"
+	"reportmaxxing/services/report-management-service/health"
+	"reportmaxxing/services/report-management-service/metrics"
+	"reportmaxxing/services/report-management-service/middleware"
+	"reportmaxxing/services/report-management-service/openapi"
+	"reportmaxxing/services/report-management-service/policy"
+)
+
+// quietRoutes are probes and scrapes, which would drown out real traffic in
+// the trace backend and the logs.
+var quietRoutes = []string{"/livez", "/readyz", "/health", "/metrics"}
+
+// Dependencies are what the routes are built from. The middleware is passed
+// in rather than built here because main also runs its background work.
+type Dependencies struct {
+	Reports     ReportService
+	Users       UserService
+	Uploads     UploadService
+	WorkOrders  WorkOrderService
+	Revocations RevocationService
+	DenyList    DenyList
+
+	Auth        *middleware.AuthMiddleware
+	Authorizer  *middleware.Authorizer
+	RateLimiter *middleware.RateLimiter
+	Readiness   *health.Checker
+}
+
+// NewRouter builds the service's HTTP handler: the shared middleware chain,
+// the probes, /metrics and the API docs when enabled, and the authenticated
+// /api routes.
+func NewRouter(cfg *config.Config, deps Dependencies) (*gin.Engine, error) {
+	r := gin.New()
+	// Rate limits key anonymous requests by client IP, so only believe
+	// X-Forwarded-For from proxies we run.
+	if err := r.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		return nil, err
+	}
+	r.Use(otelgin.Middleware(cfg.Tracing.ServiceName, otelgin.WithGinFilter(func(c *gin.Context) bool {
+		return !slices.Contains(quietRoutes, c.FullPath())
+	})))
+	r.Use(middleware.CorrelationID(), middleware.TraceContext(), middleware.RequestLogger(quietRoutes...), middleware.Recovery())
+	if cfg.Metrics.Enabled {
+		r.Use(metrics.Middleware())
+	}
+
+	doc, err := openapi.Load()
+	if err != nil {
+		return nil, fmt.Errorf("openapi: %w", err)
+	}
+	if cfg.OpenAPI.ValidateResponses {
+		validate, err := openapi.ValidateResponses(doc)
+		if err != nil {
+			return nil, fmt.Errorf("openapi: %w", err)
+		}
+		r.Use(validate)
+	}
+
+	r.GET("/livez", health.Livez)
+	r.GET("/readyz", deps.Readiness.Readyz)
+	r.GET("/health", func(c *gin.Context) {
+		c.JSON(200, gin.H{"status": "ok"})
+	})
+	if cfg.Metrics.Enabled {
+		r.GET("/metrics", metrics.Handler())
+	}
+	if cfg.OpenAPI.DocsEnabled {
+		spec, err := openapi.SpecHandler(doc)
+		if err != nil {
+			return nil, fmt.Errorf("openapi: %w", err)
+		}
+		r.GET("/openapi.json", spec)
+		r.GET("/docs", openapi.DocsHandler("/openapi.json"))
+	}
+
+	permissionPolicy := deps.Authorizer.Policy()
+	authorizer := deps.Authorizer
+	rateLimiter := deps.RateLimiter
+	profile := &profileHandler{reports: deps.Reports, policy: permissionPolicy}
+	reports := &reportHandler{
+		reports:    deps.Reports,
+		users:      deps.Users,
+		uploads:    deps.Uploads,
+		workOrders: deps.WorkOrders,
+		policy:     permissionPolicy,
+	}
+	users := &userHandler{users: deps.Users, auth: deps.Auth}
+	revocations := &revocationHandler{revocations: deps.Revocations, denyList: deps.DenyList}
+
+	api := r.Group("/api")
+	api.Use(
+		rateLimiter.Limit("ip", cfg.RateLimit.PerIP),
+		deps.Auth.Authenticate(),
+		rateLimiter.LimitByMethod("api", cfg.RateLimit.Read, cfg.RateLimit.Write),
+	)
+	{
+		api.GET("/profile", profile.get)
+
+		api.GET("/reports", reports.list)
+		api.GET("/reports/:id", authorizer.RequireOnReport(policy.ActionReportRead), reports.get)
+		api.POST("/reports", authorizer.Require(policy.ActionReportCreate), rateLimiter.Limit("reports", cfg.RateLimit.Reports), reports.create)
+		api.POST("/reports/on-behalf", authorizer.Require(policy.ActionReportCreateOnBehalf), rateLimiter.Limit("reports", cfg.RateLimit.Reports), reports.createOnBehalf)
+		api.POST("/reports/upload-url", authorizer.Require(policy.ActionUploadCreate), rateLimiter.Limit("uploads", cfg.RateLimit.Uploads), reports.uploadURL)
+		api.PUT("/reports/:id/status", deps.Auth.RequireActiveToken(), authorizer.RequireOnReport(policy.ActionReportStatusUpdate), reports.updateStatus)
+		api.PUT("/reports/:id", authorizer.RequireOnReport(policy.ActionReportUpdate), reports.edit)
+		api.POST("/reports/:id/withdraw", authorizer.RequireOnReport(policy.ActionReportWithdraw), reports.withdraw)
+		api.POST("/reports/:id/comments", authorizer.RequireOnReport(policy.ActionReportComment), reports.addComment)
+		api.POST("/reports/:id/attachments", authorizer.RequireOnReport(policy.ActionReportAttach), reports.addAttachment)
+		api.PUT("/reports/:id/assignee", authorizer.RequireOnReport(policy.ActionReportAssign), reports.assign)
+		api.PUT("/reports/:id/priority", authorizer.RequireOnReport(policy.ActionReportPriorityUpdate), reports.changePriority)
+		api.POST("/reports/:id/merge", deps.Auth.RequireActiveToken(), authorizer.RequireOnReport(policy.ActionReportMerge), reports.merge)
+		api.POST("/reports/:id/reopen", authorizer.RequireOnReport(policy.ActionReportReopen), reports.reopen)
+		api.POST("/reports/:id/work-orders", authorizer.RequireOnReport(policy.ActionWorkOrderLink), reports.linkWorkOrder)
+		api.DELETE("/reports/:id", deps.Auth.RequireActiveToken(), authorizer.RequireOnReport(policy.ActionReportDelete), reports.delete)
+
+		moderation := api.Group("/users/:id", authorizer.Require(policy.ActionUserSuspend))
+		moderation.GET("/moderation", users.moderationHistory)
+		moderation.POST("/suspend", users.suspend)
+		moderation.POST("/unsuspend", users.unsuspend)
+
+		admin := api.Group("/admin", deps.Auth.RequireActiveToken(), authorizer.Require(policy.ActionSessionRevoke))
+		admin.GET("/revocations", revocations.list)
+		admin.POST("/revocations", revocations.create)
+		admin.DELETE("/revocations/:id", revocations.delete)
+	}
+
+	return r, nil
+}
@@ -0,0 +1,519 @@
+package handlers
+
+import (
+	"crypto/rsa"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"reportmaxxing/services/report-management-service/config"
+	"reportmaxxing/services/report-management-service/health"
+	"reportmaxxing/services/report-management-service/middleware"
+	"reportmaxxing/services/report-management-service/models"
+	"reportmaxxing/services/report-management-service/policy"
+	"reportmaxxing/services/report-management-service/ratelimit"
+	"reportmaxxing/services/report-management-service/response"
+	"reportmaxxing/services/report-management-service/services"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	os.Exit(m.Run())
+}
+
+// testClient is the service-account client the test tokens are issued to.
+// Service-account tokens skip the users-table sync, so the whole chain runs
+// without a database while roles still come from the token.
+const testClient = "test-suite"
+
+// testServer is the real router, auth chain and default policy wired to
+// fakes, with tokens signed by a dev key served as a JWKS file.
+type testServer struct {
+	router      http.Handler
+	cfg         *config.Config
+	key         *rsa.PrivateKey
+	reports     *fakeReports
+	revocations *fakeRevocations
+	denyList    *fakeDenyList
+}
+
+type testPrincipal struct {
+	subject string
+	roles   []string
+	groups  []string
+	// client defaults to testClient.
+	client string
+}
+
+var (
+	citizen      = testPrincipal{subject: "citizen-1", roles: []string{"CITIZEN"}}
+	otherCitizen = testPrincipal{subject: "citizen-2", roles: []string{"CITIZEN"}}
+	staff        = testPrincipal{subject: "staff-1", roles: []string{"DEPARTMENT_STAFF"}, groups: []string{"/departments/sanitation"}}
+	supervisor   = testPrincipal{subject: "supervisor-1", roles: []string{"SUPERVISOR"}}
+	auditor      = testPrincipal{subject: "auditor-1", roles: []string{"AUDITOR"}}
+	callCenter   = testPrincipal{subject: "call-center-account", client: "call-center"}
+)
+
+func newTestServer(t *testing.T, configure func(*config.Config)) *testServer {
+	t.Helper()
+
+	dir := t.TempDir()
+	jwksPath := filepath.Join(dir, "jwks.json")
+	key, err := middleware.LoadOrCreateDevKey(filepath.Join(dir, "dev-key.pem"), jwksPath)
+	if err != nil {
+		t.Fatalf("dev key: %v", err)
+	}
+
+	cfg := config.Default()
+	cfg.Auth.JWKSFile = jwksPath
+	cfg.Auth.ServiceClients = []string{testClient, callCenter.client}
+	cfg.Metrics.Enabled = false
+	if configure != nil {
+		configure(&cfg)
+	}
+
+	auth, err := middleware.NewAuthMiddleware(cfg.Auth, nil, middleware.NewDenyList(nil, 0))
+	if err != nil {
+		t.Fatalf("auth middleware: %v", err)
+	}
+	permissionPolicy, err := policy.Load("")
+	if err != nil {
+		t.Fatalf("policy: %v", err)
+	}
+
+	s := &testServer{
+		cfg: &cfg,
+		key: key,
+		reports: newFakeReports(
+			models.Report{
+				ID: "RPT-1", Title: "Overflowing bin", Description: "On Main St", Category: models.CategorySanitation,
+				Status: models.StatusOpen, Visibility: models.VisibilityPublic, Priority: models.PriorityNormal,
+				IntakeChannel: models.IntakeChannelApp, UserID: citizen.subject, CreatedAt: fixtureTime, UpdatedAt: fixtureTime,
+			},
+			models.Report{
+				ID: "RPT-2", Title: "Broken window", Description: "At the library", Category: models.CategoryCrime,
+				Status: models.StatusInProgress, Visibility: models.VisibilityPrivate, Priority: models.PriorityHigh,
+				IntakeChannel: models.IntakeChannelApp, UserID: otherCitizen.subject, CreatedAt: fixtureTime, UpdatedAt: fixtureTime,
+			},
+		),
+		revocations: &fakeRevocations{revocations: []models.TokenRevocation{{
+			ID: "REV-1", Subject: otherCitizen.subject, Reason: "lost phone", Source: services.RevocationSourceAdmin, RevokedBy: supervisor.subject, CreatedAt: fixtureTime,
+		}}},
+		denyList: &fakeDenyList{},
+	}
+
+	router, err := NewRouter(&cfg, Dependencies{
+		Reports: s.reports,
+		Users: newFakeUsers(models.User{
+			ID: citizen.subject, Email: "citizen@example.test", Name: "Casey Citizen", Status: models.UserStatusActive,
+			CreatedAt: fixtureTime, UpdatedAt: fixtureTime,
+		}),
+		Uploads:     fakeUploads{},
+		WorkOrders:  fakeWorkOrders{reports: s.reports},
+		Revocations: s.revocations,
+		DenyList:    s.denyList,
+		Auth:        auth,
+		Authorizer:  middleware.NewAuthorizer(permissionPolicy, s.reports.GetReportByID),
+		RateLimiter: middleware.NewRateLimiter(ratelimit.NewMemoryStore(), cfg.RateLimit.ExemptRoles),
+		Readiness:   health.NewChecker(time.Second, 0),
+	})
+	if err != nil {
+		t.Fatalf("NewRouter: %v", err)
+	}
+	s.router = router
+	return s
+}
+
+func (s *testServer) token(t *testing.T, p testPrincipal) string {
+	t.Helper()
+	client := p.client
+	if client == "" {
+		client = testClient
+	}
+	token, err := middleware.MintDevToken(s.key, middleware.DevTokenOptions{
+		Issuer:         s.cfg.Auth.ExpectedIssuer(),
+		Client:         client,
+		Subject:        p.subject,
+		Roles:          p.roles,
+		Groups:         p.groups,
+		TTL:            time.Hour,
+		ServiceAccount: true,
+	})
+	if err != nil {
+		t.Fatalf("mint token: %v", err)
+	}
+	return token
+}
+
+func (s *testServer) do(t *testing.T, method, path, token, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func decodeResponse(t *testing.T, rec *httptest.ResponseRecorder) response.Response {
+	t.Helper()
+	var body response.Response
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode %q: %v", rec.Body.String(), err)
+	}
+	return body
+}
+
+// routeTest drives one route through every outcome it has. forbidden is
+// empty for routes any signed-in caller may use, and invalid for routes
+// that take no request body. operation is the route's operationId in
+// openapi.yaml.
+type routeTest struct {
+	operation    string
+	method, path string
+	allowed      testPrincipal
+	forbidden    *testPrincipal
+	body         string
+	invalid      string
+	want         int
+}
+
+var routeTests = []routeTest{
+	{operation: "getProfile", method: "GET", path: "/api/profile", allowed: citizen, want: http.StatusOK},
+	{operation: "listReports", method: "GET", path: "/api/reports", allowed: citizen, want: http.StatusOK},
+	{operation: "getReport", method: "GET", path: "/api/reports/RPT-1", allowed: citizen, forbidden: &otherCitizen, want: http.StatusOK},
+	{
+		operation: "createReport", method: "POST", path: "/api/reports", allowed: citizen, forbidden: &auditor,
+		body:    `{"title":"Pothole","description":"Deep one","category":"SANITATION","visibility":"PUBLIC"}`,
+		invalid: `{"title":"Pothole"}`, want: http.StatusCreated,
+	},
+	{
+		operation: "createReportOnBehalf", method: "POST", path: "/api/reports/on-behalf", allowed: staff, forbidden: &citizen,
+		body:    `{"title":"Pothole","description":"Deep one","category":"SANITATION","visibility":"PUBLIC","channel":"phone","reporter":{"phone":"+15550100000"}}`,
+		invalid: `{"title":"Pothole","description":"Deep one","category":"SANITATION","visibility":"PUBLIC","channel":"fax","reporter":{"phone":"+15550100000"}}`,
+		want:    http.StatusCreated,
+	},
+	{
+		operation: "createUploadURL", method: "POST", path: "/api/reports/upload-url", allowed: citizen, forbidden: &auditor,
+		body: `{"file_name":"bin.jpg","content_type":"image/jpeg"}`, invalid: `{"file_name":"bin.jpg"}`, want: http.StatusOK,
+	},
+	{
+		operation: "updateReportStatus", method: "PUT", path: "/api/reports/RPT-1/status", allowed: staff, forbidden: &citizen,
+		body: `{"status":"IN_PROGRESS"}`, invalid: `{}`, want: http.StatusOK,
+	},
+	{
+		operation: "editReport", method: "PUT", path: "/api/reports/RPT-1", allowed: citizen, forbidden: &auditor,
+		body: `{"title":"Overflowing bins"}`, invalid: `{}`, want: http.StatusOK,
+	},
+	{operation: "withdrawReport", method: "POST", path: "/api/reports/RPT-1/withdraw", allowed: citizen, forbidden: &otherCitizen, want: http.StatusOK},
+	{
+		operation: "addReportComment", method: "POST", path: "/api/reports/RPT-1/comments", allowed: citizen, forbidden: &auditor,
+		body: `{"body":"Still there"}`, invalid: `{}`, want: http.StatusCreated,
+	},
+	{
+		operation: "addReportAttachment", method: "POST", path: "/api/reports/RPT-1/attachments", allowed: citizen, forbidden: &auditor,
+		body: `{"url":"https://images.example.test/bin.jpg","content_type":"image/jpeg"}`, invalid: `{}`, want: http.StatusCreated,
+	},
+	{
+		operation: "assignReport", method: "PUT", path: "/api/reports/RPT-1/assignee", allowed: staff, forbidden: &citizen,
+		body: `{"assignee_id":"staff-1"}`, invalid: `{"assignee_id":7}`, want: http.StatusOK,
+	},
+	{
+		operation: "changeReportPriority", method: "PUT", path: "/api/reports/RPT-1/priority", allowed: staff, forbidden: &citizen,
+		body: `{"priority":"HIGH"}`, invalid: `{"priority":"SOON"}`, want: http.StatusOK,
+	},
+	{
+		operation: "mergeReport", method: "POST", path: "/api/reports/RPT-2/merge", allowed: supervisor, forbidden: &citizen,
+		body: `{"target_report_id":"RPT-1"}`, invalid: `{}`, want: http.StatusOK,
+	},
+	{operation: "reopenReport", method: "POST", path: "/api/reports/RPT-1/reopen", allowed: staff, forbidden: &citizen, want: http.StatusOK},
+	{
+		operation: "linkWorkOrder", method: "POST", path: "/api/reports/RPT-1/work-orders", allowed: staff, forbidden: &citizen,
+		body: `{"work_order_id":"WO-1"}`, invalid: `{}`, want: http.StatusCreated,
+	},
+	{operation: "deleteReport", method: "DELETE", path: "/api/reports/RPT-1", allowed: staff, forbidden: &citizen, want: http.StatusOK},
+	{operation: "getModerationHistory", method: "GET", path: "/api/users/citizen-1/moderation", allowed: supervisor, forbidden: &staff, want: http.StatusOK},
+	{
+		operation: "suspendUser", method: "POST", path: "/api/users/citizen-1/suspend", allowed: supervisor, forbidden: &staff,
+		body: `{"reason":"spam"}`, invalid: `{"reason":"spam","until":"2001-01-01T00:00:00Z"}`, want: http.StatusOK,
+	},
+	{
+		operation: "unsuspendUser", method: "POST", path: "/api/users/citizen-1/unsuspend", allowed: supervisor, forbidden: &staff,
+		body: `{"reason":"appeal upheld"}`, invalid: `{}`, want: http.StatusOK,
+	},
+	{operation: "listRevocations", method: "GET", path: "/api/admin/revocations", allowed: supervisor, forbidden: &staff, want: http.StatusOK},
+	{
+		operation: "createRevocation", method: "POST", path: "/api/admin/revocations", allowed: supervisor, forbidden: &staff,
+		body: `{"subject":"citizen-2","reason":"lost phone"}`, invalid: `{"subject":"citizen-2","session_id":"s-1"}`, want: http.StatusCreated,
+	},
+	{operation: "deleteRevocation", method: "DELETE", path: "/api/admin/revocations/REV-1", allowed: supervisor, forbidden: &staff, want: http.StatusOK},
+}
+
+func TestRoutes(t *testing.T) {
+	s := newTestServer(t, nil)
+
+	for _, tt := range routeTests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			t.Run("unauthenticated", func(t *testing.T) {
+				rec := s.do(t, tt.method, tt.path, "", tt.body)
+				if rec.Code != http.StatusUnauthorized {
+					t.Fatalf("status = %d, want 401: %s", rec.Code, rec.Body)
+				}
+				if got := decodeResponse(t, rec).Error.Code; got != middleware.ErrCodeTokenMissing {
+					t.Errorf("error code = %q, want %q", got, middleware.ErrCodeTokenMissing)
+				}
+			})
+			if tt.forbidden != nil {
+				t.Run("forbidden", func(t *testing.T) {
+					rec := s.do(t, tt.method, tt.path, s.token(t, *tt.forbidden), tt.body)
+					if rec.Code != http.StatusForbidden {
+						t.Fatalf("status = %d, want 403: %s", rec.Code, rec.Body)
+					}
+				})
+			}
+			if tt.invalid != "" {
+				t.Run("invalid", func(t *testing.T) {
+					rec := s.do(t, tt.method, tt.path, s.token(t, tt.allowed), tt.invalid)
+					if rec.Code != http.StatusBadRequest {
+						t.Fatalf("status = %d, want 400: %s", rec.Code, rec.Body)
+					}
+				})
+			}
+			t.Run("allowed", func(t *testing.T) {
+				rec := s.do(t, tt.method, tt.path, s.token(t, tt.allowed), tt.body)
+				if rec.Code != tt.want {
+					t.Fatalf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body)
+				}
+				if !decodeResponse(t, rec).Success {
+					t.Errorf("success = false: %s", rec.Body)
+				}
+			})
+		})
+	}
+}
+
+func TestRejectedTokens(t *testing.T) {
+	s := newTestServer(t, nil)
+	otherKey, err := middleware.LoadOrCreateDevKey(filepath.Join(t.TempDir(), "other.pem"), filepath.Join(t.TempDir(), "other.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mint := func(key *rsa.PrivateKey, change func(*middleware.DevTokenOptions)) string {
+		opts := middleware.DevTokenOptions{
+			Issuer:         s.cfg.Auth.ExpectedIssuer(),
+			Client:         testClient,
+			Subject:        citizen.subject,
+			Roles:          citizen.roles,
+			TTL:            time.Hour,
+			ServiceAccount: true,
+		}
+		change(&opts)
+		token, err := middleware.MintDevToken(key, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		code  string
+	}{
+		{name: "malformed", token: "not-a-jwt", code: middleware.ErrCodeTokenMalformed},
+		{name: "unknown signing key", token: mint(otherKey, func(*middleware.DevTokenOptions) {}), code: middleware.ErrCodeTokenInvalidSignature},
+		{name: "expired", token: mint(s.key, func(o *middleware.DevTokenOptions) { o.TTL = -time.Hour }), code: middleware.ErrCodeTokenExpired},
+		{name: "wrong issuer", token: mint(s.key, func(o *middleware.DevTokenOptions) { o.Issuer = "https://elsewhere.example.test/realms/x" }), code: middleware.ErrCodeTokenInvalidIssuer},
+		{name: "service account of an unlisted client", token: mint(s.key, func(o *middleware.DevTokenOptions) { o.Client = "stranger" }), code: middleware.ErrCodeTokenInvalidClient},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := s.do(t, "GET", "/api/profile", tt.token, "")
+			if rec.Code != http.StatusUnauthorized {
+				t.Fatalf("status = %d, want 401: %s", rec.Code, rec.Body)
+			}
+			body := decodeResponse(t, rec)
+			if body.Error.Code != tt.code {
+				t.Errorf("error code = %q, want %q", body.Error.Code, tt.code)
+			}
+			if body.Error.Message != "Invalid token" {
+				t.Errorf("message = %q; the reason must stay in the log", body.Error.Message)
+			}
+		})
+	}
+}
+
+func TestListReportsFollowsReadScope(t *testing.T) {
+	s := newTestServer(t, nil)
+
+	tests := []struct {
+		principal testPrincipal
+		query     string
+		ids       []string
+	}{
+		{principal: citizen, query: "own", ids: []string{"RPT-1"}},
+		{principal: staff, query: "visible", ids: []string{"RPT-1"}},
+		{principal: testPrincipal{subject: "staff-2", roles: []string{"DEPARTMENT_STAFF"}}, query: "own", ids: []string{}},
+		{principal: supervisor, query: "visible", ids: []string{"RPT-1"}},
+		{principal: auditor, query: "all", ids: []string{"RPT-1", "RPT-2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.principal.subject, func(t *testing.T) {
+			rec := s.do(t, "GET", "/api/reports", s.token(t, tt.principal), "")
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+			}
+			if s.reports.listed != tt.query {
+				t.Errorf("listed %q reports, want %q", s.reports.listed, tt.query)
+			}
+			var body struct {
+				Data []models.Report `json:"data"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			if len(body.Data) != len(tt.ids) {
+				t.Fatalf("got %d reports, want %v", len(body.Data), tt.ids)
+			}
+		})
+	}
+}
+
+// Reports filed on behalf of a resident belong to the resident, but the
+// client that filed them can still read them back under report:read:own.
+func TestOnBehalfReportsAreReadableByTheirCreator(t *testing.T) {
+	s := newTestServer(t, nil)
+
+	rec := s.do(t, "POST", "/api/reports/on-behalf", s.token(t, callCenter),
+		`{"title":"Pothole","description":"Deep one","category":"SANITATION","visibility":"PRIVATE","channel":"PHONE","reporter":{"phone":"+15550100000"}}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create on behalf: status = %d: %s", rec.Code, rec.Body)
+	}
+	var created struct {
+		Data models.Report `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	if created.Data.UserID == callCenter.subject || created.Data.CreatedByID != callCenter.subject {
+		t.Fatalf("user_id, created_by_id = %q, %q; want the resident and %q", created.Data.UserID, created.Data.CreatedByID, callCenter.subject)
+	}
+
+	path := "/api/reports/" + created.Data.ID
+	if rec := s.do(t, "GET", path, s.token(t, callCenter), ""); rec.Code != http.StatusOK {
+		t.Errorf("creator reads the report: status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if rec := s.do(t, "GET", path, s.token(t, otherCitizen), ""); rec.Code != http.StatusForbidden {
+		t.Errorf("unrelated citizen reads the report: status = %d, want 403", rec.Code)
+	}
+
+	rec = s.do(t, "GET", "/api/reports", s.token(t, callCenter), "")
+	var listed struct {
+		Data []models.Report `json:"data"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &listed); err != nil {
+		t.Fatal(err)
+	}
+	if len(listed.Data) != 1 || listed.Data[0].ID != created.Data.ID {
+		t.Errorf("creator's report list = %+v, want only %s", listed.Data, created.Data.ID)
+	}
+}
+
+func TestMergeChecksTheTarget(t *testing.T) {
+	s := newTestServer(t, nil)
+	s.reports.reports["RPT-3"] = &models.Report{
+		ID: "RPT-3", Title: "Overflowing bin", Description: "Same bin", Category: models.CategorySanitation,
+		Status: models.StatusOpen, Visibility: models.VisibilityPublic, Priority: models.PriorityNormal,
+		IntakeChannel: models.IntakeChannelApp, UserID: otherCitizen.subject, CreatedAt: fixtureTime, UpdatedAt: fixtureTime,
+	}
+
+	// Staff in sanitation may merge sanitation reports, but not into a
+	// crime report outside their department.
+	rec := s.do(t, "POST", "/api/reports/RPT-3/merge", s.token(t, staff), `{"target_report_id":"RPT-2"}`)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("merge into another department's report: status = %d, want 403: %s", rec.Code, rec.Body)
+	}
+	if merged := s.reports.reports["RPT-3"].MergedIntoID; merged != "" {
+		t.Errorf("merged_into_id = %q after a forbidden merge", merged)
+	}
+
+	rec = s.do(t, "POST", "/api/reports/RPT-3/merge", s.token(t, staff), `{"target_report_id":"RPT-404"}`)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("merge into a missing report: status = %d, want 404: %s", rec.Code, rec.Body)
+	}
+
+	rec = s.do(t, "POST", "/api/reports/RPT-3/merge", s.token(t, staff), `{"target_report_id":"RPT-1"}`)
+	if rec.Code != http.StatusOK {
+		t.Errorf("merge within the department: status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestMissingReportIsNotFound(t *testing.T) {
+	s := newTestServer(t, nil)
+	rec := s.do(t, "GET", "/api/reports/RPT-404", s.token(t, supervisor), "")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestRevocationChangesRefreshTheDenyList(t *testing.T) {
+	s := newTestServer(t, nil)
+	token := s.token(t, supervisor)
+
+	s.do(t, "POST", "/api/admin/revocations", token, `{"session_id":"s-1"}`)
+	s.do(t, "DELETE", "/api/admin/revocations/REV-1", token, "")
+	if s.denyList.refreshes != 2 {
+		t.Errorf("deny list refreshed %d times, want 2", s.denyList.refreshes)
+	}
+
+	rec := s.do(t, "DELETE", "/api/admin/revocations/REV-404", token, "")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404: %s", rec.Code, rec.Body)
+	}
+	if s.denyList.refreshes != 2 {
+		t.Errorf("a failed delete refreshed the deny list")
+	}
+}
+
+func TestWriteRateLimit(t *testing.T) {
+	s := newTestServer(t, func(cfg *config.Config) {
+		cfg.RateLimit.Write = ratelimit.Limit{Requests: 2, Period: time.Hour}
+	})
+	token := s.token(t, citizen)
+
+	for i := 0; i < 2; i++ {
+		if rec := s.do(t, "POST", "/api/reports/RPT-1/comments", token, `{"body":"again"}`); rec.Code != http.StatusCreated {
+			t.Fatalf("request %d: status = %d: %s", i+1, rec.Code, rec.Body)
+		}
+	}
+	rec := s.do(t, "POST", "/api/reports/RPT-1/comments", token, `{"body":"again"}`)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429: %s", rec.Code, rec.Body)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("429 without Retry-After")
+	}
+	// Reads are limited separately.
+	if rec := s.do(t, "GET", "/api/reports/RPT-1", token, ""); rec.Code != http.StatusOK {
+		t.Errorf("read after write limit: status = %d", rec.Code)
+	}
+}
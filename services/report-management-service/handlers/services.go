@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"context"
+
+	"reportmaxxing/services/report-management-service/models"
+	"reportmaxxing/services/report-management-service/services"
+)
+
+// The interfaces below are the parts of the services package the routes
+// call, so handlers can be exercised against fakes.
+
+type ReportService interface {
+	GetAllReports() ([]models.Report, error)
+	GetVisibleReports(departments []string) ([]models.Report, error)
+	GetReportByID(id string) (*models.Report, error)
+	GetReportsByUserID(userID string) ([]models.Report, error)
+	GetReportStats(ctx context.Context, userID string) (*services.ReportStats, error)
+	CreateReport(ctx context.Context, actor services.Actor, req models.CreateReportRequest) (*models.Report, error)
+	CreateReportOnBehalf(ctx context.Context, actor services.Actor, reporterID string, channel models.IntakeChannel, req models.CreateReportRequest) (*models.Report, error)
+	UpdateReportStatus(ctx context.Context, actor services.Actor, reportID string, newStatus models.ReportStatus) (*models.Report, error)
+	EditReport(ctx context.Context, actor services.Actor, reportID string, req models.UpdateReportRequest) (*models.Report, error)
+	WithdrawReport(ctx context.Context, actor services.Actor, reportID string) (*models.Report, error)
+	AddComment(ctx context.Context, actor services.Actor, reportID, body string) (*models.Report, error)
+	AddAttachment(ctx context.Context, actor services.Actor, reportID string, req models.AddAttachmentRequest) (*models.Report, error)
+	AssignReport(ctx context.Context, actor services.Actor, reportID, assigneeID string) (*models.Report, error)
+	ChangePriority(ctx context.Context, actor services.Actor, reportID string, priority models.ReportPriority) (*models.Report, error)
+	MergeReport(ctx context.Context, actor services.Actor, reportID, targetID string) (*models.Report, error)
+	ReopenReport(ctx context.Context, actor services.Actor, reportID string) (*models.Report, error)
+	DeleteReport(ctx context.Context, actor services.Actor, reportID string) error
+}
+
+type UserService interface {
+	GetUserByID(ctx context.Context, userID string) (*models.User, error)
+	FindOrCreateReporter(ctx context.Context, req models.ReporterRequest) (*models.User, error)
+	GetModerationHistory(ctx context.Context, userID string) ([]models.UserModerationAction, error)
+	SuspendUser(ctx context.Context, actor services.Actor, userID string, req models.SuspendUserRequest) (*models.User, error)
+	UnsuspendUser(ctx context.Context, actor services.Actor, userID string, req models.UnsuspendUserRequest) (*models.User, error)
+}
+
+type UploadService interface {
+	GenerateUploadURL(ctx context.Context, userID, fileName, contentType string) (string, string, string, error)
+}
+
+type WorkOrderService interface {
+	LinkWorkOrder(ctx context.Context, reportID, workOrderID string) (*models.WorkOrderLink, error)
+}
+
+type RevocationService interface {
+	ListRevocations(ctx context.Context) ([]models.TokenRevocation, error)
+	Revoke(ctx context.Context, actor services.Actor, req models.RevokeTokensRequest) (*models.TokenRevocation, error)
+	DeleteRevocation(ctx context.Context, id string) error
+}
+
+// DenyList is refreshed after an admin changes revocations, so this instance
+// applies them at once instead of on its next poll.
+type DenyList interface {
+	Refresh(ctx context.Context) error
+}
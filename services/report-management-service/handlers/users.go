@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"reportmaxxing/services/report-management-service/middleware"
+	"reportmaxxing/services/report-management-service/models"
+	"reportmaxxing/services/report-management-service/response"
+)
+
+type userHandler struct {
+	users UserService
+	auth  *middleware.AuthMiddleware
+}
+
+func (h *userHandler) moderationHistory(c *gin.Context) {
+	user, err := h.users.GetUserByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		response.NotFound(c, "User not found")
+		return
+	}
+	history, err := h.users.GetModerationHistory(c.Request.Context(), user.ID)
+	if err != nil {
+		response.InternalError(c, "Failed to fetch moderation history")
+		return
+	}
+	response.Success(c, gin.H{"user": user, "history": history})
+}
+
+func (h *userHandler) suspend(c *gin.Context) {
+	var req models.SuspendUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	user, err := h.users.SuspendUser(c.Request.Context(), actorFromContext(c), c.Param("id"), req)
+	if err != nil {
+		respondModerationError(c, err)
+		return
+	}
+	h.auth.ApplySuspension(user)
+	response.SuccessWithMessage(c, "User suspended", user)
+}
+
+func (h *userHandler) unsuspend(c *gin.Context) {
+	var req models.UnsuspendUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	user, err := h.users.UnsuspendUser(c.Request.Context(), actorFromContext(c), c.Param("id"), req)
+	if err != nil {
+		respondModerationError(c, err)
+		return
+	}
+	h.auth.ApplySuspension(user)
+	response.SuccessWithMessage(c, "User reinstated", user)
+}
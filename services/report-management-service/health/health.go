@@ -0,0 +1,138 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Check probes one dependency. It should return promptly once ctx is done.
+type Check func(ctx context.Context) error
+
+type Component struct {
+	Status    string `json:"status"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status     string               `json:"status"`
+	Components map[string]Component `json:"components"`
+	CheckedAt  time.Time            `json:"checked_at"`
+}
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker runs the readiness checks. Results are cached for cacheTTL so
+// frequent probes from several sources don't load the dependencies, and
+// concurrent probes share one run.
+type Checker struct {
+	timeout  time.Duration
+	cacheTTL time.Duration
+	checks   []namedCheck
+
+	mu   sync.Mutex
+	last *Report
+}
+
+func NewChecker(timeout, cacheTTL time.Duration) *Checker {
+	return &Checker{timeout: timeout, cacheTTL: cacheTTL}
+}
+
+// Register adds a dependency to the readiness checks. Call it before serving.
+func (c *Checker) Register(name string, check Check) {
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+	sort.Slice(c.checks, func(i, j int) bool { return c.checks[i].name < c.checks[j].name })
+}
+
+// Run returns the latest report, checking every dependency in parallel if
+// the cached one has expired. Each check gets its own timeout and is not
+// tied to the caller, so a dropped probe can't poison the cache.
+func (c *Checker) Run() Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.last != nil && time.Since(c.last.CheckedAt) < c.cacheTTL {
+		return *c.last
+	}
+
+	report := Report{
+		Status:     StatusUp,
+		Components: make(map[string]Component, len(c.checks)),
+		CheckedAt:  time.Now(),
+	}
+	var (
+		wg      sync.WaitGroup
+		results sync.Mutex
+	)
+	for _, nc := range c.checks {
+		wg.Add(1)
+		go func(nc namedCheck) {
+			defer wg.Done()
+			component := c.probe(nc.check)
+
+			results.Lock()
+			defer results.Unlock()
+			report.Components[nc.name] = component
+			if component.Status != StatusUp {
+				report.Status = StatusDown
+			}
+		}(nc)
+	}
+	wg.Wait()
+
+	c.last = &report
+	return report
+}
+
+func (c *Checker) probe(check Check) Component {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	// Don't wait past the timeout for a check that ignores ctx.
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- check(ctx) }()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	component := Component{Status: StatusUp, LatencyMS: time.Since(start).Milliseconds()}
+	if err != nil {
+		component.Status = StatusDown
+		component.Error = err.Error()
+	}
+	return component
+}
+
+// Livez reports that the process is up and serving. It checks no
+// dependencies: restarting the service wouldn't fix an outage elsewhere.
+func Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": StatusUp})
+}
+
+// Readyz returns the dependency breakdown, with 503 when any is down so load
+// balancers stop routing to this instance.
+func (c *Checker) Readyz(ctx *gin.Context) {
+	report := c.Run()
+	code := http.StatusOK
+	if report.Status != StatusUp {
+		code = http.StatusServiceUnavailable
+	}
+	ctx.JSON(code, report)
+}
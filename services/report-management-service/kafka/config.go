@@ -0,0 +1,143 @@
+package kafka
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+const (
+	SASLMechanismPlain       = "PLAIN"
+	SASLMechanismScramSHA256 = "SCRAM-SHA-256"
+	SASLMechanismScramSHA512 = "SCRAM-SHA-512"
+)
+
+type Config struct {
+	Brokers []string
+
+	TLSEnabled            bool
+	TLSCAFile             string
+	TLSCertFile           string
+	TLSKeyFile            string
+	TLSInsecureSkipVerify bool
+
+	SASLMechanism string
+	SASLUsername  string
+	SASLPassword  string
+}
+
+func (c Config) Validate() error {
+	if len(c.Brokers) == 0 {
+		return errors.New("at least one kafka broker is required")
+	}
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		return errors.New("kafka TLS client certificate and key must be set together")
+	}
+	if c.SASLMechanism != "" && c.SASLUsername == "" {
+		return fmt.Errorf("kafka SASL mechanism %s requires a username", c.SASLMechanism)
+	}
+	return nil
+}
+
+// Transport returns the transport used by writers and other clients that
+// talk to the cluster through the kafka.Transport round tripper.
+func (c Config) Transport() (*kafka.Transport, error) {
+	tlsConfig, mechanism, err := c.security()
+	if err != nil {
+		return nil, err
+	}
+	return &kafka.Transport{
+		TLS:  tlsConfig,
+		SASL: mechanism,
+	}, nil
+}
+
+// Dialer returns a dialer with the same security settings, for direct
+// connections (topic administration) and kafka.Reader based consumers.
+func (c Config) Dialer() (*kafka.Dialer, error) {
+	tlsConfig, mechanism, err := c.security()
+	if err != nil {
+		return nil, err
+	}
+	return &kafka.Dialer{
+		Timeout:       10 * time.Second,
+		DualStack:     true,
+		TLS:           tlsConfig,
+		SASLMechanism: mechanism,
+	}, nil
+}
+
+func (c Config) security() (*tls.Config, sasl.Mechanism, error) {
+	if err := c.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	mechanism, err := c.saslMechanism()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tlsConfig, mechanism, nil
+}
+
+func (c Config) tlsConfig() (*tls.Config, error) {
+	if !c.TLSEnabled {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: c.TLSInsecureSkipVerify,
+	}
+
+	if c.TLSCAFile != "" {
+		caPEM, err := os.ReadFile(c.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read kafka CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("no certificates found in kafka CA file %s", c.TLSCAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if c.TLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.TLSCertFile, c.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load kafka client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+func (c Config) saslMechanism() (sasl.Mechanism, error) {
+	switch strings.ToUpper(c.SASLMechanism) {
+	case "":
+		return nil, nil
+	case SASLMechanismPlain:
+		return plain.Mechanism{Username: c.SASLUsername, Password: c.SASLPassword}, nil
+	case SASLMechanismScramSHA256:
+		return scram.Mechanism(scram.SHA256, c.SASLUsername, c.SASLPassword)
+	case SASLMechanismScramSHA512:
+		return scram.Mechanism(scram.SHA512, c.SASLUsername, c.SASLPassword)
+	default:
+		return nil, fmt.Errorf("unsupported kafka SASL mechanism %q", c.SASLMechanism)
+	}
+}
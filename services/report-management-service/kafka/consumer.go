@@ -0,0 +1,184 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"reportmaxxing/services/report-management-service/logging"
+	"reportmaxxing/services/report-management-service/tracing"
+)
+
+const (
+	WorkOrdersUpdatedTopic    = "workorders.updated"
+	WorkOrdersUpdatedDLQTopic = "workorders.updated.dlq"
+
+	// KeycloakEventsTopic is where a Keycloak event-listener SPI publishes
+	// admin and user events.
+	KeycloakEventsTopic = "keycloak.events"
+)
+
+// MessageHandler processes one message. Returning an error causes the
+// message to be retried and, once attempts are exhausted, dead-lettered.
+type MessageHandler func(ctx context.Context, msg kafka.Message) error
+
+type ConsumerOptions struct {
+	GroupID     string
+	Topic       string
+	DLQTopic    string
+	MaxAttempts int
+}
+
+type Consumer struct {
+	reader   *kafka.Reader
+	handler  MessageHandler
+	producer *Producer
+	opts     ConsumerOptions
+}
+
+func NewConsumer(cfg Config, opts ConsumerOptions, handler MessageHandler, producer *Producer) (*Consumer, error) {
+	dialer, err := cfg.Dialer()
+	if err != nil {
+		return nil, fmt.Errorf("invalid kafka config: %w", err)
+	}
+	if opts.GroupID == "" || opts.Topic == "" {
+		return nil, errors.New("consumer group ID and topic are required")
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 3
+	}
+
+	if producer != nil {
+		if err := producer.EnsureTopic(opts.Topic, false); err != nil {
+			slog.Warn("kafka: failed to ensure topic exists", "topic", opts.Topic, "error", err)
+		}
+	}
+
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:        cfg.Brokers,
+		GroupID:        opts.GroupID,
+		Topic:          opts.Topic,
+		Dialer:         dialer,
+		MinBytes:       1,
+		MaxBytes:       10e6,
+		CommitInterval: 0,
+	})
+
+	return &Consumer{
+		reader:   reader,
+		handler:  handler,
+		producer: producer,
+		opts:     opts,
+	}, nil
+}
+
+// Run fetches and handles messages until ctx is cancelled. Offsets are only
+// committed after a message has been handled or dead-lettered, so a crash
+// leads to redelivery rather than loss.
+func (c *Consumer) Run(ctx context.Context) error {
+	slog.Info("kafka: consumer started", "group", c.opts.GroupID, "topic", c.opts.Topic)
+
+	for {
+		msg, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			slog.Error("kafka: consumer fetch failed", "topic", c.opts.Topic, "error", err)
+			time.Sleep(time.Second)
+			continue
+		}
+
+		msgCtx, span := c.startSpan(ctx, msg)
+		msgCtx = withMessageLogger(msgCtx, msg)
+		if err := c.handle(msgCtx, msg); err != nil {
+			if ctx.Err() != nil {
+				span.End()
+				return nil
+			}
+			tracing.Fail(span, err)
+			c.deadLetter(msgCtx, msg, err)
+		}
+		span.End()
+
+		if err := c.reader.CommitMessages(ctx, msg); err != nil && ctx.Err() == nil {
+			logging.FromContext(msgCtx).Error("kafka: consumer commit failed", "error", err)
+		}
+	}
+}
+
+// startSpan continues the producer's trace from the message headers.
+func (c *Consumer) startSpan(ctx context.Context, msg kafka.Message) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ContextFromMessage(ctx, msg), "process "+msg.Topic,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationTypeDeliver,
+			semconv.MessagingDestinationName(msg.Topic),
+			semconv.MessagingKafkaConsumerGroup(c.opts.GroupID),
+			semconv.MessagingDestinationPartitionID(strconv.Itoa(msg.Partition)),
+			semconv.MessagingKafkaMessageOffset(int(msg.Offset)),
+		),
+	)
+}
+
+// withMessageLogger scopes log lines about msg to its offset and to the
+// request ID and trace that produced it.
+func withMessageLogger(ctx context.Context, msg kafka.Message) context.Context {
+	logger := slog.Default().With("topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset)
+	if id := tracing.CorrelationIDFromContext(ctx); id != "" {
+		logger = logger.With("request_id", id)
+	}
+	if traceID := tracing.TraceID(ctx); traceID != "" {
+		logger = logger.With("trace_id", traceID)
+	}
+	return logging.WithLogger(ctx, logger)
+}
+
+func (c *Consumer) handle(ctx context.Context, msg kafka.Message) error {
+	var err error
+	for attempt := 1; attempt <= c.opts.MaxAttempts; attempt++ {
+		if err = c.handler(ctx, msg); err == nil {
+			return nil
+		}
+
+		logging.FromContext(ctx).Warn("kafka: consumer handler failed", "attempt", attempt, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * 500 * time.Millisecond):
+		}
+	}
+	return err
+}
+
+func (c *Consumer) deadLetter(ctx context.Context, msg kafka.Message, cause error) {
+	if c.opts.DLQTopic == "" || c.producer == nil {
+		logging.FromContext(ctx).Error("kafka: consumer dropping message", "error", cause)
+		return
+	}
+
+	dlqMsg := kafka.Message{
+		Key:   msg.Key,
+		Value: msg.Value,
+		Headers: append(msg.Headers,
+			kafka.Header{Key: "dlq_error", Value: []byte(cause.Error())},
+			kafka.Header{Key: "dlq_source_topic", Value: []byte(msg.Topic)},
+		),
+	}
+	if err := c.producer.WriteMessages(ctx, c.opts.DLQTopic, dlqMsg); err != nil {
+		logging.FromContext(ctx).Error("kafka: consumer failed to dead-letter message", "dlq_topic", c.opts.DLQTopic, "error", err)
+	}
+}
+
+func (c *Consumer) Close() error {
+	return c.reader.Close()
+}
@@ -0,0 +1,89 @@
+package kafka
+
+import (
+	"context"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+
+	"reportmaxxing/services/report-management-service/tracing"
+)
+
+const (
+	TraceParentHeader   = "traceparent"
+	CorrelationIDHeader = "correlation_id"
+)
+
+// headerCarrier lets the OpenTelemetry propagator read and write message
+// headers.
+type headerCarrier struct {
+	headers *[]kafka.Header
+}
+
+func (c headerCarrier) Get(key string) string {
+	for _, h := range *c.headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func (c headerCarrier) Set(key, value string) {
+	for i, h := range *c.headers {
+		if h.Key == key {
+			(*c.headers)[i].Value = []byte(value)
+			return
+		}
+	}
+	*c.headers = append(*c.headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, len(*c.headers))
+	for i, h := range *c.headers {
+		keys[i] = h.Key
+	}
+	return keys
+}
+
+// withPropagationHeaders carries the trace context and correlation ID from
+// ctx onto an outgoing message. Headers the message already has, such as
+// those of a replayed event, are kept.
+func withPropagationHeaders(ctx context.Context, msg kafka.Message) kafka.Message {
+	var headers []kafka.Header
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier{&headers})
+	if id := tracing.CorrelationIDFromContext(ctx); id != "" {
+		headers = append(headers, kafka.Header{Key: CorrelationIDHeader, Value: []byte(id)})
+	}
+
+	for _, h := range headers {
+		if !hasHeader(msg.Headers, h.Key) {
+			msg.Headers = append(msg.Headers, h)
+		}
+	}
+	return msg
+}
+
+func hasHeader(headers []kafka.Header, key string) bool {
+	for _, h := range headers {
+		if h.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// ContextFromMessage restores the trace context and correlation ID of a
+// consumed message onto ctx, so actions taken while handling it stay
+// correlated with the request that produced it. An invalid correlation ID is
+// dropped.
+func ContextFromMessage(ctx context.Context, msg kafka.Message) context.Context {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, headerCarrier{&msg.Headers})
+	for _, h := range msg.Headers {
+		if h.Key == CorrelationIDHeader && tracing.ValidCorrelationID(string(h.Value)) {
+			ctx = tracing.WithCorrelationID(ctx, string(h.Value))
+		}
+	}
+	return ctx
+}
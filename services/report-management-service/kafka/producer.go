@@ -3,63 +3,241 @@ package kafka
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/segmentio/kafka-go"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
 
+	"reportmaxxing/services/report-management-service/logging"
+	"reportmaxxing/services/report-management-service/metrics"
 	"reportmaxxing/services/report-management-service/models"
+	"reportmaxxing/services/report-management-service/tracing"
 )
 
 const (
 	ReportsCreatedTopic       = "reports.created"
 	ReportsStatusChangedTopic = "reports.status-changed"
+	ReportsSnapshotTopic      = "reports.snapshot"
+
+	ReportsAssignedTopic        = "reports.assigned"
+	ReportsPriorityChangedTopic = "reports.priority-changed"
+	ReportsCommentAddedTopic    = "reports.comment-added"
+	ReportsAttachmentAddedTopic = "reports.attachment-added"
+	ReportsEditedTopic          = "reports.edited"
+	ReportsWithdrawnTopic       = "reports.withdrawn"
+	ReportsMergedTopic          = "reports.merged"
+	ReportsReopenedTopic        = "reports.reopened"
+	ReportsDeletedTopic         = "reports.deleted"
+
+	UsersSuspendedTopic   = "users.suspended"
+	UsersUnsuspendedTopic = "users.unsuspended"
 )
 
+// eventTopics are created at startup; each event type is published to the
+// topic of the same name.
+var eventTopics = []string{
+	ReportsCreatedTopic,
+	ReportsStatusChangedTopic,
+	ReportsAssignedTopic,
+	ReportsPriorityChangedTopic,
+	ReportsCommentAddedTopic,
+	ReportsAttachmentAddedTopic,
+	ReportsEditedTopic,
+	ReportsWithdrawnTopic,
+	ReportsMergedTopic,
+	ReportsReopenedTopic,
+	ReportsDeletedTopic,
+	UsersSuspendedTopic,
+	UsersUnsuspendedTopic,
+}
+
 type Producer struct {
+	brokers   []string
+	transport *kafka.Transport
+	dialer    *kafka.Dialer
+
+	mu      sync.Mutex
 	writers map[string]*kafka.Writer
 }
 
-func NewProducer(brokerURL string) *Producer {
+func NewProducer(cfg Config) (*Producer, error) {
+	transport, err := cfg.Transport()
+	if err != nil {
+		return nil, fmt.Errorf("invalid kafka config: %w", err)
+	}
+	dialer, err := cfg.Dialer()
+	if err != nil {
+		return nil, fmt.Errorf("invalid kafka config: %w", err)
+	}
+
 	p := &Producer{
-		writers: make(map[string]*kafka.Writer),
+		brokers:   cfg.Brokers,
+		transport: transport,
+		dialer:    dialer,
+		writers:   make(map[string]*kafka.Writer),
+	}
+
+	if err := ensureTopicsExist(dialer, cfg.Brokers); err != nil {
+		slog.Warn("kafka: failed to ensure topics exist", "error", err)
+	}
+
+	for _, topic := range eventTopics {
+		p.writer(topic)
+	}
+
+	return p, nil
+}
+
+func (p *Producer) writer(topic string) *kafka.Writer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if w, ok := p.writers[topic]; ok {
+		return w
+	}
+
+	w := &kafka.Writer{
+		Addr:         kafka.TCP(p.brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.LeastBytes{},
+		BatchTimeout: 10 * time.Millisecond,
+		Transport:    p.transport,
+	}
+	p.writers[topic] = w
+	return w
+}
+
+// EnsureTopic creates topic if it does not exist yet. Compacted topics keep
+// only the latest message per key, which is what snapshot consumers expect.
+func (p *Producer) EnsureTopic(topic string, compacted bool) error {
+	conn, err := dialController(p.dialer, p.brokers)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	topicConfig := kafka.TopicConfig{
+		Topic:             topic,
+		NumPartitions:     3,
+		ReplicationFactor: 1,
+	}
+	if compacted {
+		topicConfig.ConfigEntries = []kafka.ConfigEntry{
+			{ConfigName: "cleanup.policy", ConfigValue: "compact"},
+		}
+	}
+
+	if err := conn.CreateTopics(topicConfig); err != nil && !isTopicExistsError(err) {
+		return err
+	}
+	return nil
+}
+
+// WriteMessages publishes pre-encoded messages to an arbitrary topic. It is
+// used by the snapshot and replay commands and the outbox relay, which are
+// not tied to the typed event topics.
+func (p *Producer) WriteMessages(ctx context.Context, topic string, msgs ...kafka.Message) error {
+	if err := p.write(ctx, topic, msgs...); err != nil {
+		logging.FromContext(ctx).Error("kafka: publish failed", "topic", topic, "messages", len(msgs), "error", err)
+		return err
+	}
+	return nil
+}
+
+// write sends msgs to topic inside a producer span, whose context is
+// propagated in the message headers, and records the outcome in the publish
+// metrics.
+func (p *Producer) write(ctx context.Context, topic string, msgs ...kafka.Message) error {
+	ctx, span := tracing.Tracer().Start(ctx, "publish "+topic,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationTypePublish,
+			semconv.MessagingDestinationName(topic),
+			semconv.MessagingBatchMessageCount(len(msgs)),
+		),
+	)
+	defer span.End()
+
+	for i := range msgs {
+		msgs[i] = withPropagationHeaders(ctx, msgs[i])
 	}
 
-	if err := ensureTopicsExist(brokerURL); err != nil {
-		log.Printf("Warning: Failed to ensure topics exist: %v", err)
+	start := time.Now()
+	err := p.writer(topic).WriteMessages(ctx, msgs...)
+	metrics.ObserveKafkaPublish(topic, start, err)
+	if err != nil {
+		tracing.Fail(span, err)
 	}
+	return err
+}
+
+// dialController connects to the first reachable broker and then to the
+// cluster controller, which is the only broker that accepts CreateTopics.
+func dialController(dialer *kafka.Dialer, brokers []string) (*kafka.Conn, error) {
+	var lastErr error
+	for _, broker := range brokers {
+		conn, err := dialer.Dial("tcp", broker)
+		if err != nil {
+			lastErr = err
+			continue
+		}
 
-	for _, topic := range []string{ReportsCreatedTopic, ReportsStatusChangedTopic} {
-		p.writers[topic] = &kafka.Writer{
-			Addr:         kafka.TCP(brokerURL),
-			Topic:        topic,
-			Balancer:     &kafka.LeastBytes{},
-			BatchTimeout: 10 * time.Millisecond,
+		controller, err := conn.Controller()
+		conn.Close()
+		if err != nil {
+			lastErr = err
+			continue
 		}
+
+		return dialer.Dial("tcp", net.JoinHostPort(controller.Host, strconv.Itoa(controller.Port)))
 	}
+	return nil, fmt.Errorf("no kafka broker reachable: %w", lastErr)
+}
 
-	return p
+// Ping fetches cluster metadata from the first reachable broker.
+func (p *Producer) Ping(ctx context.Context) error {
+	var lastErr error
+	for _, broker := range p.brokers {
+		conn, err := p.dialer.DialContext(ctx, "tcp", broker)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if deadline, ok := ctx.Deadline(); ok {
+			conn.SetDeadline(deadline)
+		}
+		_, err = conn.Brokers()
+		conn.Close()
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+	}
+	return fmt.Errorf("no kafka broker reachable: %w", lastErr)
 }
 
-func ensureTopicsExist(brokerURL string) error {
-	conn, err := kafka.Dial("tcp", brokerURL)
+func ensureTopicsExist(dialer *kafka.Dialer, brokers []string) error {
+	conn, err := dialController(dialer, brokers)
 	if err != nil {
 		return err
 	}
 	defer conn.Close()
 
-	topicConfigs := []kafka.TopicConfig{
-		{
-			Topic:             ReportsCreatedTopic,
+	topicConfigs := make([]kafka.TopicConfig, 0, len(eventTopics))
+	for _, topic := range eventTopics {
+		topicConfigs = append(topicConfigs, kafka.TopicConfig{
+			Topic:             topic,
 			NumPartitions:     3,
 			ReplicationFactor: 1,
-		},
-		{
-			Topic:             ReportsStatusChangedTopic,
-			NumPartitions:     3,
-			ReplicationFactor: 1,
-		},
+		})
 	}
 
 	err = conn.CreateTopics(topicConfigs...)
@@ -67,10 +245,18 @@ func ensureTopicsExist(brokerURL string) error {
 		return err
 	}
 
-	log.Printf("Kafka topics ensured: %s, %s", ReportsCreatedTopic, ReportsStatusChangedTopic)
+	slog.Info("kafka: topics ensured", "topics", eventTopics)
 	return nil
 }
 
+// publishContext bounds a publish to 5s while keeping the caller's trace and
+// correlation values. It ignores the caller's cancellation: by the time we
+// publish, the change is committed, and a client disconnecting should not
+// drop the event.
+func publishContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+}
+
 func isTopicExistsError(err error) bool {
 	if err == nil {
 		return false
@@ -82,13 +268,38 @@ func isTopicExistsError(err error) bool {
 		strings.Contains(errStr, "Topic with this name already exists")
 }
 
-func (p *Producer) PublishReportCreated(event *models.ReportCreatedEvent) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+func (p *Producer) PublishReportCreated(ctx context.Context, event *models.ReportCreatedEvent) error {
+	ctx, cancel := publishContext(ctx)
+	defer cancel()
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		logging.FromContext(ctx).Error("kafka: encoding ReportCreatedEvent failed", "error", err)
+		return err
+	}
+
+	msg := kafka.Message{
+		Key:   []byte(event.ReportID),
+		Value: data,
+		Time:  time.Now(),
+	}
+
+	if err := p.write(ctx, ReportsCreatedTopic, msg); err != nil {
+		logging.FromContext(ctx).Error("kafka: publish failed", "topic", ReportsCreatedTopic, "error", err)
+		return err
+	}
+
+	logging.FromContext(ctx).Info("kafka: published", "topic", ReportsCreatedTopic, "report_id", event.ReportID)
+	return nil
+}
+
+func (p *Producer) PublishReportStatusChanged(ctx context.Context, event *models.ReportStatusChangedEvent) error {
+	ctx, cancel := publishContext(ctx)
 	defer cancel()
 
 	data, err := json.Marshal(event)
 	if err != nil {
-		log.Printf("Failed to marshal ReportCreatedEvent: %v", err)
+		logging.FromContext(ctx).Error("kafka: encoding ReportStatusChangedEvent failed", "error", err)
 		return err
 	}
 
@@ -98,22 +309,24 @@ func (p *Producer) PublishReportCreated(event *models.ReportCreatedEvent) error
 		Time:  time.Now(),
 	}
 
-	if err := p.writers[ReportsCreatedTopic].WriteMessages(ctx, msg); err != nil {
-		log.Printf("Failed to publish to %s: %v", ReportsCreatedTopic, err)
+	if err := p.write(ctx, ReportsStatusChangedTopic, msg); err != nil {
+		logging.FromContext(ctx).Error("kafka: publish failed", "topic", ReportsStatusChangedTopic, "error", err)
 		return err
 	}
 
-	log.Printf("Published ReportCreatedEvent for report: %s", event.ReportID)
+	logging.FromContext(ctx).Info("kafka: published", "topic", ReportsStatusChangedTopic, "report_id", event.ReportID)
 	return nil
 }
 
-func (p *Producer) PublishReportStatusChanged(event *models.ReportStatusChangedEvent) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// PublishReportEvent publishes a generic report mutation event to the topic
+// named after its event type.
+func (p *Producer) PublishReportEvent(ctx context.Context, event *models.ReportEvent) error {
+	ctx, cancel := publishContext(ctx)
 	defer cancel()
 
 	data, err := json.Marshal(event)
 	if err != nil {
-		log.Printf("Failed to marshal ReportStatusChangedEvent: %v", err)
+		logging.FromContext(ctx).Error("kafka: encoding ReportEvent failed", "error", err)
 		return err
 	}
 
@@ -123,20 +336,50 @@ func (p *Producer) PublishReportStatusChanged(event *models.ReportStatusChangedE
 		Time:  time.Now(),
 	}
 
-	if err := p.writers[ReportsStatusChangedTopic].WriteMessages(ctx, msg); err != nil {
-		log.Printf("Failed to publish to %s: %v", ReportsStatusChangedTopic, err)
+	if err := p.write(ctx, event.EventType, msg); err != nil {
+		logging.FromContext(ctx).Error("kafka: publish failed", "topic", event.EventType, "error", err)
 		return err
 	}
 
-	log.Printf("Published ReportStatusChangedEvent for report: %s", event.ReportID)
+	logging.FromContext(ctx).Info("kafka: published", "topic", event.EventType, "report_id", event.ReportID)
 	return nil
 }
 
 func (p *Producer) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
 	for _, writer := range p.writers {
 		if err := writer.Close(); err != nil {
-			log.Printf("Error closing Kafka writer: %v", err)
+			slog.Error("kafka: closing writer failed", "error", err)
 		}
 	}
 	return nil
 }
+
+// PublishUserModerationEvent publishes a suspension or reinstatement to the
+// topic named after its event type, keyed by user.
+func (p *Producer) PublishUserModerationEvent(ctx context.Context, event *models.UserModerationEvent) error {
+	ctx, cancel := publishContext(ctx)
+	defer cancel()
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		logging.FromContext(ctx).Error("kafka: encoding UserModerationEvent failed", "error", err)
+		return err
+	}
+
+	msg := kafka.Message{
+		Key:   []byte(event.UserID),
+		Value: data,
+		Time:  time.Now(),
+	}
+
+	if err := p.write(ctx, event.EventType, msg); err != nil {
+		logging.FromContext(ctx).Error("kafka: publish failed", "topic", event.EventType, "error", err)
+		return err
+	}
+
+	logging.FromContext(ctx).Info("kafka: published", "topic", event.EventType, "target_user_id", event.UserID)
+	return nil
+}
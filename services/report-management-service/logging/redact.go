@@ -0,0 +1,54 @@
+package logging
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// DefaultRedactFields hold citizen-written content or contact details.
+var DefaultRedactFields = []string{"title", "description", "comment", "email", "phone", "file_name"}
+
+var emailAddress = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@([A-Za-z0-9.\-]+\.[A-Za-z]{2,})`)
+
+// Redactor blanks the values of the configured attribute keys and masks the
+// local part of email addresses anywhere else, including the message and
+// error strings, which often quote user input.
+type Redactor struct {
+	fields map[string]bool
+}
+
+func NewRedactor(fields []string) *Redactor {
+	r := &Redactor{fields: make(map[string]bool, len(fields))}
+	for _, field := range fields {
+		r.fields[strings.ToLower(strings.TrimSpace(field))] = true
+	}
+	return r
+}
+
+// ReplaceAttr has the signature of slog.HandlerOptions.ReplaceAttr.
+func (r *Redactor) ReplaceAttr(_ []string, a slog.Attr) slog.Attr {
+	if r.fields[strings.ToLower(a.Key)] {
+		return slog.String(a.Key, redacted)
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		if s := a.Value.String(); strings.Contains(s, "@") {
+			return slog.String(a.Key, MaskEmails(s))
+		}
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			return slog.String(a.Key, MaskEmails(err.Error()))
+		}
+	}
+	return a
+}
+
+// MaskEmails replaces the local part of every email address in s, keeping the
+// domain, which is usually enough to tell accounts apart while debugging.
+func MaskEmails(s string) string {
+	return emailAddress.ReplaceAllString(s, redacted+"@$1")
+}
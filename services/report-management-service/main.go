@@ -1,213 +1,366 @@
 package main
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
-	"github.com/gin-gonic/gin"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
 
+	"reportmaxxing/services/report-management-service/config"
+	"reportmaxxing/services/report-management-service/handlers"
+	"reportmaxxing/services/report-management-service/health"
 	"reportmaxxing/services/report-management-service/kafka"
+	"reportmaxxing/services/report-management-service/logging"
+	"reportmaxxing/services/report-management-service/metrics"
 	"reportmaxxing/services/report-management-service/middleware"
+	"reportmaxxing/services/report-management-service/migrations"
 	"reportmaxxing/services/report-management-service/models"
-	"reportmaxxing/services/report-management-service/response"
+	"reportmaxxing/services/report-management-service/policy"
+	"reportmaxxing/services/report-management-service/ratelimit"
+	"reportmaxxing/services/report-management-service/repository"
 	"reportmaxxing/services/report-management-service/services"
+	"reportmaxxing/services/report-management-service/tracing"
 )
 
 func main() {
-	dsn := "host=localhost user=gorm password=gorm dbname=gorm port=9920 sslmode=disable TimeZone=Asia/Jakarta"
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	cfg, args, err := config.Load(os.Args[1:])
+	if err != nil {
+		fatal("config", err)
+	}
+	if err := logging.Setup(os.Stderr, cfg.Logging); err != nil {
+		fatal("logging", err)
+	}
+
+	// These commands don't need Kafka (and dev-token, config and openapi not
+	// even Postgres), so they run before connecting.
+	if len(args) > 0 {
+		switch args[0] {
+		case "dev-token":
+			if err := runDevToken(args[1:], cfg.Auth); err != nil {
+				fatal("dev-token", err)
+			}
+			return
+		case "config":
+			fmt.Print(cfg.String())
+			return
+		case "openapi":
+			if err := runOpenAPI(); err != nil {
+				fatal("openapi", err)
+			}
+			return
+		case "migrate":
+			if err := runMigrate(args[1:], cfg.Database); err != nil {
+				fatal("migrate", err)
+			}
+			return
+		}
+	}
+
+	slog.Info("configuration loaded", "config", cfg)
 
+	flushTraces, err := tracing.Setup(context.Background(), cfg.Tracing)
 	if err != nil {
-		panic("failed to connect database")
+		fatal("failed to initialize tracing", err)
 	}
 
-	if err := db.AutoMigrate(&models.User{}, &models.Report{}, &models.ReportUpdate{}); err != nil {
-		log.Fatalf("failed to migrate: %v", err)
+	db, err := openDatabase(cfg.Database)
+	if err != nil {
+		fatal("failed to connect database", err)
+	}
+
+	// The schema is owned by migrations/sql. Serving against a different
+	// version would fail in confusing ways, so refuse to start instead.
+	migrator, err := migrations.New(db)
+	if err != nil {
+		fatal("failed to load migrations", err)
+	}
+	if cfg.Database.MigrateOnStart {
+		applied, err := migrator.Up(context.Background())
+		if err != nil {
+			fatal("failed to migrate", err)
+		}
+		for _, migration := range applied {
+			slog.Info("migrate: applied", "version", migration.Version, "name", migration.Name)
+		}
+	}
+	if err := migrator.Check(context.Background()); err != nil {
+		fatal("schema check failed", err)
 	}
 
-	brokerURL := getEnv("KAFKA_BROKER_URL", "localhost:9092")
-	kafkaProducer := kafka.NewProducer(brokerURL)
-	defer kafkaProducer.Close()
+	kafkaProducer, err := kafka.NewProducer(cfg.Kafka)
+	if err != nil {
+		fatal("failed to initialize kafka producer", err)
+	}
 
-	keycloakURL := getEnv("KEYCLOAK_URL", "http://localhost:8080")
-	keycloakRealm := getEnv("KEYCLOAK_REALM", "reportmaxxing")
+	store := repository.NewGormStore(db)
 
-	authMiddleware, err := middleware.NewAuthMiddleware(keycloakURL, keycloakRealm, db)
+	if len(args) > 0 {
+		err := runCommand(args, store, kafkaProducer)
+		kafkaProducer.Close()
+		flushTraces(context.Background())
+		if err != nil {
+			fatal(args[0], err)
+		}
+		return
+	}
+
+	// Background workers run on ctx, which is only cancelled once the HTTP
+	// server has drained, so requests in flight can still use them.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var workers sync.WaitGroup
+	var consumers []*kafka.Consumer
+
+	denyList := middleware.NewDenyList(store, cfg.Auth.MaxTokenLifetime+cfg.Auth.Leeway)
+	if err := denyList.Refresh(ctx); err != nil {
+		fatal("failed to load token revocations", err)
+	}
+	background(&workers, func() { denyList.Run(ctx, 30*time.Second) })
+
+	authMiddleware, err := middleware.NewAuthMiddleware(cfg.Auth, store, denyList)
 	if err != nil {
-		log.Fatalf("failed to initialize auth middleware: %v", err)
+		fatal("failed to initialize auth middleware", err)
 	}
 
-	reportService := services.NewReportService(db, kafkaProducer)
-	s3Service, err := services.NewS3ServiceFromEnv()
+	reportService := services.NewReportService(store, kafkaProducer)
+	s3Service, err := services.NewS3Service(cfg.S3)
 	if err != nil {
-		log.Fatalf("failed to initialize s3 service: %v", err)
+		fatal("failed to initialize s3 service", err)
+	}
+	workOrderService := services.NewWorkOrderService(store, reportService)
+	revocationService := services.NewRevocationService(store)
+	userService := services.NewUserService(store, kafkaProducer)
+
+	outboxRelay := services.NewOutboxRelay(store, kafkaProducer)
+	background(&workers, func() { outboxRelay.Run(ctx, 15*time.Second) })
+
+	if cfg.Consumers.WorkOrdersEnabled {
+		workOrderConsumer, err := kafka.NewConsumer(cfg.Kafka, kafka.ConsumerOptions{
+			GroupID:  cfg.Consumers.WorkOrdersGroup,
+			Topic:    kafka.WorkOrdersUpdatedTopic,
+			DLQTopic: kafka.WorkOrdersUpdatedDLQTopic,
+		}, workOrderService.HandleMessage, kafkaProducer)
+		if err != nil {
+			fatal("failed to initialize work order consumer", err)
+		}
+		consumers = append(consumers, workOrderConsumer)
+
+		background(&workers, func() { workOrderConsumer.Run(ctx) })
 	}
 
-	r := gin.Default()
+	if cfg.Consumers.KeycloakEventsEnabled {
+		keycloakConsumer, err := kafka.NewConsumer(cfg.Kafka, kafka.ConsumerOptions{
+			GroupID: cfg.Consumers.KeycloakEventsGroup,
+			Topic:   cfg.Consumers.KeycloakEventsTopic,
+		}, revocationService.HandleKeycloakEvent, kafkaProducer)
+		if err != nil {
+			fatal("failed to initialize keycloak event consumer", err)
+		}
+		consumers = append(consumers, keycloakConsumer)
+
+		background(&workers, func() { keycloakConsumer.Run(ctx) })
+	}
 
-	r.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok"})
-	})
+	permissionPolicy, err := policy.Load(cfg.PolicyFile)
+	if err != nil {
+		fatal("failed to load permission policy", err)
+	}
+	authorizer := middleware.NewAuthorizer(permissionPolicy, reportService.GetReportByID)
 
-	api := r.Group("/api")
-	api.Use(authMiddleware.Authenticate())
-	{
-		// Profile endpoint - returns current user info with report stats
-		api.GET("/profile", func(c *gin.Context) {
-			userID := c.GetString("userID")
-			email := c.GetString("email")
-			name := c.GetString("name")
-			roles := c.MustGet("roles").([]string)
-
-			// Get report counts for user
-			var openCount, resolvedCount int64
-			db.Model(&models.Report{}).Where("user_id = ? AND status IN ?", userID, []string{"OPEN", "IN_PROGRESS"}).Count(&openCount)
-			db.Model(&models.Report{}).Where("user_id = ? AND status = ?", userID, "RESOLVED").Count(&resolvedCount)
-
-			// Determine primary role for display
-			primaryRole := "CITIZEN"
-			if middleware.HasRole(roles, "DEPARTMENT_STAFF") {
-				primaryRole = "DEPARTMENT_STAFF"
-			}
+	var rateLimitStore ratelimit.Store = ratelimit.NewMemoryStore()
+	if cfg.RateLimit.Store == config.RateLimitStorePostgres {
+		postgresStore := ratelimit.NewPostgresStore(store)
+		background(&workers, func() { pruneRateLimits(ctx, postgresStore) })
+		rateLimitStore = postgresStore
+	}
+	rateLimiter := middleware.NewRateLimiter(rateLimitStore, cfg.RateLimit.ExemptRoles)
 
-			response.Success(c, gin.H{
-				"id":               userID,
-				"email":            email,
-				"name":             name,
-				"role":             primaryRole,
-				"roles":            roles,
-				"open_reports":     openCount,
-				"resolved_reports": resolvedCount,
-			})
-		})
-
-		api.GET("/reports", func(c *gin.Context) {
-			userID := c.GetString("userID")
-			roles := c.MustGet("roles").([]string)
-
-			var reports []models.Report
-			var err error
-
-			if middleware.HasRole(roles, "DEPARTMENT_STAFF") {
-				reports, err = reportService.GetAllReports()
-			} else {
-				reports, err = reportService.GetReportsByUserID(userID)
-			}
+	sqlDB, err := db.DB()
+	if err != nil {
+		fatal("failed to get database pool", err)
+	}
+	checker := health.NewChecker(cfg.Health.Timeout, cfg.Health.CacheTTL)
+	checker.Register("postgres", sqlDB.PingContext)
+	checker.Register("kafka", kafkaProducer.Ping)
+	checker.Register("jwks", authMiddleware.CheckJWKS)
+	checker.Register("s3", s3Service.Ping)
+
+	if cfg.Metrics.Enabled {
+		metrics.RegisterDB(sqlDB, cfg.Database.Name)
+		metrics.Registry.MustRegister(services.NewReportMetrics(db, slaTargets(cfg.Metrics)))
+	}
 
-			if err != nil {
-				response.InternalError(c, "Failed to fetch reports")
-				return
-			}
-			response.Success(c, reports)
-		})
-
-		api.GET("/reports/:id", func(c *gin.Context) {
-			id := c.Param("id")
-			userID := c.GetString("userID")
-			roles := c.MustGet("roles").([]string)
-
-			report, err := reportService.GetReportByID(id)
-			if err != nil {
-				response.NotFound(c, "Report not found")
-				return
-			}
+	r, err := handlers.NewRouter(cfg, handlers.Dependencies{
+		Reports:     reportService,
+		Users:       userService,
+		Uploads:     s3Service,
+		WorkOrders:  workOrderService,
+		Revocations: revocationService,
+		DenyList:    denyList,
+		Auth:        authMiddleware,
+		Authorizer:  authorizer,
+		RateLimiter: rateLimiter,
+		Readiness:   checker,
+	})
+	if err != nil {
+		fatal("failed to build router", err)
+	}
 
-			if !middleware.HasRole(roles, "DEPARTMENT_STAFF") && report.UserID != userID {
-				response.Forbidden(c, "Access denied")
-				return
-			}
+	srv := &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.Server.Port),
+		Handler:           r,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+	}
 
-			response.Success(c, report)
-		})
+	signalCtx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("HTTP server listening", "addr", srv.Addr)
+		serverErr <- srv.ListenAndServe()
+	}()
+
+	exitCode := 0
+	select {
+	case err := <-serverErr:
+		slog.Error("HTTP server stopped", "error", err)
+		exitCode = 1
+	case <-signalCtx.Done():
+		slog.Info("shutdown: signal received, draining requests")
+	}
+	// A second signal kills the process without waiting.
+	stopSignals()
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancelShutdown()
+	shutdown(shutdownCtx, srv, cancel, &workers, consumers, kafkaProducer, flushTraces, db)
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
+}
 
-		api.POST("/reports", authMiddleware.RequireRole("CITIZEN"), func(c *gin.Context) {
-			var req models.CreateReportRequest
-			if err := c.ShouldBindJSON(&req); err != nil {
-				log.Printf("create-report: invalid payload: %v", err)
-				response.BadRequest(c, err.Error())
-				return
-			}
+// shutdown stops the service in dependency order: stop accepting and drain
+// HTTP requests, stop background workers, close consumers, flush the
+// producer and pending spans, then close the database pool. Everything
+// shares ctx's deadline.
+func shutdown(ctx context.Context, srv *http.Server, stopWorkers context.CancelFunc, workers *sync.WaitGroup, consumers []*kafka.Consumer, producer *kafka.Producer, flushTraces func(context.Context) error, db *gorm.DB) {
+	if err := srv.Shutdown(ctx); err != nil {
+		slog.Error("shutdown: HTTP drain incomplete", "error", err)
+	}
 
-			userID := c.GetString("userID")
-			log.Printf("create-report: user_id=%s title=%q category=%s visibility=%s has_image=%t",
-				userID,
-				req.Title,
-				req.Category,
-				req.Visibility,
-				req.ImageURL != "",
-			)
-			report, err := reportService.CreateReport(userID, req)
-			if err != nil {
-				log.Printf("create-report: failed user_id=%s err=%v", userID, err)
-				response.InternalError(c, "Failed to create report")
-				return
-			}
-			log.Printf("create-report: success user_id=%s report_id=%s", userID, report.ID)
-			response.CreatedWithMessage(c, "Report created successfully", report)
-		})
-
-		api.POST("/reports/upload-url", authMiddleware.RequireRole("CITIZEN"), func(c *gin.Context) {
-			var req struct {
-				FileName    string `json:"file_name" binding:"required"`
-				ContentType string `json:"content_type" binding:"required"`
-			}
-			if err := c.ShouldBindJSON(&req); err != nil {
-				log.Printf("upload-url: invalid payload: %v", err)
-				response.BadRequest(c, err.Error())
-				return
-			}
+	stopWorkers()
+	done := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		slog.Warn("shutdown: background workers did not stop in time")
+	}
 
-			userID := c.GetString("userID")
-			log.Printf("upload-url: user_id=%s file_name=%q content_type=%s", userID, req.FileName, req.ContentType)
-			uploadURL, imageURL, objectKey, err := s3Service.GenerateUploadURL(
-				c.Request.Context(),
-				userID,
-				req.FileName,
-				req.ContentType,
-			)
-			if err != nil {
-				log.Printf("upload-url: failed user_id=%s err=%v", userID, err)
-				response.InternalError(c, "Failed to create upload URL")
-				return
-			}
-			log.Printf("upload-url: success user_id=%s object_key=%s", userID, objectKey)
+	for _, consumer := range consumers {
+		if err := consumer.Close(); err != nil {
+			slog.Error("shutdown: closing consumer", "error", err)
+		}
+	}
+	if err := producer.Close(); err != nil {
+		slog.Error("shutdown: flushing producer", "error", err)
+	}
+	if err := flushTraces(ctx); err != nil {
+		slog.Error("shutdown: flushing traces", "error", err)
+	}
 
-			response.Success(c, gin.H{
-				"upload_url": uploadURL,
-				"image_url":  imageURL,
-				"object_key": objectKey,
-			})
-		})
+	if sqlDB, err := db.DB(); err == nil {
+		if err := sqlDB.Close(); err != nil {
+			slog.Error("shutdown: closing database", "error", err)
+		}
+	}
+	slog.Info("shutdown: complete")
+}
 
-		api.PUT("/reports/:id/status", authMiddleware.RequireRole("DEPARTMENT_STAFF"), func(c *gin.Context) {
-			id := c.Param("id")
+// fatal logs err and exits, for failures the service cannot start without.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
 
-			var req struct {
-				Status string `json:"status" binding:"required"`
-			}
-			if err := c.ShouldBindJSON(&req); err != nil {
-				response.BadRequest(c, err.Error())
-				return
-			}
+// background runs fn on its own goroutine, tracked by wg so shutdown can wait
+// for it to return.
+func background(wg *sync.WaitGroup, fn func()) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		fn()
+	}()
+}
 
-			report, err := reportService.UpdateReportStatus(id, models.ReportStatus(req.Status))
-			if err != nil {
-				response.NotFound(c, "Report not found")
-				return
-			}
-			response.Success(c, report)
-		})
+// slaTargets maps each priority to its resolution target, leaving out the
+// ones set to 0.
+func slaTargets(cfg config.MetricsConfig) map[models.ReportPriority]time.Duration {
+	targets := map[models.ReportPriority]time.Duration{}
+	for priority, target := range map[models.ReportPriority]time.Duration{
+		models.PriorityUrgent: cfg.SLAUrgent,
+		models.PriorityHigh:   cfg.SLAHigh,
+		models.PriorityNormal: cfg.SLANormal,
+		models.PriorityLow:    cfg.SLALow,
+	} {
+		if target > 0 {
+			targets[priority] = target
+		}
 	}
+	return targets
+}
 
-	port := getEnv("PORT", "8081")
-	r.Run(":" + port)
+func openDatabase(dbConfig config.DatabaseConfig) (*gorm.DB, error) {
+	// Parameterized so report content never reaches the slow/failed query
+	// logs.
+	db, err := gorm.Open(postgres.Open(dbConfig.DSN()), &gorm.Config{
+		Logger: gormlogger.NewSlogLogger(slog.Default(), gormlogger.Config{
+			SlowThreshold:             200 * time.Millisecond,
+			LogLevel:                  gormlogger.Warn,
+			ParameterizedQueries:      true,
+			IgnoreRecordNotFoundError: true,
+		}),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Use(tracing.GormPlugin{}); err != nil {
+		return nil, err
+	}
+	return db, nil
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// pruneRateLimits drops shared buckets that have been idle for a day, the
+// longest period a limit can have.
+func pruneRateLimits(ctx context.Context, store *ratelimit.PostgresStore) {
+	ticker := time.NewTicker(10 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := store.Prune(ctx, 24*time.Hour); err != nil && ctx.Err() == nil {
+				slog.Error("rate-limit: prune failed", "error", err)
+			}
+		}
 	}
-	return defaultValue
 }
@@ -0,0 +1,109 @@
+package metrics
+
+import (
+	"database/sql"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry holds every metric the service exposes on /metrics.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests by route, method and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency by route and method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	httpInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "HTTP requests currently being served.",
+	})
+
+	kafkaPublished = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kafka_publish_total",
+		Help: "Kafka publish attempts by topic and result (success or failure).",
+	}, []string{"topic", "result"})
+
+	kafkaPublishDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "kafka_publish_duration_seconds",
+		Help:    "Kafka publish latency by topic.",
+		Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"topic"})
+
+	presignedURLs = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "s3_presigned_urls_total",
+		Help: "Presigned upload URLs by result (success or failure).",
+	}, []string{"result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		httpInFlight,
+		kafkaPublished,
+		kafkaPublishDuration,
+		presignedURLs,
+	)
+}
+
+// Handler serves the registry in the Prometheus text format.
+func Handler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry}))
+}
+
+// RegisterDB exposes the connection pool stats as go_sql_* metrics.
+func RegisterDB(db *sql.DB, name string) {
+	Registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// Middleware records request rate, errors and duration. Requests are
+// labelled by route template (/api/reports/:id), not path, to keep label
+// cardinality bounded; unmatched paths share one label.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		httpInFlight.Inc()
+		defer httpInFlight.Dec()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		httpRequests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		httpDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// ObserveKafkaPublish records one publish to topic that took since start.
+func ObserveKafkaPublish(topic string, start time.Time, err error) {
+	kafkaPublished.WithLabelValues(topic, result(err)).Inc()
+	kafkaPublishDuration.WithLabelValues(topic).Observe(time.Since(start).Seconds())
+}
+
+func ObservePresign(err error) {
+	presignedURLs.WithLabelValues(result(err)).Inc()
+}
+
+func result(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
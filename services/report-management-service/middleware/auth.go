@@ -1,128 +1,441 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"os"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/MicahParks/keyfunc/v3"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
-	"gorm.io/gorm"
 
 	"reportmaxxing/services/report-management-service/models"
+	"reportmaxxing/services/report-management-service/repository"
+	"reportmaxxing/services/report-management-service/response"
 )
 
+const (
+	ErrCodeTokenMissing          = "TOKEN_MISSING"
+	ErrCodeTokenMalformed        = "TOKEN_MALFORMED"
+	ErrCodeTokenExpired          = "TOKEN_EXPIRED"
+	ErrCodeTokenNotYetValid      = "TOKEN_NOT_YET_VALID"
+	ErrCodeTokenInvalidSignature = "TOKEN_INVALID_SIGNATURE"
+	ErrCodeTokenInvalidIssuer    = "TOKEN_INVALID_ISSUER"
+	ErrCodeTokenInvalidAudience  = "TOKEN_INVALID_AUDIENCE"
+	ErrCodeTokenInvalidClient    = "TOKEN_INVALID_CLIENT"
+	ErrCodeTokenInvalidType      = "TOKEN_INVALID_TYPE"
+	ErrCodeTokenInvalid          = "TOKEN_INVALID"
+	ErrCodeTokenRevoked          = "TOKEN_REVOKED"
+
+	ErrCodeUserSuspended   = "USER_SUSPENDED"
+	ErrCodeEmailUnverified = "EMAIL_UNVERIFIED"
+)
+
+// errEmailUnverified is returned by syncUser when the token's email belongs
+// to another users row that may only be linked once the email is verified.
+var errEmailUnverified = errors.New("email belongs to another user and is not verified")
+
+// AuthConfig describes which Keycloak tokens the API accepts.
+type AuthConfig struct {
+	KeycloakURL string
+	Realm       string
+
+	// Issuer defaults to <KeycloakURL>/realms/<Realm>. Override it when
+	// clients reach Keycloak through a different hostname than the API.
+	Issuer string
+	// Audiences, when set, requires the token's aud to contain one of them.
+	Audiences []string
+	// AllowedClients, when set, requires the token's azp (the client it was
+	// issued to) to be one of them.
+	AllowedClients []string
+	// ServiceClients lists confidential clients whose client-credentials
+	// tokens are accepted. Their callers are service accounts: they are not
+	// synced as users and are authorised by the client's policy entry.
+	ServiceClients []string
+	// Leeway tolerates clock skew when checking exp, nbf and iat.
+	Leeway time.Duration
+	// JWKSFile, when set, loads verification keys from a local JWK set
+	// instead of fetching them from Keycloak, for offline development and
+	// tests. See the dev-token command.
+	JWKSFile string
+
+	// RoleSources lists where roles are read from: "realm", "client" and/or
+	// "groups". Defaults to realm roles only.
+	RoleSources []string
+	// RoleClientID selects the resource_access entry used by the "client"
+	// source. Defaults to the first allowed client.
+	RoleClientID string
+	// RoleGroupPrefix marks the groups the "groups" source maps to roles,
+	// e.g. "/roles/DEPARTMENT_STAFF". Defaults to "/roles/".
+	RoleGroupPrefix string
+	// DepartmentGroupPrefix marks groups that scope staff to a department,
+	// e.g. "/departments/sanitation". Defaults to "/departments/".
+	DepartmentGroupPrefix string
+
+	// MaxTokenLifetime is the longest an access token can live (the realm's
+	// Access Token Lifespan, or a client's override). Session revocations
+	// are dropped once it has passed. Defaults to one hour.
+	MaxTokenLifetime time.Duration
+
+	// UserCacheTTL is how long a user's claims are trusted to match the
+	// users table before being written again. Zero syncs on every request.
+	UserCacheTTL time.Duration
+
+	// IntrospectionClientID and IntrospectionClientSecret identify the
+	// confidential client used to introspect tokens on routes guarded by
+	// RequireActiveToken. Introspection is off when the ID is empty.
+	IntrospectionClientID     string
+	IntrospectionClientSecret string
+	// IntrospectionCacheTTL bounds how long a result is reused.
+	IntrospectionCacheTTL time.Duration
+}
+
+func (cfg AuthConfig) ExpectedIssuer() string {
+	if cfg.Issuer != "" {
+		return cfg.Issuer
+	}
+	return cfg.realmURL()
+}
+
 type KeycloakClaims struct {
 	jwt.RegisteredClaims
+	Type              string `json:"typ"`
+	AuthorizedParty   string `json:"azp"`
 	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
 	PreferredUsername string `json:"preferred_username"`
 	Name              string `json:"name"`
-	RealmAccess       struct {
+	Locale            string `json:"locale"`
+	SID               string `json:"sid"`
+	SessionState      string `json:"session_state"`
+	PhoneNumber       string `json:"phone_number"`
+	// ClientID is only set on client-credentials tokens, by the service
+	// account's "Client ID" mapper. Older Keycloak versions name the claim
+	// clientId.
+	ClientID       string `json:"client_id,omitempty"`
+	LegacyClientID string `json:"clientId,omitempty"`
+	RealmAccess    struct {
 		Roles []string `json:"roles"`
 	} `json:"realm_access"`
+	ResourceAccess map[string]struct {
+		Roles []string `json:"roles"`
+	} `json:"resource_access,omitempty"`
+	Groups []string `json:"groups,omitempty"`
 }
 
-type AuthMiddleware struct {
-	jwks keyfunc.Keyfunc
-	db   *gorm.DB
+// SessionID returns the Keycloak session the token belongs to. Newer
+// Keycloak versions send sid, older ones session_state.
+func (c *KeycloakClaims) SessionID() string {
+	if c.SID != "" {
+		return c.SID
+	}
+	return c.SessionState
 }
 
-func NewAuthMiddleware(keycloakURL, realm string, db *gorm.DB) (*AuthMiddleware, error) {
-	jwksURL := fmt.Sprintf("%s/realms/%s/protocol/openid-connect/certs", keycloakURL, realm)
+type AuthMiddleware struct {
+	jwks         keyfunc.Keyfunc
+	store        repository.Store
+	cfg          AuthConfig
+	parser       *jwt.Parser
+	users        *userCache
+	denyList     *DenyList
+	introspector *introspector
+	jwksTracker  *jwksTracker
+}
 
-	k, err := keyfunc.NewDefault([]string{jwksURL})
+func NewAuthMiddleware(cfg AuthConfig, store repository.Store, denyList *DenyList) (*AuthMiddleware, error) {
+	k, tracker, err := newKeyfunc(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create keyfunc: %w", err)
 	}
 
-	return &AuthMiddleware{jwks: k, db: db}, nil
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512", "PS256", "PS384", "PS512"}),
+		jwt.WithIssuer(cfg.ExpectedIssuer()),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(cfg.Leeway),
+	}
+	if len(cfg.Audiences) > 0 {
+		opts = append(opts, jwt.WithAudience(cfg.Audiences...))
+	}
+
+	return &AuthMiddleware{
+		jwks:         k,
+		store:        store,
+		cfg:          cfg,
+		parser:       jwt.NewParser(opts...),
+		users:        newUserCache(cfg.UserCacheTTL),
+		denyList:     denyList,
+		introspector: newIntrospector(cfg),
+		jwksTracker:  tracker,
+	}, nil
+}
+
+// newKeyfunc loads signing keys from KEYCLOAK_JWKS_FILE, or from the realm's
+// JWKS endpoint with a tracker for their freshness.
+func newKeyfunc(cfg AuthConfig) (keyfunc.Keyfunc, *jwksTracker, error) {
+	if cfg.JWKSFile != "" {
+		raw, err := os.ReadFile(cfg.JWKSFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read JWKS file: %w", err)
+		}
+		k, err := keyfunc.NewJWKSetJSON(raw)
+		return k, nil, err
+	}
+
+	tracker := newJWKSTracker()
+	jwksURL := fmt.Sprintf("%s/protocol/openid-connect/certs", cfg.realmURL())
+	k, err := keyfunc.NewDefaultOverrideCtx(context.Background(), []string{jwksURL}, keyfunc.Override{
+		Client:                  &http.Client{Transport: tracker},
+		RefreshInterval:         jwksRefreshInterval,
+		RefreshErrorHandlerFunc: tracker.refreshFailed,
+	})
+	return k, tracker, err
+}
+
+func (cfg AuthConfig) realmURL() string {
+	return fmt.Sprintf("%s/realms/%s", strings.TrimRight(cfg.KeycloakURL, "/"), cfg.Realm)
+}
+
+// validateToken parses and verifies a bearer token, returning an error code
+// from the ErrCodeToken* set when it is rejected.
+func (a *AuthMiddleware) validateToken(tokenString string) (*KeycloakClaims, string, error) {
+	claims := &KeycloakClaims{}
+	token, err := a.parser.ParseWithClaims(tokenString, claims, a.jwks.Keyfunc)
+	if err != nil {
+		return nil, tokenErrorCode(err), err
+	}
+	if !token.Valid {
+		return nil, ErrCodeTokenInvalid, errors.New("token is invalid")
+	}
+
+	// Keycloak marks access tokens "Bearer"; ID and refresh tokens signed by
+	// the same realm key must not be accepted as API credentials.
+	if claims.Type != "" && !strings.EqualFold(claims.Type, "Bearer") {
+		return nil, ErrCodeTokenInvalidType, fmt.Errorf("token type %q is not an access token", claims.Type)
+	}
+
+	if claims.IsServiceAccount() {
+		if !slices.Contains(a.cfg.ServiceClients, claims.AuthorizedParty) {
+			return nil, ErrCodeTokenInvalidClient, fmt.Errorf("client %q is not allowed to use service-account tokens", claims.AuthorizedParty)
+		}
+		return claims, "", nil
+	}
+
+	if len(a.cfg.AllowedClients) > 0 && !slices.Contains(a.cfg.AllowedClients, claims.AuthorizedParty) {
+		return nil, ErrCodeTokenInvalidClient, fmt.Errorf("token was issued to client %q", claims.AuthorizedParty)
+	}
+
+	return claims, "", nil
+}
+
+// IsServiceAccount reports whether the token was obtained through the client
+// credentials grant rather than by a user logging in: it carries a client ID
+// claim naming the client it was issued to. Usernames are not trusted for
+// this, since users can pick one that looks like a service account's.
+func (c *KeycloakClaims) IsServiceAccount() bool {
+	clientID := c.ClientID
+	if clientID == "" {
+		clientID = c.LegacyClientID
+	}
+	return clientID != "" && clientID == c.AuthorizedParty
+}
+
+func tokenErrorCode(err error) string {
+	switch {
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return ErrCodeTokenExpired
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return ErrCodeTokenNotYetValid
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid), errors.Is(err, jwt.ErrTokenUnverifiable):
+		return ErrCodeTokenInvalidSignature
+	case errors.Is(err, jwt.ErrTokenInvalidIssuer):
+		return ErrCodeTokenInvalidIssuer
+	case errors.Is(err, jwt.ErrTokenInvalidAudience):
+		return ErrCodeTokenInvalidAudience
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return ErrCodeTokenMalformed
+	default:
+		return ErrCodeTokenInvalid
+	}
 }
 
 func (a *AuthMiddleware) Authenticate() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if !strings.HasPrefix(authHeader, "Bearer ") {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing authorization header"})
+			response.UnauthorizedWithCode(c, ErrCodeTokenMissing, "Missing authorization header")
+			c.Abort()
 			return
 		}
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
-		token, err := jwt.ParseWithClaims(tokenString, &KeycloakClaims{}, a.jwks.Keyfunc)
-		if err != nil || !token.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
+		claims, code, err := a.validateToken(tokenString)
+		if err != nil {
+			// The reason stays in the log: it can name the expected issuer,
+			// audience or client, which callers have no need to see.
+			Logger(c).Info("auth: token rejected", "code", code, "error", err)
+			response.UnauthorizedWithCode(c, code, "Invalid token")
+			c.Abort()
 			return
 		}
 
-		claims := token.Claims.(*KeycloakClaims)
-
-		user, err := a.syncUser(claims)
-		if err != nil {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to sync user"})
+		if a.denyList != nil && a.denyList.Revoked(claims) {
+			response.UnauthorizedWithCode(c, ErrCodeTokenRevoked, "Token has been revoked")
+			c.Abort()
 			return
 		}
+		if claims.ExpiresAt != nil {
+			c.Set("tokenExpiresAt", claims.ExpiresAt.Time)
+		}
 
-		c.Set("userID", user.ID)
-		c.Set("email", claims.Email)
-		c.Set("name", claims.Name)
-		c.Set("roles", claims.RealmAccess.Roles)
+		if claims.IsServiceAccount() {
+			// Service accounts act for a partner system, not a person, so
+			// they have no users row. The subject is the stable ID Keycloak
+			// gives the client's service-account user.
+			c.Set("userID", claims.Subject)
+			c.Set("clientID", claims.AuthorizedParty)
+			c.Set("name", claims.AuthorizedParty)
+		} else {
+			user, err := a.syncUser(c.Request.Context(), claims, a.cfg.resolveRoles(claims))
+			if errors.Is(err, errEmailUnverified) {
+				response.ForbiddenWithCode(c, ErrCodeEmailUnverified, "Verify your email address to sign in")
+				c.Abort()
+				return
+			}
+			if err != nil {
+				Logger(c).Error("auth: syncing user failed", "subject", claims.Subject, "error", err)
+				response.InternalError(c, "Failed to sync user")
+				c.Abort()
+				return
+			}
+
+			if a.denyList != nil {
+				if suspension, ok := a.denyList.Suspension(user.ID); ok {
+					respondSuspended(c, suspension)
+					c.Abort()
+					return
+				}
+			}
+
+			c.Set("userID", user.ID)
+			c.Set("email", claims.Email)
+			c.Set("name", claims.Name)
+		}
+		c.Set("roles", a.cfg.resolveRoles(claims))
+		c.Set("departments", a.cfg.resolveDepartments(claims))
+		WithLogAttrs(c, "user_id", c.GetString("userID"), "client_id", claims.AuthorizedParty)
 		c.Next()
 	}
 }
 
-func (a *AuthMiddleware) syncUser(claims *KeycloakClaims) (*models.User, error) {
-	var user models.User
+// syncUser records the token's user and roles, creating or refreshing the
+// users row. Users whose claims haven't changed since the last sync within
+// the cache TTL are not written again.
+func (a *AuthMiddleware) syncUser(ctx context.Context, claims *KeycloakClaims, roles []string) (*models.User, error) {
+	user := models.User{
+		ID:                claims.Subject,
+		Email:             strings.ToLower(strings.TrimSpace(claims.Email)),
+		Name:              claims.Name,
+		PreferredUsername: claims.PreferredUsername,
+		Locale:            claims.Locale,
+		Phone:             models.NormalizePhone(claims.PhoneNumber),
+		Roles:             roles,
+	}
 
-	// Try to find existing user
-	result := a.db.Where("id = ?", claims.Subject).First(&user)
+	claimsHash := userClaimsHash(claims, roles)
+	if cached, ok := a.users.get(user.ID, claimsHash); ok {
+		return cached, nil
+	}
 
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
-			// Create new user
-			user = models.User{
-				ID:    claims.Subject,
-				Email: claims.Email,
-				Name:  claims.Name,
-			}
-			if err := a.db.Create(&user).Error; err != nil {
-				return nil, err
-			}
-		} else {
-			return nil, result.Error
-		}
-	} else {
-		// Update existing user if email or name changed
-		if user.Email != claims.Email || user.Name != claims.Name {
-			a.db.Model(&user).Updates(map[string]interface{}{
-				"email": claims.Email,
-				"name":  claims.Name,
-			})
+	err := a.store.Transaction(ctx, func(tx repository.Store) error {
+		if err := claimUserByEmail(ctx, tx, claims, user.Email); err != nil {
+			return err
 		}
+		// Suspensions are checked against the deny-list, not this row, so
+		// a cached row can't hide one.
+		return tx.Users().Sync(ctx, &user)
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	a.users.store(claimsHash, user)
 	return &user, nil
 }
 
-func (a *AuthMiddleware) RequireRole(roles ...string) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		userRoles, exists := c.Get("roles")
-		if !exists {
-			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "No roles found"})
-			return
-		}
+// claimUserByEmail links a users row with the same email but another ID to
+// the token's subject. Such rows are residents staff filed reports for before
+// they had an account, or accounts recreated in Keycloak. Their reports and
+// moderation history move to the subject, and the row takes the subject's ID,
+// or is dropped if the subject already has a row (keeping a suspension).
+// Only verified emails are linked, so nobody gets someone else's reports by
+// registering their address.
+func claimUserByEmail(ctx context.Context, tx repository.Store, claims *KeycloakClaims, email string) error {
+	if email == "" {
+		return nil
+	}
 
-		for _, userRole := range userRoles.([]string) {
-			for _, requiredRole := range roles {
-				if userRole == requiredRole {
-					c.Next()
-					return
-				}
-			}
+	existing, err := tx.Users().LockByEmail(ctx, email, claims.Subject)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !claims.EmailVerified {
+		return errEmailUnverified
+	}
+
+	if err := tx.Users().Reassign(ctx, existing.ID, claims.Subject); err != nil {
+		return err
+	}
+
+	_, err = tx.Users().Get(ctx, claims.Subject)
+	if errors.Is(err, repository.ErrNotFound) {
+		return tx.Users().ChangeID(ctx, existing, claims.Subject)
+	}
+	if err != nil {
+		return err
+	}
+	if existing.Status == models.UserStatusSuspended {
+		err := tx.Users().SaveModeration(ctx, &models.User{
+			ID:               claims.Subject,
+			Status:           existing.Status,
+			SuspendedUntil:   existing.SuspendedUntil,
+			SuspensionReason: existing.SuspensionReason,
+		})
+		if err != nil {
+			return err
 		}
+	}
+	return tx.Users().Delete(ctx, existing)
+}
 
-		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+// ApplySuspension enforces a suspension change on this instance at once.
+// Other instances pick it up on their next deny-list refresh.
+func (a *AuthMiddleware) ApplySuspension(user *models.User) {
+	if a.denyList != nil {
+		a.denyList.SetSuspension(user)
+	}
+}
+
+func respondSuspended(c *gin.Context, user *models.User) {
+	message := "Account suspended"
+	if user.SuspendedUntil != nil {
+		message += " until " + user.SuspendedUntil.UTC().Format(time.RFC3339)
+	}
+	if user.SuspensionReason != "" {
+		message += ": " + user.SuspensionReason
 	}
+	response.ForbiddenWithCode(c, ErrCodeUserSuspended, message)
 }
 
 func HasRole(roles []string, role string) bool {
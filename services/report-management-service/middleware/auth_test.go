@@ -0,0 +1,156 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"reportmaxxing/services/report-management-service/models"
+)
+
+func TestIsServiceAccount(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims KeycloakClaims
+		want   bool
+	}{
+		{name: "client_id matches azp", claims: KeycloakClaims{AuthorizedParty: "call-center", ClientID: "call-center"}, want: true},
+		{name: "legacy clientId matches azp", claims: KeycloakClaims{AuthorizedParty: "call-center", LegacyClientID: "call-center"}, want: true},
+		{name: "client_id for another client", claims: KeycloakClaims{AuthorizedParty: "report-frontend", ClientID: "call-center"}},
+		{name: "user with a service-account username", claims: KeycloakClaims{AuthorizedParty: "report-frontend", PreferredUsername: "service-account-call-center"}},
+		{name: "user", claims: KeycloakClaims{AuthorizedParty: "report-frontend", PreferredUsername: "jane"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.claims.IsServiceAccount(); got != tt.want {
+				t.Errorf("IsServiceAccount = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthenticateSyncsUsers(t *testing.T) {
+	auth := newTestAuth(t, newFakeStore())
+	claims := auth.userClaims("kc-jane", "Jane@Example.test")
+
+	var userID string
+	rec := auth.do(t, claims, func(c *gin.Context) {
+		userID = c.GetString("userID")
+		noContent(c)
+	})
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d: %s", rec.Code, http.StatusNoContent, rec.Body)
+	}
+	if userID != "kc-jane" {
+		t.Errorf("userID = %q, want the token subject", userID)
+	}
+	user, found := auth.store.user("kc-jane")
+	if !found {
+		t.Fatal("user row not created")
+	}
+	if user.Email != "jane@example.test" || user.Name != "Jane Doe" || !slices.Equal(user.Roles, []string{"CITIZEN"}) {
+		t.Errorf("user = %+v, want the token's normalised email, name and roles", user)
+	}
+
+	// Unchanged claims are served from the cache; changed ones are written.
+	auth.do(t, claims, noContent)
+	if auth.store.syncs != 1 {
+		t.Errorf("syncs after a repeat request = %d, want 1", auth.store.syncs)
+	}
+	claims.RealmAccess.Roles = []string{"CITIZEN", "DEPARTMENT_STAFF"}
+	auth.do(t, claims, noContent)
+	if auth.store.syncs != 2 {
+		t.Errorf("syncs after a role change = %d, want 2", auth.store.syncs)
+	}
+	if user, _ := auth.store.user("kc-jane"); len(user.Roles) != 2 {
+		t.Errorf("roles = %v, want the new roles stored", user.Roles)
+	}
+}
+
+func TestAuthenticateClaimsUsersByEmail(t *testing.T) {
+	until := time.Now().Add(time.Hour)
+	reporter := models.User{ID: "reporter-1", Email: "jane@example.test", Status: models.UserStatusActive}
+	suspended := models.User{
+		ID: "reporter-1", Email: "jane@example.test", Status: models.UserStatusSuspended,
+		SuspendedUntil: &until, SuspensionReason: "spam",
+	}
+	subject := models.User{ID: "kc-jane", Email: "old@example.test", Status: models.UserStatusActive}
+
+	t.Run("reporter without an account", func(t *testing.T) {
+		auth := newTestAuth(t, newFakeStore(reporter))
+		if rec := auth.do(t, auth.userClaims("kc-jane", "jane@example.test"), noContent); rec.Code != http.StatusNoContent {
+			t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+		}
+		if _, found := auth.store.user("reporter-1"); found {
+			t.Error("reporter row kept its old ID")
+		}
+		if user, found := auth.store.user("kc-jane"); !found || user.Name != "Jane Doe" {
+			t.Errorf("subject row = %+v, %v; want the reporter row under the subject", user, found)
+		}
+		if !slices.Equal(auth.store.reassigned, []string{"reporter-1->kc-jane"}) {
+			t.Errorf("reassigned = %v, want the reporter's records moved", auth.store.reassigned)
+		}
+	})
+
+	t.Run("subject already has a row", func(t *testing.T) {
+		auth := newTestAuth(t, newFakeStore(suspended, subject))
+		if rec := auth.do(t, auth.userClaims("kc-jane", "jane@example.test"), noContent); rec.Code != http.StatusNoContent {
+			t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+		}
+		if _, found := auth.store.user("reporter-1"); found {
+			t.Error("reporter row not dropped")
+		}
+		user, _ := auth.store.user("kc-jane")
+		if user.Status != models.UserStatusSuspended || user.SuspensionReason != "spam" {
+			t.Errorf("subject row = %+v, want the reporter's suspension kept", user)
+		}
+	})
+
+	t.Run("unverified email", func(t *testing.T) {
+		auth := newTestAuth(t, newFakeStore(reporter))
+		claims := auth.userClaims("kc-mallory", "jane@example.test")
+		claims.EmailVerified = false
+		rec := auth.do(t, claims, noContent)
+		if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), ErrCodeEmailUnverified) {
+			t.Fatalf("status = %d, body = %s; want 403 %s", rec.Code, rec.Body, ErrCodeEmailUnverified)
+		}
+		if _, found := auth.store.user("reporter-1"); !found {
+			t.Error("reporter row claimed by an unverified email")
+		}
+		if _, found := auth.store.user("kc-mallory"); found || len(auth.store.reassigned) > 0 {
+			t.Error("unverified sign-in left changes behind")
+		}
+	})
+}
+
+func TestAuthenticateRejectsSuspendedUsers(t *testing.T) {
+	until := time.Now().Add(time.Hour)
+	auth := newTestAuth(t, newFakeStore(models.User{
+		ID: "kc-jane", Email: "jane@example.test", Status: models.UserStatusSuspended,
+		SuspendedUntil: &until, SuspensionReason: "spam",
+	}))
+	if err := auth.denyList.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	claims := auth.userClaims("kc-jane", "jane@example.test")
+
+	rec := auth.do(t, claims, noContent)
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), ErrCodeUserSuspended) {
+		t.Fatalf("status = %d, body = %s; want 403 %s", rec.Code, rec.Body, ErrCodeUserSuspended)
+	}
+	if !strings.Contains(rec.Body.String(), "spam") {
+		t.Errorf("body = %s, want the suspension reason", rec.Body)
+	}
+
+	// The user row is cached now; lifting the suspension still applies at
+	// once.
+	auth.ApplySuspension(&models.User{ID: "kc-jane", Status: models.UserStatusActive})
+	if rec := auth.do(t, claims, noContent); rec.Code != http.StatusNoContent {
+		t.Errorf("after unsuspending: status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+}
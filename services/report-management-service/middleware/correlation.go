@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	"reportmaxxing/services/report-management-service/tracing"
+)
+
+const CorrelationIDHeader = tracing.CorrelationIDHeader
+
+// CorrelationID reuses the caller's correlation (or request) ID when it is
+// valid (see tracing.ValidCorrelationID) and generates one otherwise. The ID
+// is stored as "correlationID", attached to the request context for
+// downstream Kafka publishes, and echoed back in the response.
+func CorrelationID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(CorrelationIDHeader)
+		if id == "" {
+			id = c.GetHeader("X-Request-ID")
+		}
+		if !tracing.ValidCorrelationID(id) {
+			id = uuid.New().String()
+		}
+
+		c.Set("correlationID", id)
+		c.Request = c.Request.WithContext(tracing.WithCorrelationID(c.Request.Context(), id))
+		c.Header(CorrelationIDHeader, id)
+		c.Next()
+	}
+}
+
+// TraceContext echoes the server span started by otelgin: the trace ID is
+// stored as "traceID" for log lines and returned in X-Trace-ID, and the
+// span's traceparent is returned so callers can link their own spans to it.
+func TraceContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		if traceID := tracing.TraceID(ctx); traceID != "" {
+			c.Set("traceID", traceID)
+			c.Header(tracing.TraceIDHeader, traceID)
+			otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(c.Writer.Header()))
+		}
+		c.Next()
+	}
+}
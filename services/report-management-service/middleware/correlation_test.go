@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func TestCorrelationIDOnlyReusesValidIDs(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(CorrelationID())
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		reused bool
+	}{
+		{name: "correlation id", header: CorrelationIDHeader, value: "web-7f3a:42", reused: true},
+		{name: "request id", header: "X-Request-ID", value: "req_01.abc", reused: true},
+		{name: "longest allowed", header: CorrelationIDHeader, value: strings.Repeat("a", 100), reused: true},
+		{name: "too long", header: CorrelationIDHeader, value: strings.Repeat("a", 101)},
+		{name: "spaces", header: CorrelationIDHeader, value: "id with spaces"},
+		{name: "log injection", header: CorrelationIDHeader, value: "id\" level=ERROR"},
+		{name: "non-ascii", header: CorrelationIDHeader, value: "idé"},
+		{name: "missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			got := rec.Header().Get(CorrelationIDHeader)
+			if tt.reused {
+				if got != tt.value {
+					t.Errorf("correlation ID = %q, want the caller's %q", got, tt.value)
+				}
+				return
+			}
+			if _, err := uuid.Parse(got); err != nil {
+				t.Errorf("correlation ID = %q, want a generated UUID", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,277 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+
+	"reportmaxxing/services/report-management-service/models"
+	"reportmaxxing/services/report-management-service/ratelimit"
+	"reportmaxxing/services/report-management-service/repository"
+)
+
+// fakeStore keeps the users and token revocations the middleware reads in
+// memory. The repositories it doesn't need are left nil, so a call to one
+// fails the test with a panic. Transaction restores the state from before
+// the callback when it fails.
+type fakeStore struct {
+	repository.Store
+
+	mu          sync.Mutex
+	users       map[string]models.User
+	revocations []models.TokenRevocation
+	// reassigned records every Reassign call as "from->to".
+	reassigned []string
+	syncs      int
+}
+
+func newFakeStore(users ...models.User) *fakeStore {
+	s := &fakeStore{users: map[string]models.User{}}
+	for _, user := range users {
+		s.users[user.ID] = user
+	}
+	return s
+}
+
+func (s *fakeStore) Users() repository.Users             { return fakeUsers{s: s} }
+func (s *fakeStore) Revocations() repository.Revocations { return fakeRevocations{s: s} }
+
+func (s *fakeStore) Transaction(ctx context.Context, fn func(tx repository.Store) error) error {
+	s.mu.Lock()
+	users := make(map[string]models.User, len(s.users))
+	for id, user := range s.users {
+		users[id] = user
+	}
+	reassigned := append([]string(nil), s.reassigned...)
+	s.mu.Unlock()
+
+	if err := fn(s); err != nil {
+		s.mu.Lock()
+		s.users, s.reassigned = users, reassigned
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (s *fakeStore) user(id string) (models.User, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := s.users[id]
+	return user, ok
+}
+
+type fakeUsers struct {
+	repository.Users
+	s *fakeStore
+}
+
+func (r fakeUsers) Get(ctx context.Context, id string) (*models.User, error) {
+	user, ok := r.s.user(id)
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &user, nil
+}
+
+func (r fakeUsers) Sync(ctx context.Context, user *models.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.syncs++
+	stored, ok := r.s.users[user.ID]
+	if !ok {
+		stored = models.User{ID: user.ID, Status: models.UserStatusActive}
+	}
+	stored.Email, stored.Name, stored.PreferredUsername = user.Email, user.Name, user.PreferredUsername
+	stored.Locale, stored.Phone, stored.Roles = user.Locale, user.Phone, user.Roles
+	r.s.users[user.ID] = stored
+	return nil
+}
+
+func (r fakeUsers) LockByEmail(ctx context.Context, email, exceptID string) (*models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, user := range r.s.users {
+		if user.ID != exceptID && strings.EqualFold(user.Email, email) {
+			return &user, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r fakeUsers) Reassign(ctx context.Context, fromID, toID string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.reassigned = append(r.s.reassigned, fromID+"->"+toID)
+	return nil
+}
+
+func (r fakeUsers) ChangeID(ctx context.Context, user *models.User, id string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	stored := r.s.users[user.ID]
+	delete(r.s.users, user.ID)
+	stored.ID = id
+	r.s.users[id] = stored
+	user.ID = id
+	return nil
+}
+
+func (r fakeUsers) Delete(ctx context.Context, user *models.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	delete(r.s.users, user.ID)
+	return nil
+}
+
+func (r fakeUsers) SaveModeration(ctx context.Context, user *models.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	stored, ok := r.s.users[user.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	stored.Status, stored.SuspendedUntil, stored.SuspensionReason = user.Status, user.SuspendedUntil, user.SuspensionReason
+	r.s.users[user.ID] = stored
+	return nil
+}
+
+func (r fakeUsers) ListSuspended(ctx context.Context) ([]models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var users []models.User
+	for _, user := range r.s.users {
+		if user.Status == models.UserStatusSuspended {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+type fakeRevocations struct {
+	repository.Revocations
+	s *fakeStore
+}
+
+func (r fakeRevocations) ListActive(ctx context.Context, sessionCutoff time.Time) ([]models.TokenRevocation, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var revocations []models.TokenRevocation
+	for _, revocation := range r.s.revocations {
+		if revocation.SessionID == "" || revocation.CreatedAt.After(sessionCutoff) {
+			revocations = append(revocations, revocation)
+		}
+	}
+	return revocations, nil
+}
+
+func (r fakeRevocations) PruneSessions(ctx context.Context, cutoff time.Time) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var kept []models.TokenRevocation
+	for _, revocation := range r.s.revocations {
+		if revocation.SessionID == "" || revocation.CreatedAt.After(cutoff) {
+			kept = append(kept, revocation)
+		}
+	}
+	pruned := int64(len(r.s.revocations) - len(kept))
+	r.s.revocations = kept
+	return pruned, nil
+}
+
+var errStoreDown = errors.New("postgres: connection refused")
+
+// failingLimiter is a rate-limit store whose database is unreachable.
+type failingLimiter struct{}
+
+func (failingLimiter) Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errStoreDown
+}
+
+// testAuth is an AuthMiddleware backed by a fakeStore that trusts tokens
+// signed by key.
+type testAuth struct {
+	*AuthMiddleware
+	key   *rsa.PrivateKey
+	store *fakeStore
+}
+
+func newTestAuth(t *testing.T, store *fakeStore) *testAuth {
+	t.Helper()
+	dir := t.TempDir()
+	jwksPath := filepath.Join(dir, "jwks.json")
+	key, err := LoadOrCreateDevKey(filepath.Join(dir, "dev-key.pem"), jwksPath)
+	if err != nil {
+		t.Fatalf("dev key: %v", err)
+	}
+	cfg := AuthConfig{
+		KeycloakURL:  "http://keycloak.test",
+		Realm:        "test",
+		JWKSFile:     jwksPath,
+		UserCacheTTL: time.Minute,
+	}
+	auth, err := NewAuthMiddleware(cfg, store, NewDenyList(store, 0))
+	if err != nil {
+		t.Fatalf("auth middleware: %v", err)
+	}
+	return &testAuth{AuthMiddleware: auth, key: key, store: store}
+}
+
+// userClaims returns the claims of a user token for subject with a verified
+// email, for the test to adjust before signing.
+func (a *testAuth) userClaims(subject, email string) *KeycloakClaims {
+	now := time.Now()
+	claims := &KeycloakClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    a.cfg.ExpectedIssuer(),
+			Subject:   subject,
+			IssuedAt:  jwt.NewNumericDate(now.Add(-time.Minute)),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+		Type:            "Bearer",
+		AuthorizedParty: "report-frontend",
+		Email:           email,
+		EmailVerified:   true,
+		Name:            "Jane Doe",
+	}
+	claims.RealmAccess.Roles = []string{"CITIZEN"}
+	return claims
+}
+
+func (a *testAuth) sign(t *testing.T, claims *KeycloakClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = devKeyID(&a.key.PublicKey)
+	signed, err := token.SignedString(a.key)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+// do sends a GET through Authenticate and then the handlers, and returns
+// the recorder.
+func (a *testAuth) do(t *testing.T, claims *KeycloakClaims, handlers ...gin.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/reports/:id", append([]gin.HandlerFunc{a.Authenticate()}, handlers...)...)
+
+	req := httptest.NewRequest(http.MethodGet, "/reports/RPT-1", nil)
+	req.Header.Set("Authorization", "Bearer "+a.sign(t, claims))
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	return rec
+}
+
+func noContent(c *gin.Context) { c.Status(http.StatusNoContent) }
@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"reportmaxxing/services/report-management-service/response"
+)
+
+// DefaultIntrospectionCacheTTL bounds how long an introspection result is
+// reused before Keycloak is asked again.
+const DefaultIntrospectionCacheTTL = 30 * time.Second
+
+// introspector asks Keycloak whether a token is still active, which catches
+// logouts and disabled users that the local deny-list hasn't heard about.
+type introspector struct {
+	endpoint     string
+	clientID     string
+	clientSecret string
+	ttl          time.Duration
+	client       *http.Client
+
+	mu      sync.Mutex
+	results map[string]introspectionResult
+}
+
+type introspectionResult struct {
+	active    bool
+	expiresAt time.Time
+}
+
+func newIntrospector(cfg AuthConfig) *introspector {
+	if cfg.IntrospectionClientID == "" {
+		return nil
+	}
+	ttl := cfg.IntrospectionCacheTTL
+	if ttl <= 0 {
+		ttl = DefaultIntrospectionCacheTTL
+	}
+	return &introspector{
+		endpoint:     cfg.realmURL() + "/protocol/openid-connect/token/introspect",
+		clientID:     cfg.IntrospectionClientID,
+		clientSecret: cfg.IntrospectionClientSecret,
+		ttl:          ttl,
+		client:       &http.Client{Timeout: 5 * time.Second},
+		results:      make(map[string]introspectionResult),
+	}
+}
+
+func (i *introspector) active(ctx context.Context, token string, expiresAt time.Time) (bool, error) {
+	sum := sha256.Sum256([]byte(token))
+	key := hex.EncodeToString(sum[:])
+	now := time.Now()
+
+	i.mu.Lock()
+	cached, ok := i.results[key]
+	i.mu.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return cached.active, nil
+	}
+
+	active, err := i.introspect(ctx, token)
+	if err != nil {
+		return false, err
+	}
+
+	cacheUntil := now.Add(i.ttl)
+	if !expiresAt.IsZero() && expiresAt.Before(cacheUntil) {
+		cacheUntil = expiresAt
+	}
+	i.mu.Lock()
+	for k, result := range i.results {
+		if now.After(result.expiresAt) {
+			delete(i.results, k)
+		}
+	}
+	i.results[key] = introspectionResult{active: active, expiresAt: cacheUntil}
+	i.mu.Unlock()
+	return active, nil
+}
+
+func (i *introspector) introspect(ctx context.Context, token string) (bool, error) {
+	form := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, i.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(i.clientID, i.clientSecret)
+
+	resp, err := i.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("introspection returned %s", resp.Status)
+	}
+
+	var body struct {
+		Active bool `json:"active"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return false, fmt.Errorf("invalid introspection response: %w", err)
+	}
+	return body.Active, nil
+}
+
+// RequireActiveToken checks with Keycloak that the caller's token has not
+// been revoked. Use it after Authenticate on sensitive routes; it does
+// nothing when introspection is not configured. Keycloak being unreachable
+// fails the request rather than letting a possibly revoked token through.
+func (a *AuthMiddleware) RequireActiveToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if a.introspector == nil {
+			c.Next()
+			return
+		}
+
+		token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		var expiresAt time.Time
+		if exp, ok := c.Get("tokenExpiresAt"); ok {
+			expiresAt, _ = exp.(time.Time)
+		}
+
+		active, err := a.introspector.active(c.Request.Context(), token, expiresAt)
+		if err != nil {
+			Logger(c).Error("auth: introspection failed", "error", err)
+			response.ServiceUnavailable(c, "Unable to verify token")
+			c.Abort()
+			return
+		}
+		if !active {
+			response.UnauthorizedWithCode(c, ErrCodeTokenRevoked, "Token is no longer active")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// jwksRefreshInterval is how often the realm's signing keys are refetched.
+// Keys older than two intervals mean refreshes have been failing, and tokens
+// signed after a Keycloak key rotation would be rejected.
+const jwksRefreshInterval = time.Hour
+
+// jwksTracker records when the signing keys were last fetched successfully.
+// It wraps the HTTP transport used by the key set, so both the periodic
+// refresh and unknown-kid refetches count.
+type jwksTracker struct {
+	transport http.RoundTripper
+
+	mu          sync.Mutex
+	lastRefresh time.Time
+	lastErr     error
+}
+
+func newJWKSTracker() *jwksTracker {
+	return &jwksTracker{transport: http.DefaultTransport}
+}
+
+func (t *jwksTracker) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.transport.RoundTrip(req)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch {
+	case err != nil:
+		t.lastErr = err
+	case resp.StatusCode != http.StatusOK:
+		t.lastErr = fmt.Errorf("JWKS endpoint returned %s", resp.Status)
+	default:
+		t.lastRefresh = time.Now()
+		t.lastErr = nil
+	}
+	return resp, err
+}
+
+func (t *jwksTracker) refreshFailed(url string) func(ctx context.Context, err error) {
+	return func(ctx context.Context, err error) {
+		slog.WarnContext(ctx, "auth: JWKS refresh failed", "url", url, "error", err)
+		t.mu.Lock()
+		t.lastErr = err
+		t.mu.Unlock()
+	}
+}
+
+// CheckJWKS reports whether signing keys are loaded and, when they come from
+// Keycloak, were refreshed recently.
+func (a *AuthMiddleware) CheckJWKS(ctx context.Context) error {
+	keys, err := a.jwks.Storage().KeyReadAll(ctx)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return errors.New("no signing keys loaded")
+	}
+	if a.jwksTracker == nil {
+		return nil
+	}
+
+	a.jwksTracker.mu.Lock()
+	lastRefresh, lastErr := a.jwksTracker.lastRefresh, a.jwksTracker.lastErr
+	a.jwksTracker.mu.Unlock()
+
+	if age := time.Since(lastRefresh); age > 2*jwksRefreshInterval {
+		return fmt.Errorf("signing keys last refreshed %s ago: %v", age.Round(time.Second), lastErr)
+	}
+	return nil
+}
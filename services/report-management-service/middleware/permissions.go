@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"reportmaxxing/services/report-management-service/models"
+	"reportmaxxing/services/report-management-service/policy"
+	"reportmaxxing/services/report-management-service/response"
+)
+
+type ReportLoader func(id string) (*models.Report, error)
+
+// Authorizer evaluates the permission policy for authenticated requests.
+type Authorizer struct {
+	policy     *policy.Policy
+	loadReport ReportLoader
+}
+
+func NewAuthorizer(p *policy.Policy, loadReport ReportLoader) *Authorizer {
+	return &Authorizer{policy: p, loadReport: loadReport}
+}
+
+func (a *Authorizer) Policy() *policy.Policy {
+	return a.policy
+}
+
+// Require allows the request if the caller holds action at any scope. Use it
+// for actions that do not target an existing report.
+func (a *Authorizer) Require(action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !a.policy.Allowed(PrincipalFromContext(c), action) {
+			response.Forbidden(c, "Insufficient permissions")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireOnReport loads the report named by the :id parameter and allows the
+// request if the caller may perform action on it. The report is stored as
+// "report" for the handler.
+func (a *Authorizer) RequireOnReport(action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := PrincipalFromContext(c)
+		if !a.policy.Allowed(principal, action) {
+			response.Forbidden(c, "Insufficient permissions")
+			c.Abort()
+			return
+		}
+
+		report, err := a.loadReport(c.Param("id"))
+		if err != nil {
+			response.NotFound(c, "Report not found")
+			c.Abort()
+			return
+		}
+		if !a.policy.AllowedOn(principal, action, report) {
+			response.Forbidden(c, "Access denied")
+			c.Abort()
+			return
+		}
+
+		c.Set("report", report)
+		c.Next()
+	}
+}
+
+func PrincipalFromContext(c *gin.Context) policy.Principal {
+	principal := policy.Principal{
+		UserID:   c.GetString("userID"),
+		ClientID: c.GetString("clientID"),
+	}
+	if roles, ok := c.Get("roles"); ok {
+		principal.Roles, _ = roles.([]string)
+	}
+	if departments, ok := c.Get("departments"); ok {
+		principal.Departments, _ = departments.([]string)
+	}
+	return principal
+}
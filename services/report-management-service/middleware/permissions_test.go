@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"reportmaxxing/services/report-management-service/models"
+	"reportmaxxing/services/report-management-service/policy"
+	"reportmaxxing/services/report-management-service/repository"
+)
+
+func TestRequireOnReportScopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	p, err := policy.Load("")
+	if err != nil {
+		t.Fatalf("policy: %v", err)
+	}
+	reports := map[string]*models.Report{
+		"RPT-1": {ID: "RPT-1", Category: models.CategorySanitation, UserID: "citizen-1", CreatedByID: "citizen-1"},
+		"RPT-2": {ID: "RPT-2", Category: models.CategoryCrime, UserID: "citizen-2", CreatedByID: "staff-1"},
+	}
+	authorizer := NewAuthorizer(p, func(id string) (*models.Report, error) {
+		if report, ok := reports[id]; ok {
+			return report, nil
+		}
+		return nil, repository.ErrNotFound
+	})
+
+	tests := []struct {
+		name        string
+		userID      string
+		roles       []string
+		departments []string
+		action      string
+		reportID    string
+		want        int
+	}{
+		{name: "own report", userID: "citizen-1", roles: []string{"CITIZEN"}, action: policy.ActionReportRead, reportID: "RPT-1", want: http.StatusOK},
+		{name: "someone else's report", userID: "citizen-1", roles: []string{"CITIZEN"}, action: policy.ActionReportRead, reportID: "RPT-2", want: http.StatusForbidden},
+		{name: "action not granted", userID: "citizen-1", roles: []string{"CITIZEN"}, action: policy.ActionReportDelete, reportID: "RPT-1", want: http.StatusForbidden},
+		{name: "own scope without a user ID", roles: []string{"CITIZEN"}, action: policy.ActionReportRead, reportID: "RPT-1", want: http.StatusForbidden},
+		{name: "staff in the report's department", userID: "staff-2", roles: []string{"DEPARTMENT_STAFF"}, departments: []string{"SANITATION"}, action: policy.ActionReportStatusUpdate, reportID: "RPT-1", want: http.StatusOK},
+		{name: "staff in another department", userID: "staff-2", roles: []string{"DEPARTMENT_STAFF"}, departments: []string{"SANITATION"}, action: policy.ActionReportStatusUpdate, reportID: "RPT-2", want: http.StatusForbidden},
+		{name: "staff without a department", userID: "staff-2", roles: []string{"DEPARTMENT_STAFF"}, action: policy.ActionReportStatusUpdate, reportID: "RPT-1", want: http.StatusForbidden},
+		{name: "staff outside the department who filed the report", userID: "staff-1", roles: []string{"DEPARTMENT_STAFF"}, action: policy.ActionReportRead, reportID: "RPT-2", want: http.StatusOK},
+		{name: "any scope", userID: "supervisor-1", roles: []string{"SUPERVISOR"}, action: policy.ActionReportStatusUpdate, reportID: "RPT-2", want: http.StatusOK},
+		{name: "missing report", userID: "supervisor-1", roles: []string{"SUPERVISOR"}, action: policy.ActionReportRead, reportID: "RPT-404", want: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.GET("/reports/:id",
+				func(c *gin.Context) {
+					c.Set("userID", tt.userID)
+					c.Set("roles", tt.roles)
+					c.Set("departments", tt.departments)
+				},
+				authorizer.RequireOnReport(tt.action),
+				func(c *gin.Context) {
+					if report, ok := c.Get("report"); !ok || report.(*models.Report).ID != tt.reportID {
+						t.Errorf("report in context = %v, want %s", report, tt.reportID)
+					}
+					c.Status(http.StatusOK)
+				},
+			)
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/reports/"+tt.reportID, nil))
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"reportmaxxing/services/report-management-service/ratelimit"
+)
+
+func rateLimitedRouter(limiter *RateLimiter, userID string, roles []string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		if userID != "" {
+			c.Set("userID", userID)
+			c.Set("roles", roles)
+		}
+	})
+	r.Use(limiter.Limit("reports", ratelimit.Limit{Requests: 2, Period: time.Hour}))
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	return r
+}
+
+func get(r *gin.Engine) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	return rec
+}
+
+func TestRateLimiterRejectsOverLimit(t *testing.T) {
+	limiter := NewRateLimiter(ratelimit.NewMemoryStore(), []string{"ADMIN"})
+	citizen := rateLimitedRouter(limiter, "citizen-1", []string{"CITIZEN"})
+
+	for i := 0; i < 2; i++ {
+		if rec := get(citizen); rec.Code != http.StatusNoContent {
+			t.Fatalf("request %d: status = %d, want %d", i+1, rec.Code, http.StatusNoContent)
+		}
+	}
+	rec := get(citizen)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("third request: status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Error("Retry-After not set")
+	}
+
+	// Buckets are per user, and exempt roles skip them.
+	if rec := get(rateLimitedRouter(limiter, "citizen-2", []string{"CITIZEN"})); rec.Code != http.StatusNoContent {
+		t.Errorf("another user: status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	admin := rateLimitedRouter(limiter, "admin-1", []string{"ADMIN"})
+	for i := 0; i < 3; i++ {
+		if rec := get(admin); rec.Code != http.StatusNoContent {
+			t.Fatalf("exempt request %d: status = %d, want %d", i+1, rec.Code, http.StatusNoContent)
+		}
+	}
+}
+
+func TestRateLimiterFailsOpen(t *testing.T) {
+	limiter := NewRateLimiter(failingLimiter{}, nil)
+	for _, userID := range []string{"", "citizen-1"} {
+		rec := get(rateLimitedRouter(limiter, userID, []string{"CITIZEN"}))
+		if rec.Code != http.StatusNoContent {
+			t.Errorf("user %q: status = %d, want the request let through", userID, rec.Code)
+		}
+		if rec.Header().Get("X-RateLimit-Remaining") != "" {
+			t.Errorf("user %q: rate-limit headers set without a result", userID)
+		}
+	}
+}
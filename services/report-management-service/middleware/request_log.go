@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"reportmaxxing/services/report-management-service/logging"
+)
+
+// RequestLogger attaches a logger carrying the request ID, route and trace
+// ID to the request context, and logs one line per request once it has been
+// served. Requests to quietRoutes are logged at debug level. It must run
+// after CorrelationID and TraceContext.
+func RequestLogger(quietRoutes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		logger := slog.Default().With(
+			"request_id", c.GetString("correlationID"),
+			"method", c.Request.Method,
+			"route", c.FullPath(),
+		)
+		if traceID := c.GetString("traceID"); traceID != "" {
+			logger = logger.With("trace_id", traceID)
+		}
+		setLogger(c, logger)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case slices.Contains(quietRoutes, c.FullPath()):
+			level = slog.LevelDebug
+		}
+		attrs := []interface{}{
+			"path", c.Request.URL.Path,
+			"status", status,
+			"latency_ms", time.Since(start).Milliseconds(),
+			"client_ip", c.ClientIP(),
+			"bytes", c.Writer.Size(),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, "errors", c.Errors.String())
+		}
+		Logger(c).Log(c.Request.Context(), level, "request", attrs...)
+	}
+}
+
+// Recovery turns a panic into a 500 and logs it with the request's logger.
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered interface{}) {
+		Logger(c).Error("panic serving request", "panic", recovered, "stack", string(debug.Stack()))
+		c.AbortWithStatus(http.StatusInternalServerError)
+	})
+}
+
+// Logger returns the request-scoped logger.
+func Logger(c *gin.Context) *slog.Logger {
+	return logging.FromContext(c.Request.Context())
+}
+
+// WithLogAttrs adds attributes to every later log line for this request,
+// including the request line itself.
+func WithLogAttrs(c *gin.Context, args ...interface{}) {
+	setLogger(c, Logger(c).With(args...))
+}
+
+func setLogger(c *gin.Context, logger *slog.Logger) {
+	c.Request = c.Request.WithContext(logging.WithLogger(c.Request.Context(), logger))
+}
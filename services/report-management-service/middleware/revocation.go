@@ -0,0 +1,162 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"reportmaxxing/services/report-management-service/models"
+	"reportmaxxing/services/report-management-service/repository"
+)
+
+// DefaultMaxTokenLifetime bounds how long a session revocation is kept when
+// AuthConfig.MaxTokenLifetime is not set.
+const DefaultMaxTokenLifetime = time.Hour
+
+// DenyList is an in-memory copy of the token_revocations table and of the
+// suspended users, refreshed periodically so every instance sees revocations
+// and suspensions made through any of them.
+// A session revocation only matters until the last token of the session has
+// expired, so after maxTokenLifetime it is dropped and its row pruned.
+type DenyList struct {
+	store            repository.Store
+	maxTokenLifetime time.Duration
+
+	mu        sync.RWMutex
+	subjects  map[string]time.Time
+	sessions  map[string]bool
+	suspended map[string]models.User
+}
+
+func NewDenyList(store repository.Store, maxTokenLifetime time.Duration) *DenyList {
+	if maxTokenLifetime <= 0 {
+		maxTokenLifetime = DefaultMaxTokenLifetime
+	}
+	return &DenyList{
+		store:            store,
+		maxTokenLifetime: maxTokenLifetime,
+		subjects:         make(map[string]time.Time),
+		sessions:         make(map[string]bool),
+		suspended:        make(map[string]models.User),
+	}
+}
+
+// Refresh reloads the deny-list and suspensions from the database.
+func (d *DenyList) Refresh(ctx context.Context) error {
+	revocations, err := d.store.Revocations().ListActive(ctx, d.sessionCutoff())
+	if err != nil {
+		return err
+	}
+
+	subjects := make(map[string]time.Time)
+	sessions := make(map[string]bool)
+	for _, revocation := range revocations {
+		if revocation.Subject != "" && revocation.CreatedAt.After(subjects[revocation.Subject]) {
+			subjects[revocation.Subject] = revocation.CreatedAt
+		}
+		if revocation.SessionID != "" {
+			sessions[revocation.SessionID] = true
+		}
+	}
+
+	users, err := d.store.Users().ListSuspended(ctx)
+	if err != nil {
+		return err
+	}
+	suspended := make(map[string]models.User, len(users))
+	for _, user := range users {
+		suspended[user.ID] = user
+	}
+
+	d.mu.Lock()
+	d.subjects = subjects
+	d.sessions = sessions
+	d.suspended = suspended
+	d.mu.Unlock()
+	return nil
+}
+
+// Suspension returns the user's suspension if they are suspended now.
+func (d *DenyList) Suspension(userID string) (*models.User, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	user, ok := d.suspended[userID]
+	if !ok || !user.Suspended(time.Now()) {
+		return nil, false
+	}
+	return &user, true
+}
+
+// SetSuspension applies a suspension change made through this instance
+// without waiting for the next refresh.
+func (d *DenyList) SetSuspension(user *models.User) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if user.Status == models.UserStatusSuspended {
+		d.suspended[user.ID] = models.User{
+			ID:               user.ID,
+			Status:           user.Status,
+			SuspendedUntil:   user.SuspendedUntil,
+			SuspensionReason: user.SuspensionReason,
+		}
+	} else {
+		delete(d.suspended, user.ID)
+	}
+}
+
+// Prune deletes session revocations older than the longest-lived token they
+// could still reject.
+func (d *DenyList) Prune(ctx context.Context) error {
+	pruned, err := d.store.Revocations().PruneSessions(ctx, d.sessionCutoff())
+	if err != nil {
+		return err
+	}
+	if pruned > 0 {
+		slog.Info("revocations: pruned expired session revocations", "count", pruned)
+	}
+	return nil
+}
+
+func (d *DenyList) sessionCutoff() time.Time {
+	return time.Now().Add(-d.maxTokenLifetime)
+}
+
+// Run refreshes the deny-list every interval until ctx is cancelled, pruning
+// expired session revocations first.
+func (d *DenyList) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := d.Prune(ctx); err != nil && ctx.Err() == nil {
+				slog.Error("revocations: prune failed", "error", err)
+			}
+			if err := d.Refresh(ctx); err != nil && ctx.Err() == nil {
+				slog.Error("revocations: refresh failed", "error", err)
+			}
+		}
+	}
+}
+
+// Revoked reports whether the token's session is revoked, or its subject was
+// revoked after the token was issued.
+func (d *DenyList) Revoked(claims *KeycloakClaims) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if sessionID := claims.SessionID(); sessionID != "" && d.sessions[sessionID] {
+		return true
+	}
+	revokedAt, ok := d.subjects[claims.Subject]
+	if !ok {
+		return false
+	}
+	return claims.IssuedAt == nil || !claims.IssuedAt.Time.After(revokedAt)
+}
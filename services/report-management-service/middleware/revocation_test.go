@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"reportmaxxing/services/report-management-service/models"
+)
+
+func TestDenyListRefreshAndPrune(t *testing.T) {
+	now := time.Now()
+	until := now.Add(time.Hour)
+	expired := now.Add(-time.Hour)
+	store := newFakeStore(
+		models.User{ID: "suspended", Status: models.UserStatusSuspended, SuspendedUntil: &until, SuspensionReason: "spam"},
+		models.User{ID: "lapsed", Status: models.UserStatusSuspended, SuspendedUntil: &expired},
+		models.User{ID: "active", Status: models.UserStatusActive},
+	)
+	store.revocations = []models.TokenRevocation{
+		{ID: "1", Subject: "revoked", CreatedAt: now.Add(-time.Minute)},
+		{ID: "2", SessionID: "live-session", CreatedAt: now.Add(-30 * time.Minute)},
+		{ID: "3", SessionID: "old-session", CreatedAt: now.Add(-2 * time.Hour)},
+	}
+	denyList := NewDenyList(store, time.Hour)
+	if err := denyList.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	token := func(subject, session string, issuedAt time.Time) *KeycloakClaims {
+		return &KeycloakClaims{
+			RegisteredClaims: jwt.RegisteredClaims{Subject: subject, IssuedAt: jwt.NewNumericDate(issuedAt)},
+			SID:              session,
+		}
+	}
+	revoked := []struct {
+		name   string
+		claims *KeycloakClaims
+		want   bool
+	}{
+		{name: "subject revoked after issue", claims: token("revoked", "", now.Add(-time.Hour)), want: true},
+		{name: "subject reissued after revocation", claims: token("revoked", "", now)},
+		{name: "revoked session", claims: token("someone", "live-session", now), want: true},
+		{name: "session older than any token", claims: token("someone", "old-session", now)},
+		{name: "unrevoked", claims: token("someone", "other-session", now)},
+	}
+	for _, tt := range revoked {
+		if got := denyList.Revoked(tt.claims); got != tt.want {
+			t.Errorf("Revoked(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	if suspension, ok := denyList.Suspension("suspended"); !ok || suspension.SuspensionReason != "spam" {
+		t.Errorf("Suspension(suspended) = %+v, %v; want the suspension", suspension, ok)
+	}
+	for _, id := range []string{"lapsed", "active"} {
+		if _, ok := denyList.Suspension(id); ok {
+			t.Errorf("Suspension(%s) reported a suspension", id)
+		}
+	}
+
+	if err := denyList.Prune(context.Background()); err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	var kept []string
+	for _, revocation := range store.revocations {
+		kept = append(kept, revocation.ID)
+	}
+	if len(kept) != 2 || kept[0] != "1" || kept[1] != "2" {
+		t.Errorf("revocations after Prune = %v, want the subject and live session revocations", kept)
+	}
+
+	// A refresh replaces what was loaded before.
+	store.revocations = nil
+	if err := denyList.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if denyList.Revoked(token("revoked", "live-session", now.Add(-time.Hour))) {
+		t.Error("lifted revocations still apply after a refresh")
+	}
+}
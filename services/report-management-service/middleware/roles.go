@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"slices"
+	"strings"
+)
+
+const (
+	RoleSourceRealm  = "realm"
+	RoleSourceClient = "client"
+	RoleSourceGroups = "groups"
+
+	DefaultRoleGroupPrefix       = "/roles/"
+	DefaultDepartmentGroupPrefix = "/departments/"
+)
+
+// resolveRoles merges the roles granted by each configured source:
+//   - realm:  realm_access.roles
+//   - client: resource_access.<RoleClientID>.roles
+//   - groups: groups directly under RoleGroupPrefix, so membership of
+//     "/roles/DEPARTMENT_STAFF" grants DEPARTMENT_STAFF. Other groups, and
+//     groups nested deeper under the prefix, grant nothing.
+func (cfg AuthConfig) resolveRoles(claims *KeycloakClaims) []string {
+	sources := cfg.RoleSources
+	if len(sources) == 0 {
+		sources = []string{RoleSourceRealm}
+	}
+
+	roles := []string{}
+	add := func(role string) {
+		if role != "" && !slices.Contains(roles, role) {
+			roles = append(roles, role)
+		}
+	}
+
+	for _, source := range sources {
+		switch source {
+		case RoleSourceRealm:
+			for _, role := range claims.RealmAccess.Roles {
+				add(role)
+			}
+		case RoleSourceClient:
+			for _, role := range claims.ResourceAccess[cfg.roleClientID()].Roles {
+				add(role)
+			}
+		case RoleSourceGroups:
+			for _, group := range claims.Groups {
+				role, ok := strings.CutPrefix(group, cfg.roleGroupPrefix())
+				if ok && !strings.Contains(role, "/") {
+					add(role)
+				}
+			}
+		}
+	}
+	return roles
+}
+
+// resolveDepartments returns the departments the caller belongs to, taken
+// from groups under DepartmentGroupPrefix and upper-cased to match report
+// categories ("/departments/sanitation" -> "SANITATION").
+func (cfg AuthConfig) resolveDepartments(claims *KeycloakClaims) []string {
+	var departments []string
+	for _, group := range claims.Groups {
+		if !cfg.isDepartmentGroup(group) {
+			continue
+		}
+		department := strings.ToUpper(strings.TrimPrefix(group, cfg.departmentGroupPrefix()))
+		if department != "" && !slices.Contains(departments, department) {
+			departments = append(departments, department)
+		}
+	}
+	return departments
+}
+
+func (cfg AuthConfig) roleClientID() string {
+	if cfg.RoleClientID != "" {
+		return cfg.RoleClientID
+	}
+	if len(cfg.AllowedClients) > 0 {
+		return cfg.AllowedClients[0]
+	}
+	return ""
+}
+
+func (cfg AuthConfig) roleGroupPrefix() string {
+	if cfg.RoleGroupPrefix != "" {
+		return cfg.RoleGroupPrefix
+	}
+	return DefaultRoleGroupPrefix
+}
+
+func (cfg AuthConfig) departmentGroupPrefix() string {
+	if cfg.DepartmentGroupPrefix != "" {
+		return cfg.DepartmentGroupPrefix
+	}
+	return DefaultDepartmentGroupPrefix
+}
+
+func (cfg AuthConfig) isDepartmentGroup(group string) bool {
+	return strings.HasPrefix(group, cfg.departmentGroupPrefix())
+}
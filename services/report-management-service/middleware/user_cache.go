@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"reportmaxxing/services/report-management-service/models"
+)
+
+// DefaultUserCacheTTL is how long a synced user is trusted before the next
+// request writes its claims to the database again.
+const DefaultUserCacheTTL = 5 * time.Minute
+
+// userCache remembers which subjects were recently synced and with which
+// claims, so unchanged users don't cost a database round trip per request.
+// It holds profile fields only; suspensions come from the DenyList.
+type userCache struct {
+	ttl       time.Duration
+	mu        sync.Mutex
+	entries   map[string]userCacheEntry
+	lastSweep time.Time
+}
+
+type userCacheEntry struct {
+	claimsHash string
+	user       models.User
+	expiresAt  time.Time
+}
+
+func newUserCache(ttl time.Duration) *userCache {
+	return &userCache{ttl: ttl, entries: make(map[string]userCacheEntry)}
+}
+
+// get returns the cached user if subject was synced with the same claims
+// within the TTL.
+func (c *userCache) get(subject, claimsHash string) (*models.User, bool) {
+	if c.ttl <= 0 {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[subject]
+	if !ok || entry.claimsHash != claimsHash || !time.Now().Before(entry.expiresAt) {
+		return nil, false
+	}
+	user := entry.user
+	return &user, true
+}
+
+func (c *userCache) store(claimsHash string, user models.User) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(c.lastSweep) > c.ttl {
+		for key, entry := range c.entries {
+			if now.After(entry.expiresAt) {
+				delete(c.entries, key)
+			}
+		}
+		c.lastSweep = now
+	}
+	c.entries[user.ID] = userCacheEntry{claimsHash: claimsHash, user: user, expiresAt: now.Add(c.ttl)}
+}
+
+// userClaimsHash fingerprints the claims and roles that are copied onto the
+// users row.
+func userClaimsHash(claims *KeycloakClaims, roles []string) string {
+	h := sha256.New()
+	for _, value := range append([]string{claims.Email, claims.Name, claims.PreferredUsername, claims.Locale, claims.PhoneNumber}, roles...) {
+		h.Write([]byte(value))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"io/fs"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// Databases created by the old AutoMigrate startup already have some of the
+// tables and columns, so every up migration must tolerate them existing.
+var notIdempotent = regexp.MustCompile(`(?i)\b(CREATE\s+TABLE|CREATE\s+(UNIQUE\s+)?INDEX|ADD\s+COLUMN)\s+(?:IF\s+NOT\s+EXISTS\b)?`)
+
+func TestEmbeddedMigrationsAdoptAutoMigrateSchemas(t *testing.T) {
+	sqlFS, err := fs.Sub(embedded, "sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrations, err := Load(sqlFS)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(migrations) == 0 || migrations[0].Name != "initial_schema" {
+		t.Fatal("the first migration must be initial_schema")
+	}
+
+	for i, m := range migrations {
+		if m.Version != int64(i+1) {
+			t.Errorf("migration %d_%s: versions must be consecutive, want %d", m.Version, m.Name, i+1)
+		}
+		for _, stmt := range notIdempotent.FindAllString(m.Up, -1) {
+			if !strings.HasSuffix(strings.ToUpper(strings.Join(strings.Fields(stmt), " ")), "IF NOT EXISTS") {
+				t.Errorf("migration %d_%s: %q must use IF NOT EXISTS", m.Version, m.Name, strings.TrimSpace(stmt))
+			}
+		}
+	}
+}
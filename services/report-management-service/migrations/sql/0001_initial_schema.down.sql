@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS report_updates;
+DROP TABLE IF EXISTS reports;
+DROP TABLE IF EXISTS users;
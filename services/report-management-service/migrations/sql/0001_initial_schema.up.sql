@@ -0,0 +1,37 @@
+-- The schema AutoMigrate created before versioned migrations. IF NOT EXISTS
+-- lets databases created that way adopt the migrations; later migrations add
+-- columns with IF NOT EXISTS for the same reason.
+
+CREATE TABLE IF NOT EXISTS users (
+    id         varchar(36) PRIMARY KEY,
+    email      varchar(255) NOT NULL,
+    name       varchar(255),
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email);
+
+CREATE TABLE IF NOT EXISTS reports (
+    id          varchar(20) PRIMARY KEY,
+    title       varchar(255) NOT NULL,
+    description text NOT NULL,
+    category    varchar(50) NOT NULL,
+    status      varchar(50) NOT NULL DEFAULT 'OPEN',
+    visibility  varchar(50) NOT NULL DEFAULT 'PUBLIC',
+    image_url   text,
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    user_id     varchar(36)
+);
+CREATE INDEX IF NOT EXISTS idx_reports_user_id ON reports (user_id);
+
+CREATE TABLE IF NOT EXISTS report_updates (
+    id         varchar(36) PRIMARY KEY,
+    report_id  varchar(20),
+    title      varchar(255) NOT NULL,
+    date       varchar(100),
+    is_active  boolean DEFAULT false,
+    created_at timestamptz,
+    CONSTRAINT fk_reports_updates FOREIGN KEY (report_id) REFERENCES reports (id)
+);
+CREATE INDEX IF NOT EXISTS idx_report_updates_report_id ON report_updates (report_id);
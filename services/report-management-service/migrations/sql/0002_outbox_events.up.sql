@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id           varchar(36) PRIMARY KEY,
+    event_type   varchar(100) NOT NULL,
+    topic        varchar(255) NOT NULL,
+    key          varchar(255) NOT NULL,
+    payload      jsonb NOT NULL,
+    created_at   timestamptz,
+    published_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_event_type ON outbox_events (event_type);
+CREATE INDEX IF NOT EXISTS idx_outbox_events_created_at ON outbox_events (created_at);
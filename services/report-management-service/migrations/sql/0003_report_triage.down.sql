@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS report_attachments;
+DROP TABLE IF EXISTS report_comments;
+ALTER TABLE reports DROP COLUMN IF EXISTS merged_into_id;
+ALTER TABLE reports DROP COLUMN IF EXISTS assignee_id;
+ALTER TABLE reports DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE reports DROP COLUMN IF EXISTS priority;
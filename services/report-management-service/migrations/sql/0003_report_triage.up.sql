@@ -0,0 +1,28 @@
+ALTER TABLE reports ADD COLUMN IF NOT EXISTS priority varchar(20) NOT NULL DEFAULT 'NORMAL';
+ALTER TABLE reports ADD COLUMN IF NOT EXISTS deleted_at timestamptz;
+ALTER TABLE reports ADD COLUMN IF NOT EXISTS assignee_id varchar(36);
+ALTER TABLE reports ADD COLUMN IF NOT EXISTS merged_into_id varchar(20);
+CREATE INDEX IF NOT EXISTS idx_reports_deleted_at ON reports (deleted_at);
+CREATE INDEX IF NOT EXISTS idx_reports_assignee_id ON reports (assignee_id);
+CREATE INDEX IF NOT EXISTS idx_reports_merged_into_id ON reports (merged_into_id);
+
+CREATE TABLE IF NOT EXISTS report_comments (
+    id         varchar(36) PRIMARY KEY,
+    report_id  varchar(20),
+    author_id  varchar(36) NOT NULL,
+    body       text NOT NULL,
+    created_at timestamptz,
+    CONSTRAINT fk_reports_comments FOREIGN KEY (report_id) REFERENCES reports (id)
+);
+CREATE INDEX IF NOT EXISTS idx_report_comments_report_id ON report_comments (report_id);
+
+CREATE TABLE IF NOT EXISTS report_attachments (
+    id           varchar(36) PRIMARY KEY,
+    report_id    varchar(20),
+    uploader_id  varchar(36) NOT NULL,
+    url          text NOT NULL,
+    content_type varchar(100),
+    created_at   timestamptz,
+    CONSTRAINT fk_reports_attachments FOREIGN KEY (report_id) REFERENCES reports (id)
+);
+CREATE INDEX IF NOT EXISTS idx_report_attachments_report_id ON report_attachments (report_id);
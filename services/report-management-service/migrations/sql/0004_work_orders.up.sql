@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS work_order_links (
+    work_order_id varchar(100) PRIMARY KEY,
+    report_id     varchar(20) NOT NULL,
+    last_state    varchar(50),
+    created_at    timestamptz,
+    updated_at    timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_work_order_links_report_id ON work_order_links (report_id);
+-- Timestamp of the newest work-order event applied to a link, so older
+-- updates delivered late can be dropped.
+ALTER TABLE work_order_links ADD COLUMN IF NOT EXISTS last_event_at timestamptz;
+
+CREATE TABLE IF NOT EXISTS processed_events (
+    event_id     varchar(100) PRIMARY KEY,
+    source       varchar(100) NOT NULL,
+    processed_at timestamptz
+);
@@ -0,0 +1,4 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS preferred_username varchar(255);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locale varchar(20);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone varchar(50);
+CREATE INDEX IF NOT EXISTS idx_users_phone ON users (phone);
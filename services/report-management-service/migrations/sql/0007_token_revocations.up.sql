@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS token_revocations (
+    id         varchar(36) PRIMARY KEY,
+    subject    varchar(36),
+    session_id varchar(100),
+    reason     text,
+    source     varchar(50) NOT NULL,
+    revoked_by varchar(100),
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_token_revocations_subject ON token_revocations (subject);
+CREATE INDEX IF NOT EXISTS idx_token_revocations_session_id ON token_revocations (session_id);
@@ -0,0 +1,15 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status varchar(20) NOT NULL DEFAULT 'ACTIVE';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS suspended_until timestamptz;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS suspension_reason text;
+
+CREATE TABLE IF NOT EXISTS user_moderation_actions (
+    id              varchar(36) PRIMARY KEY,
+    user_id         varchar(36) NOT NULL,
+    action          varchar(20) NOT NULL,
+    reason          text NOT NULL,
+    suspended_until timestamptz,
+    actor_id        varchar(36) NOT NULL,
+    correlation_id  varchar(100),
+    created_at      timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_user_moderation_actions_user_id ON user_moderation_actions (user_id);
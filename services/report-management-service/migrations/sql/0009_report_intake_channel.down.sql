@@ -0,0 +1,2 @@
+ALTER TABLE reports DROP COLUMN IF EXISTS intake_channel;
+ALTER TABLE reports DROP COLUMN IF EXISTS created_by_id;
@@ -0,0 +1,3 @@
+ALTER TABLE reports ADD COLUMN IF NOT EXISTS created_by_id varchar(36);
+ALTER TABLE reports ADD COLUMN IF NOT EXISTS intake_channel varchar(20) NOT NULL DEFAULT 'APP';
+CREATE INDEX IF NOT EXISTS idx_reports_created_by_id ON reports (created_by_id);
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_outbox_events_unpublished ON outbox_events (created_at, id) WHERE published_at IS NULL;
@@ -3,29 +3,118 @@ package models
 import "time"
 
 type ReportCreatedEvent struct {
-	EventID     string    `json:"event_id"`
-	EventType   string    `json:"event_type"`
-	Timestamp   time.Time `json:"timestamp"`
-	ReportID    string    `json:"report_id"`
-	UserID      string    `json:"user_id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Category    string    `json:"category"`
-	Status      string    `json:"status"`
-	Visibility  string    `json:"visibility"`
+	EventID       string    `json:"event_id"`
+	EventType     string    `json:"event_type"`
+	Timestamp     time.Time `json:"timestamp"`
+	ReportID      string    `json:"report_id"`
+	UserID        string    `json:"user_id"`
+	ActorID       string    `json:"actor_id"`
+	ActorClientID string    `json:"actor_client_id,omitempty"`
+	CorrelationID string    `json:"correlation_id"`
+	IntakeChannel string    `json:"intake_channel"`
+	Title         string    `json:"title"`
+	Description   string    `json:"description"`
+	Category      string    `json:"category"`
+	Status        string    `json:"status"`
+	Visibility    string    `json:"visibility"`
 }
 
 type ReportStatusChangedEvent struct {
+	EventID       string                 `json:"event_id"`
+	EventType     string                 `json:"event_type"`
+	Timestamp     time.Time              `json:"timestamp"`
+	ReportID      string                 `json:"report_id"`
+	UserID        string                 `json:"user_id"`
+	ActorID       string                 `json:"actor_id"`
+	ActorClientID string                 `json:"actor_client_id,omitempty"`
+	CorrelationID string                 `json:"correlation_id"`
+	OldStatus     string                 `json:"old_status"`
+	NewStatus     string                 `json:"new_status"`
+	Changes       map[string]FieldChange `json:"changes"`
+}
+
+// FieldChange is one entry of the before/after diff carried by report events.
+type FieldChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// ReportEvent is the envelope for every report mutation other than creation
+// and status changes. Data holds event-specific details such as the comment
+// or attachment that was added.
+type ReportEvent struct {
+	EventID       string                 `json:"event_id"`
+	EventType     string                 `json:"event_type"`
+	Timestamp     time.Time              `json:"timestamp"`
+	ReportID      string                 `json:"report_id"`
+	UserID        string                 `json:"user_id"`
+	ActorID       string                 `json:"actor_id"`
+	ActorClientID string                 `json:"actor_client_id,omitempty"`
+	CorrelationID string                 `json:"correlation_id"`
+	Changes       map[string]FieldChange `json:"changes,omitempty"`
+	Data          interface{}            `json:"data,omitempty"`
+}
+
+// UserModerationEvent is emitted when staff suspend or reinstate a user.
+type UserModerationEvent struct {
+	EventID        string     `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Timestamp      time.Time  `json:"timestamp"`
+	UserID         string     `json:"user_id"`
+	ActorID        string     `json:"actor_id"`
+	ActorClientID  string     `json:"actor_client_id,omitempty"`
+	CorrelationID  string     `json:"correlation_id"`
+	Reason         string     `json:"reason"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+}
+
+type ReportSnapshotEvent struct {
 	EventID   string    `json:"event_id"`
 	EventType string    `json:"event_type"`
 	Timestamp time.Time `json:"timestamp"`
 	ReportID  string    `json:"report_id"`
-	UserID    string    `json:"user_id"`
-	OldStatus string    `json:"old_status"`
-	NewStatus string    `json:"new_status"`
+	Report    Report    `json:"report"`
+}
+
+// WorkOrderUpdatedEvent is published by the field crews' work-order system.
+// ReportID is only set when the work order was raised from a report; later
+// updates are matched through the WorkOrderLink table.
+type WorkOrderUpdatedEvent struct {
+	EventID     string    `json:"event_id"`
+	WorkOrderID string    `json:"work_order_id"`
+	ReportID    string    `json:"report_id,omitempty"`
+	State       string    `json:"state"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// OutboxEvent is the durable record of every domain event the service emits.
+// Rows are written in the same transaction as the change that caused them,
+// so the table doubles as the audit log used for replays.
+type OutboxEvent struct {
+	ID          string     `gorm:"primaryKey;type:varchar(36)" json:"id"`
+	EventType   string     `gorm:"type:varchar(100);not null;index" json:"event_type"`
+	Topic       string     `gorm:"type:varchar(255);not null" json:"topic"`
+	Key         string     `gorm:"type:varchar(255);not null" json:"key"`
+	Payload     []byte     `gorm:"type:jsonb;not null" json:"payload"`
+	CreatedAt   time.Time  `gorm:"index" json:"created_at"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
 }
 
 const (
 	EventTypeReportCreated       = "reports.created"
 	EventTypeReportStatusChanged = "reports.status-changed"
+	EventTypeReportSnapshot      = "reports.snapshot"
+
+	EventTypeReportAssigned        = "reports.assigned"
+	EventTypeReportPriorityChanged = "reports.priority-changed"
+	EventTypeReportCommentAdded    = "reports.comment-added"
+	EventTypeReportAttachmentAdded = "reports.attachment-added"
+	EventTypeReportEdited          = "reports.edited"
+	EventTypeReportWithdrawn       = "reports.withdrawn"
+	EventTypeReportMerged          = "reports.merged"
+	EventTypeReportReopened        = "reports.reopened"
+	EventTypeReportDeleted         = "reports.deleted"
+
+	EventTypeUserSuspended   = "users.suspended"
+	EventTypeUserUnsuspended = "users.unsuspended"
 )
@@ -1,12 +1,18 @@
 package models
 
 import (
+	"strings"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type ReportCategory string
 type ReportStatus string
 type ReportVisibility string
+type ReportPriority string
+type UserStatus string
+type IntakeChannel string
 
 const (
 	CategoryCrime      ReportCategory = "CRIME"
@@ -16,18 +22,82 @@ const (
 	StatusOpen       ReportStatus = "OPEN"
 	StatusInProgress ReportStatus = "IN_PROGRESS"
 	StatusResolved   ReportStatus = "RESOLVED"
+	StatusWithdrawn  ReportStatus = "WITHDRAWN"
+
+	PriorityLow    ReportPriority = "LOW"
+	PriorityNormal ReportPriority = "NORMAL"
+	PriorityHigh   ReportPriority = "HIGH"
+	PriorityUrgent ReportPriority = "URGENT"
 
 	VisibilityPublic    ReportVisibility = "PUBLIC"
 	VisibilityPrivate   ReportVisibility = "PRIVATE"
 	VisibilityAnonymous ReportVisibility = "ANONYMOUS"
+
+	UserStatusActive    UserStatus = "ACTIVE"
+	UserStatusSuspended UserStatus = "SUSPENDED"
+
+	IntakeChannelApp     IntakeChannel = "APP"
+	IntakeChannelPhone   IntakeChannel = "PHONE"
+	IntakeChannelEmail   IntakeChannel = "EMAIL"
+	IntakeChannelWalkIn  IntakeChannel = "WALK_IN"
+	IntakeChannelPartner IntakeChannel = "PARTNER"
 )
 
 type User struct {
-	ID        string    `gorm:"primaryKey;type:varchar(36)" json:"id"`
-	Email     string    `gorm:"uniqueIndex;type:varchar(255);not null" json:"email"`
-	Name      string    `gorm:"type:varchar(255)" json:"name"`
+	ID                string `gorm:"primaryKey;type:varchar(36)" json:"id"`
+	Email             string `gorm:"uniqueIndex;type:varchar(255);not null" json:"email"`
+	Name              string `gorm:"type:varchar(255)" json:"name"`
+	PreferredUsername string `gorm:"type:varchar(255)" json:"preferred_username,omitempty"`
+	Locale            string `gorm:"type:varchar(20)" json:"locale,omitempty"`
+	Phone             string `gorm:"type:varchar(50);index" json:"phone,omitempty"`
+	// Roles are the user's roles as of their last sign-in, kept so
+	// moderation can compare them with the actor's.
+	Roles     []string  `gorm:"type:jsonb;serializer:json;not null;default:'[]'" json:"-"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	// A suspended user is locked out until SuspendedUntil, or indefinitely
+	// when it is nil.
+	Status           UserStatus `gorm:"type:varchar(20);not null;default:ACTIVE" json:"status"`
+	SuspendedUntil   *time.Time `json:"suspended_until,omitempty"`
+	SuspensionReason string     `gorm:"type:text" json:"suspension_reason,omitempty"`
+}
+
+// Suspended reports whether the user is currently locked out.
+func (u *User) Suspended(now time.Time) bool {
+	if u.Status != UserStatusSuspended {
+		return false
+	}
+	return u.SuspendedUntil == nil || now.Before(*u.SuspendedUntil)
+}
+
+// NormalizePhone strips formatting so "+1 (555) 010-0000" and
+// "+15550100000" are stored and matched the same way.
+func NormalizePhone(phone string) string {
+	var b strings.Builder
+	for i, r := range strings.TrimSpace(phone) {
+		if (r >= '0' && r <= '9') || (r == '+' && i == 0) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+const (
+	ModerationActionSuspend   = "SUSPEND"
+	ModerationActionUnsuspend = "UNSUSPEND"
+)
+
+// UserModerationAction is the audit trail of suspensions and their reversal.
+type UserModerationAction struct {
+	ID             string     `gorm:"primaryKey;type:varchar(36)" json:"id"`
+	UserID         string     `gorm:"type:varchar(36);not null;index" json:"user_id"`
+	Action         string     `gorm:"type:varchar(20);not null" json:"action"`
+	Reason         string     `gorm:"type:text;not null" json:"reason"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+	ActorID        string     `gorm:"type:varchar(36);not null" json:"actor_id"`
+	CorrelationID  string     `gorm:"type:varchar(100)" json:"correlation_id,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
 }
 
 type Report struct {
@@ -37,12 +107,22 @@ type Report struct {
 	Category    ReportCategory   `gorm:"type:varchar(50);not null" json:"category"`
 	Status      ReportStatus     `gorm:"type:varchar(50);not null;default:OPEN" json:"status"`
 	Visibility  ReportVisibility `gorm:"type:varchar(50);not null;default:PUBLIC" json:"visibility"`
+	Priority    ReportPriority   `gorm:"type:varchar(20);not null;default:NORMAL" json:"priority"`
 	ImageURL    string           `gorm:"type:text" json:"image_url,omitempty"`
 	CreatedAt   time.Time        `json:"created_at"`
 	UpdatedAt   time.Time        `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt   `gorm:"index" json:"-"`
 
-	UserID  string         `gorm:"type:varchar(36);index" json:"user_id"`
-	Updates []ReportUpdate `gorm:"foreignKey:ReportID" json:"updates,omitempty"`
+	// UserID is the reporter. CreatedByID is whoever filed the report,
+	// which differs when staff file it on a resident's behalf.
+	UserID        string             `gorm:"type:varchar(36);index" json:"user_id"`
+	CreatedByID   string             `gorm:"type:varchar(36);index" json:"created_by_id,omitempty"`
+	IntakeChannel IntakeChannel      `gorm:"type:varchar(20);not null;default:APP" json:"intake_channel"`
+	AssigneeID    string             `gorm:"type:varchar(36);index" json:"assignee_id,omitempty"`
+	MergedIntoID  string             `gorm:"type:varchar(20);index" json:"merged_into_id,omitempty"`
+	Updates       []ReportUpdate     `gorm:"foreignKey:ReportID" json:"updates,omitempty"`
+	Comments      []ReportComment    `gorm:"foreignKey:ReportID" json:"comments,omitempty"`
+	Attachments   []ReportAttachment `gorm:"foreignKey:ReportID" json:"attachments,omitempty"`
 }
 
 type ReportUpdate struct {
@@ -55,3 +135,60 @@ type ReportUpdate struct {
 
 	Report *Report `gorm:"foreignKey:ReportID" json:"report,omitempty"`
 }
+
+type ReportComment struct {
+	ID        string    `gorm:"primaryKey;type:varchar(36)" json:"id"`
+	ReportID  string    `gorm:"type:varchar(20);index" json:"report_id"`
+	AuthorID  string    `gorm:"type:varchar(36);not null" json:"author_id"`
+	Body      string    `gorm:"type:text;not null" json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ReportAttachment struct {
+	ID          string    `gorm:"primaryKey;type:varchar(36)" json:"id"`
+	ReportID    string    `gorm:"type:varchar(20);index" json:"report_id"`
+	UploaderID  string    `gorm:"type:varchar(36);not null" json:"uploader_id"`
+	URL         string    `gorm:"type:text;not null" json:"url"`
+	ContentType string    `gorm:"type:varchar(100)" json:"content_type,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// WorkOrderLink ties an external work order to a report. LastEventAt is the
+// updated_at of the newest work-order event applied through the link.
+type WorkOrderLink struct {
+	WorkOrderID string     `gorm:"primaryKey;type:varchar(100)" json:"work_order_id"`
+	ReportID    string     `gorm:"type:varchar(20);not null;index" json:"report_id"`
+	LastState   string     `gorm:"type:varchar(50)" json:"last_state,omitempty"`
+	LastEventAt *time.Time `json:"last_event_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// ProcessedEvent records inbound events that have already been applied, so
+// redelivered messages are skipped.
+type ProcessedEvent struct {
+	EventID     string    `gorm:"primaryKey;type:varchar(100)" json:"event_id"`
+	Source      string    `gorm:"type:varchar(100);not null" json:"source"`
+	ProcessedAt time.Time `json:"processed_at"`
+}
+
+// RateLimitBucket is a token bucket shared between API instances.
+type RateLimitBucket struct {
+	Key       string    `gorm:"primaryKey;type:varchar(255)"`
+	Tokens    float64   `gorm:"not null"`
+	UpdatedAt time.Time `gorm:"index;not null"`
+}
+
+// TokenRevocation denies API access to a Keycloak subject or session before
+// its tokens expire. A subject revocation rejects tokens issued up to
+// CreatedAt, so the user can sign in again once re-enabled; a session
+// revocation rejects every token carrying that session ID.
+type TokenRevocation struct {
+	ID        string    `gorm:"primaryKey;type:varchar(36)" json:"id"`
+	Subject   string    `gorm:"type:varchar(36);index" json:"subject,omitempty"`
+	SessionID string    `gorm:"type:varchar(100);index" json:"session_id,omitempty"`
+	Reason    string    `gorm:"type:text" json:"reason,omitempty"`
+	Source    string    `gorm:"type:varchar(50);not null" json:"source"`
+	RevokedBy string    `gorm:"type:varchar(100)" json:"revoked_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
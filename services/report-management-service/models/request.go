@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type CreateReportRequest struct {
 	Title       string `json:"title" binding:"required"`
 	Description string `json:"description" binding:"required"`
@@ -7,3 +9,68 @@ type CreateReportRequest struct {
 	Visibility  string `json:"visibility" binding:"required"`
 	ImageURL    string `json:"image_url"`
 }
+
+type UpdateReportRequest struct {
+	Title       *string `json:"title"`
+	Description *string `json:"description"`
+	Category    *string `json:"category"`
+	Visibility  *string `json:"visibility"`
+}
+
+type AssignReportRequest struct {
+	AssigneeID string `json:"assignee_id"`
+}
+
+type UpdatePriorityRequest struct {
+	Priority string `json:"priority" binding:"required"`
+}
+
+type AddCommentRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+type AddAttachmentRequest struct {
+	URL         string `json:"url" binding:"required"`
+	ContentType string `json:"content_type"`
+}
+
+type MergeReportRequest struct {
+	TargetReportID string `json:"target_report_id" binding:"required"`
+}
+
+type LinkWorkOrderRequest struct {
+	WorkOrderID string `json:"work_order_id" binding:"required"`
+}
+
+// RevokeTokensRequest revokes either every token of a subject or one session.
+type RevokeTokensRequest struct {
+	Subject   string `json:"subject"`
+	SessionID string `json:"session_id"`
+	Reason    string `json:"reason"`
+}
+
+// SuspendUserRequest suspends a user until Until, or indefinitely when it is
+// omitted.
+type SuspendUserRequest struct {
+	Reason string     `json:"reason" binding:"required"`
+	Until  *time.Time `json:"until"`
+}
+
+type UnsuspendUserRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// ReporterRequest identifies the resident a report is filed for. Email is
+// matched first, then phone; a new citizen record is created when neither
+// matches.
+type ReporterRequest struct {
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+	Name  string `json:"name"`
+}
+
+type CreateReportOnBehalfRequest struct {
+	CreateReportRequest
+	Reporter ReporterRequest `json:"reporter" binding:"required"`
+	Channel  string          `json:"channel" binding:"required"`
+}
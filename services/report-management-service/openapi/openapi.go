@@ -0,0 +1,148 @@
+// Package openapi serves the API's OpenAPI 3 document and checks live
+// responses against it. The document is openapi.yaml, compiled into the
+// binary; the mobile client generates its API types from the same file.
+package openapi
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gin-gonic/gin"
+
+	"reportmaxxing/services/report-management-service/logging"
+)
+
+//go:embed openapi.yaml
+var spec []byte
+
+// Config controls the docs routes and response validation. Validation
+// buffers every response body, so leave it off in production.
+type Config struct {
+	DocsEnabled       bool
+	ValidateResponses bool
+}
+
+// Load parses the embedded document and checks it is valid OpenAPI.
+func Load() (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(spec)
+	if err != nil {
+		return nil, err
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// SpecHandler serves doc as JSON.
+func SpecHandler(doc *openapi3.T) (gin.HandlerFunc, error) {
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+	}, nil
+}
+
+// DocsHandler serves Swagger UI for the document at specURL. The UI itself
+// is loaded from a CDN.
+func DocsHandler(specURL string) gin.HandlerFunc {
+	page := strings.ReplaceAll(docsPage, "{{SPEC_URL}}", specURL)
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(page))
+	}
+}
+
+const docsPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Report Management API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = function () {
+      SwaggerUIBundle({ url: "{{SPEC_URL}}", dom_id: "#swagger-ui", persistAuthorization: true });
+    };
+  </script>
+</body>
+</html>
+`
+
+// ValidateResponses checks every response to a documented route against
+// doc and logs a warning for each one that does not match: an undocumented
+// status code, a missing or mistyped field, a value outside an enum.
+// Responses are sent unchanged either way. Requests to routes the document
+// doesn't describe are not checked.
+func ValidateResponses(doc *openapi3.T) (gin.HandlerFunc, error) {
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(c *gin.Context) {
+		route, pathParams, err := router.FindRoute(c.Request)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		recorder := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		header := c.Writer.Header()
+		input := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: &openapi3filter.RequestValidationInput{
+				Request:    c.Request,
+				PathParams: pathParams,
+				Route:      route,
+			},
+			Status: c.Writer.Status(),
+			Header: header,
+			Options: &openapi3filter.Options{
+				IncludeResponseStatus: true,
+				MultiError:            true,
+				// Only JSON bodies are described in enough detail to check.
+				ExcludeResponseBody: !strings.HasPrefix(header.Get("Content-Type"), "application/json"),
+			},
+		}
+		input.SetBodyBytes(recorder.body.Bytes())
+
+		ctx := c.Request.Context()
+		if err := openapi3filter.ValidateResponse(ctx, input); err != nil {
+			logging.FromContext(ctx).Warn("openapi: response does not match the spec",
+				"operation", route.Operation.OperationID,
+				"status", c.Writer.Status(),
+				"error", err,
+			)
+		}
+	}, nil
+}
+
+// bodyRecorder keeps a copy of the response body for validation.
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,263 @@
+package policy
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"reportmaxxing/services/report-management-service/models"
+)
+
+type Scope int
+
+const (
+	ScopeNone Scope = iota
+	ScopeOwn
+	ScopeDepartment
+	ScopeAny
+)
+
+const (
+	ActionReportCreate         = "report:create"
+	ActionReportCreateOnBehalf = "report:create-on-behalf"
+	ActionUploadCreate         = "upload:create"
+	ActionReportRead           = "report:read"
+	ActionReportReadPrivate    = "report:read-private"
+	ActionReportUpdate         = "report:update"
+	ActionReportWithdraw       = "report:withdraw"
+	ActionReportComment        = "report:comment"
+	ActionReportAttach         = "report:attach"
+	ActionReportStatusUpdate   = "report:status:update"
+	ActionReportAssign         = "report:assign"
+	ActionReportPriorityUpdate = "report:priority:update"
+	ActionReportMerge          = "report:merge"
+	ActionReportReopen         = "report:reopen"
+	ActionReportDelete         = "report:delete"
+	ActionWorkOrderLink        = "workorder:link"
+	ActionSessionRevoke        = "session:revoke"
+	ActionUserSuspend          = "user:suspend"
+
+	wildcard = "*"
+)
+
+var scopeNames = map[string]Scope{
+	"own":        ScopeOwn,
+	"department": ScopeDepartment,
+	"any":        ScopeAny,
+}
+
+//go:embed default_policy.yaml
+var defaultPolicy []byte
+
+// Principal is the caller a decision is made for. ClientID is only set for
+// service accounts, which are granted the client's permissions on top of
+// their roles.
+type Principal struct {
+	UserID      string
+	ClientID    string
+	Roles       []string
+	Departments []string
+}
+
+// Policy maps roles, and service-account clients, to the actions they may
+// perform and at which scope.
+type Policy struct {
+	grants       map[string]map[string]Scope
+	clientGrants map[string]map[string]Scope
+}
+
+type policyFile struct {
+	Roles   map[string][]string `yaml:"roles"`
+	Clients map[string][]string `yaml:"clients"`
+}
+
+// Load reads a policy file, or the built-in default policy when path is
+// empty.
+func Load(path string) (*Policy, error) {
+	data := defaultPolicy
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, fmt.Errorf("failed to read policy file: %w", err)
+		}
+	}
+	return Parse(data)
+}
+
+func Parse(data []byte) (*Policy, error) {
+	var file policyFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid policy: %w", err)
+	}
+	if len(file.Roles) == 0 {
+		return nil, fmt.Errorf("invalid policy: no roles defined")
+	}
+
+	p := &Policy{
+		grants:       make(map[string]map[string]Scope),
+		clientGrants: make(map[string]map[string]Scope),
+	}
+	for role, permissions := range file.Roles {
+		grants, err := parseGrants(permissions)
+		if err != nil {
+			return nil, fmt.Errorf("invalid policy: role %s: %w", role, err)
+		}
+		p.grants[role] = grants
+	}
+	for client, permissions := range file.Clients {
+		grants, err := parseGrants(permissions)
+		if err != nil {
+			return nil, fmt.Errorf("invalid policy: client %s: %w", client, err)
+		}
+		p.clientGrants[client] = grants
+	}
+	return p, nil
+}
+
+func parseGrants(permissions []string) (map[string]Scope, error) {
+	grants := make(map[string]Scope)
+	for _, permission := range permissions {
+		action, scope, err := parsePermission(permission)
+		if err != nil {
+			return nil, err
+		}
+		if scope > grants[action] {
+			grants[action] = scope
+		}
+	}
+	return grants, nil
+}
+
+// grantSets returns the grants that apply to the principal: one per role,
+// plus the client's when it is a service account.
+func (p *Policy) grantSets(principal Principal) []map[string]Scope {
+	sets := make([]map[string]Scope, 0, len(principal.Roles)+1)
+	for _, role := range principal.Roles {
+		if grants, ok := p.grants[role]; ok {
+			sets = append(sets, grants)
+		}
+	}
+	if principal.ClientID != "" {
+		if grants, ok := p.clientGrants[principal.ClientID]; ok {
+			sets = append(sets, grants)
+		}
+	}
+	return sets
+}
+
+// parsePermission splits "report:read:own" into its action and scope.
+// Permissions without a scope suffix are granted at ScopeAny.
+func parsePermission(permission string) (string, Scope, error) {
+	permission = strings.TrimSpace(permission)
+	if permission == "" {
+		return "", ScopeNone, fmt.Errorf("empty permission")
+	}
+	if permission == wildcard {
+		return wildcard, ScopeAny, nil
+	}
+
+	if i := strings.LastIndex(permission, ":"); i > 0 {
+		if scope, ok := scopeNames[permission[i+1:]]; ok {
+			return permission[:i], scope, nil
+		}
+	}
+	if !strings.Contains(permission, ":") {
+		return "", ScopeNone, fmt.Errorf("permission %q must look like resource:action", permission)
+	}
+	return permission, ScopeAny, nil
+}
+
+// Scope returns the broadest scope at which the principal may perform action.
+func (p *Policy) Scope(principal Principal, action string) Scope {
+	best := ScopeNone
+	for _, grants := range p.grantSets(principal) {
+		if grants[wildcard] > best {
+			best = grants[wildcard]
+		}
+		if grants[action] > best {
+			best = grants[action]
+		}
+	}
+	return best
+}
+
+// Allowed reports whether the principal may perform action at all, for
+// actions that do not target an existing report.
+func (p *Policy) Allowed(principal Principal, action string) bool {
+	return p.Scope(principal, action) != ScopeNone
+}
+
+// AllowedOn reports whether the principal may perform action on report.
+func (p *Policy) AllowedOn(principal Principal, action string, report *models.Report) bool {
+	switch p.Scope(principal, action) {
+	case ScopeAny:
+		return true
+	case ScopeDepartment:
+		if InDepartmentScope(principal.Departments, string(report.Category)) {
+			return true
+		}
+	case ScopeNone:
+		return false
+	}
+	return ownReport(principal, report)
+}
+
+// ownReport reports whether the report is the principal's: filed for them,
+// or filed by them on someone's behalf.
+func ownReport(principal Principal, report *models.Report) bool {
+	return principal.UserID != "" && (report.UserID == principal.UserID || report.CreatedByID == principal.UserID)
+}
+
+// Permissions lists the permissions granted to the principal, for display in
+// the profile.
+func (p *Policy) Permissions(principal Principal) []string {
+	scopes := map[Scope]string{ScopeOwn: "own", ScopeDepartment: "department", ScopeAny: "any"}
+	var permissions []string
+	seen := make(map[string]bool)
+	for _, grants := range p.grantSets(principal) {
+		for action := range grants {
+			scope := p.Scope(principal, action)
+			permission := action
+			if action != wildcard {
+				permission = action + ":" + scopes[scope]
+			}
+			if !seen[permission] {
+				seen[permission] = true
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	sort.Strings(permissions)
+	return permissions
+}
+
+// roleRanks orders the built-in roles by privilege, for moderation. Roles
+// not listed rank with CITIZEN.
+var roleRanks = map[string]int{
+	"AUDITOR":          1,
+	"DEPARTMENT_STAFF": 2,
+	"SUPERVISOR":       3,
+	"ADMIN":            4,
+}
+
+// RoleRank returns the rank of the most privileged of roles.
+func RoleRank(roles []string) int {
+	rank := 0
+	for _, role := range roles {
+		if roleRanks[role] > rank {
+			rank = roleRanks[role]
+		}
+	}
+	return rank
+}
+
+// InDepartmentScope reports whether a caller with the given departments may
+// see reports in category. Callers without any department see none.
+func InDepartmentScope(departments []string, category string) bool {
+	return slices.Contains(departments, category)
+}
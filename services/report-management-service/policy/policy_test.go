@@ -0,0 +1,207 @@
+package policy
+
+import (
+	"slices"
+	"testing"
+
+	"reportmaxxing/services/report-management-service/models"
+)
+
+func TestParsePermission(t *testing.T) {
+	tests := []struct {
+		permission string
+		action     string
+		scope      Scope
+		wantErr    bool
+	}{
+		{permission: "report:read:own", action: "report:read", scope: ScopeOwn},
+		{permission: "report:read:department", action: "report:read", scope: ScopeDepartment},
+		{permission: "report:read:any", action: "report:read", scope: ScopeAny},
+		{permission: "report:status:update:department", action: "report:status:update", scope: ScopeDepartment},
+		{permission: "report:create", action: "report:create", scope: ScopeAny},
+		{permission: " session:revoke ", action: "session:revoke", scope: ScopeAny},
+		{permission: "*", action: "*", scope: ScopeAny},
+		{permission: "", wantErr: true},
+		{permission: "report", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.permission, func(t *testing.T) {
+			action, scope, err := parsePermission(tt.permission)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parsePermission(%q) = %q, %v; want an error", tt.permission, action, scope)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parsePermission(%q): %v", tt.permission, err)
+			}
+			if action != tt.action || scope != tt.scope {
+				t.Errorf("parsePermission(%q) = %q, %v; want %q, %v", tt.permission, action, scope, tt.action, tt.scope)
+			}
+		})
+	}
+}
+
+func TestParseRejectsInvalidPolicies(t *testing.T) {
+	tests := map[string]string{
+		"not yaml":       "roles: [",
+		"no roles":       "clients:\n  dashboard: [report:read:any]\n",
+		"bad role grant": "roles:\n  CITIZEN: [report]\n",
+		"bad client":     "roles:\n  CITIZEN: [report:read:own]\nclients:\n  dashboard: ['']\n",
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Parse([]byte(data)); err == nil {
+				t.Fatal("Parse succeeded, want an error")
+			}
+		})
+	}
+}
+
+const testPolicy = `
+roles:
+  CITIZEN:
+    - report:read:own
+    - report:create
+  STAFF:
+    - report:read:department
+    - report:assign:department
+  SUPERVISOR:
+    - report:read:any
+    - report:read:own
+  ADMIN:
+    - "*"
+clients:
+  dashboard:
+    - report:read:any
+`
+
+func loadTestPolicy(t *testing.T) *Policy {
+	t.Helper()
+	p, err := Parse([]byte(testPolicy))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return p
+}
+
+func TestScope(t *testing.T) {
+	p := loadTestPolicy(t)
+
+	tests := []struct {
+		name      string
+		principal Principal
+		action    string
+		want      Scope
+	}{
+		{name: "no roles", principal: Principal{}, action: ActionReportRead, want: ScopeNone},
+		{name: "unknown role", principal: Principal{Roles: []string{"GUEST"}}, action: ActionReportRead, want: ScopeNone},
+		{name: "role without action", principal: Principal{Roles: []string{"CITIZEN"}}, action: ActionReportAssign, want: ScopeNone},
+		{name: "own", principal: Principal{Roles: []string{"CITIZEN"}}, action: ActionReportRead, want: ScopeOwn},
+		{name: "unscoped permission", principal: Principal{Roles: []string{"CITIZEN"}}, action: ActionReportCreate, want: ScopeAny},
+		{name: "department", principal: Principal{Roles: []string{"STAFF"}}, action: ActionReportRead, want: ScopeDepartment},
+		{name: "broadest grant in a role wins", principal: Principal{Roles: []string{"SUPERVISOR"}}, action: ActionReportRead, want: ScopeAny},
+		{name: "broadest role wins", principal: Principal{Roles: []string{"CITIZEN", "STAFF"}}, action: ActionReportRead, want: ScopeDepartment},
+		{name: "wildcard", principal: Principal{Roles: []string{"ADMIN"}}, action: ActionUserSuspend, want: ScopeAny},
+		{name: "client grants", principal: Principal{ClientID: "dashboard"}, action: ActionReportRead, want: ScopeAny},
+		{name: "client grants add to roles", principal: Principal{ClientID: "dashboard", Roles: []string{"CITIZEN"}}, action: ActionReportRead, want: ScopeAny},
+		{name: "unknown client", principal: Principal{ClientID: "other"}, action: ActionReportRead, want: ScopeNone},
+		{name: "client ID is not a role", principal: Principal{Roles: []string{"dashboard"}}, action: ActionReportRead, want: ScopeNone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.Scope(tt.principal, tt.action); got != tt.want {
+				t.Errorf("Scope = %v, want %v", got, tt.want)
+			}
+			if got := p.Allowed(tt.principal, tt.action); got != (tt.want != ScopeNone) {
+				t.Errorf("Allowed = %v, want %v", got, tt.want != ScopeNone)
+			}
+		})
+	}
+}
+
+func TestAllowedOn(t *testing.T) {
+	p := loadTestPolicy(t)
+
+	own := &models.Report{UserID: "u1", Category: models.CategorySanitation}
+	sanitation := &models.Report{UserID: "u2", Category: models.CategorySanitation}
+	crime := &models.Report{UserID: "u2", Category: models.CategoryCrime}
+
+	citizen := Principal{UserID: "u1", Roles: []string{"CITIZEN"}}
+	staff := Principal{UserID: "u1", Roles: []string{"STAFF"}, Departments: []string{"SANITATION"}}
+	unscopedStaff := Principal{UserID: "u3", Roles: []string{"STAFF"}}
+	supervisor := Principal{UserID: "u3", Roles: []string{"SUPERVISOR"}}
+
+	tests := []struct {
+		name      string
+		principal Principal
+		action    string
+		report    *models.Report
+		want      bool
+	}{
+		{name: "own scope, own report", principal: citizen, action: ActionReportRead, report: own, want: true},
+		{name: "own scope, other's report", principal: citizen, action: ActionReportRead, report: sanitation, want: false},
+		{name: "own scope, filed on someone's behalf", principal: citizen, action: ActionReportRead, report: &models.Report{UserID: "u2", CreatedByID: "u1"}, want: true},
+		{name: "no grant, own report", principal: citizen, action: ActionReportAssign, report: own, want: false},
+		{name: "department scope, in department", principal: staff, action: ActionReportRead, report: sanitation, want: true},
+		{name: "department scope, other department", principal: staff, action: ActionReportRead, report: crime, want: false},
+		{name: "department scope falls back to own", principal: staff, action: ActionReportRead, report: &models.Report{UserID: "u1", Category: models.CategoryCrime}, want: true},
+		{name: "department scope without departments", principal: unscopedStaff, action: ActionReportAssign, report: crime, want: false},
+		{name: "department scope without departments, own report", principal: unscopedStaff, action: ActionReportRead, report: &models.Report{UserID: "u3", Category: models.CategoryCrime}, want: true},
+		{name: "any scope", principal: supervisor, action: ActionReportRead, report: crime, want: true},
+		{name: "any scope, other action", principal: supervisor, action: ActionReportAssign, report: crime, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := p.AllowedOn(tt.principal, tt.action, tt.report); got != tt.want {
+				t.Errorf("AllowedOn = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPermissions(t *testing.T) {
+	p := loadTestPolicy(t)
+
+	got := p.Permissions(Principal{ClientID: "dashboard", Roles: []string{"CITIZEN", "STAFF"}})
+	want := []string{"report:assign:department", "report:create:any", "report:read:any"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Permissions = %v, want %v", got, want)
+	}
+}
+
+// The shipped policy is checked against the grants the README documents.
+func TestDefaultPolicy(t *testing.T) {
+	p, err := Load("")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	tests := []struct {
+		role   string
+		action string
+		want   Scope
+	}{
+		{role: "CITIZEN", action: ActionReportRead, want: ScopeOwn},
+		{role: "CITIZEN", action: ActionReportDelete, want: ScopeNone},
+		{role: "DEPARTMENT_STAFF", action: ActionReportRead, want: ScopeDepartment},
+		{role: "DEPARTMENT_STAFF", action: ActionReportDelete, want: ScopeDepartment},
+		{role: "DEPARTMENT_STAFF", action: ActionUserSuspend, want: ScopeNone},
+		{role: "SUPERVISOR", action: ActionUserSuspend, want: ScopeAny},
+		{role: "SUPERVISOR", action: ActionReportRead, want: ScopeAny},
+		{role: "SUPERVISOR", action: ActionReportDelete, want: ScopeDepartment},
+		{role: "SUPERVISOR", action: ActionReportReadPrivate, want: ScopeNone},
+		{role: "AUDITOR", action: ActionReportRead, want: ScopeAny},
+		{role: "AUDITOR", action: ActionReportReadPrivate, want: ScopeAny},
+		{role: "AUDITOR", action: ActionReportUpdate, want: ScopeNone},
+		{role: "ADMIN", action: ActionSessionRevoke, want: ScopeAny},
+	}
+	for _, tt := range tests {
+		t.Run(tt.role+" "+tt.action, func(t *testing.T) {
+			if got := p.Scope(Principal{Roles: []string{tt.role}}, tt.action); got != tt.want {
+				t.Errorf("Scope = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit is a token bucket that holds up to Requests tokens and refills them
+// evenly over Period, e.g. 10 per hour allows a burst of 10 and then one
+// every six minutes.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+var periodUnits = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+	"d": 24 * time.Hour,
+}
+
+// ParseLimit parses limits written as "<requests>/<unit>" where unit is s, m,
+// h or d, e.g. "20/h". "off" or "0" disables the limit.
+func ParseLimit(s string) (Limit, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	if s == "" || s == "off" || s == "0" {
+		return Limit{}, nil
+	}
+
+	count, unit, ok := strings.Cut(s, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("rate limit %q must look like <requests>/<s|m|h|d>", s)
+	}
+	requests, err := strconv.Atoi(count)
+	if err != nil || requests < 0 {
+		return Limit{}, fmt.Errorf("rate limit %q has an invalid request count", s)
+	}
+	period, ok := periodUnits[unit]
+	if !ok {
+		return Limit{}, fmt.Errorf("rate limit %q has an unknown unit %q", s, unit)
+	}
+	return Limit{Requests: requests, Period: period}, nil
+}
+
+func (l Limit) Enabled() bool {
+	return l.Requests > 0 && l.Period > 0
+}
+
+func (l Limit) String() string {
+	if !l.Enabled() {
+		return "off"
+	}
+	for unit, period := range periodUnits {
+		if l.Period == period {
+			return fmt.Sprintf("%d/%s", l.Requests, unit)
+		}
+	}
+	return fmt.Sprintf("%d/%s", l.Requests, l.Period)
+}
+
+// Result is the outcome of taking a token.
+type Result struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+// Store keeps token buckets. Take consumes one token from the bucket for
+// key if one is available.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// bucket is the state shared by the stores; take refills it for the time
+// elapsed since it was last updated and consumes a token if it can.
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+func newBucket(limit Limit, now time.Time) bucket {
+	return bucket{tokens: float64(limit.Requests), updatedAt: now}
+}
+
+func (b *bucket) take(limit Limit, now time.Time) Result {
+	rate := float64(limit.Requests) / limit.Period.Seconds()
+	if elapsed := now.Sub(b.updatedAt).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(float64(limit.Requests), b.tokens+elapsed*rate)
+	}
+	b.updatedAt = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return Result{Allowed: true, Remaining: int(b.tokens)}
+	}
+
+	wait := time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	return Result{Allowed: false, RetryAfter: wait}
+}
+
+// idle reports whether the bucket has been full for at least the limit's
+// period, so dropping it loses nothing.
+func (b *bucket) idle(limit Limit, now time.Time) bool {
+	return now.Sub(b.updatedAt) > limit.Period
+}
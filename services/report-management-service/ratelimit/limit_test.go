@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Limit
+		wantErr bool
+	}{
+		{in: "20/h", want: Limit{Requests: 20, Period: time.Hour}},
+		{in: " 5/S ", want: Limit{Requests: 5, Period: time.Second}},
+		{in: "100/d", want: Limit{Requests: 100, Period: 24 * time.Hour}},
+		{in: "off"},
+		{in: "0"},
+		{in: ""},
+		{in: "20", wantErr: true},
+		{in: "x/h", wantErr: true},
+		{in: "-1/h", wantErr: true},
+		{in: "20/w", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseLimit(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseLimit(%q): err = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseLimit(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestBucketRefillsEvenly(t *testing.T) {
+	limit := Limit{Requests: 10, Period: time.Hour}
+	now := time.Now()
+	b := newBucket(limit, now)
+
+	for i := 9; i >= 0; i-- {
+		if result := b.take(limit, now); !result.Allowed || result.Remaining != i {
+			t.Fatalf("take = %+v, want allowed with %d remaining", result, i)
+		}
+	}
+	result := b.take(limit, now)
+	if result.Allowed || result.RetryAfter != 6*time.Minute {
+		t.Fatalf("take on an empty bucket = %+v, want a retry after 6m", result)
+	}
+
+	// One token comes back every six minutes, and never more than the limit.
+	if result := b.take(limit, now.Add(6*time.Minute)); !result.Allowed || result.Remaining != 0 {
+		t.Errorf("take after 6m = %+v, want one token", result)
+	}
+	if result := b.take(limit, now.Add(48*time.Hour)); result.Remaining != 9 {
+		t.Errorf("take after two days = %+v, want a full bucket less one", result)
+	}
+}
@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const memorySweepInterval = time.Minute
+
+type memoryEntry struct {
+	bucket bucket
+	limit  Limit
+}
+
+// MemoryStore keeps buckets in process. Limits are per instance, so use it
+// only when a single API instance is running.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*memoryEntry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*memoryEntry),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	entry, ok := s.buckets[key]
+	if !ok {
+		entry = &memoryEntry{bucket: newBucket(limit, now)}
+		s.buckets[key] = entry
+	}
+	entry.limit = limit
+	return entry.bucket.take(limit, now), nil
+}
+
+// sweep drops buckets that have refilled completely. Callers hold s.mu.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < memorySweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, entry := range s.buckets {
+		if entry.bucket.idle(entry.limit, now) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreKeysAndSweeps(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	limit := Limit{Requests: 1, Period: time.Minute}
+	ctx := context.Background()
+
+	if result, _ := store.Take(ctx, "a", limit); !result.Allowed {
+		t.Fatal("first take on a rejected")
+	}
+	if result, _ := store.Take(ctx, "a", limit); result.Allowed {
+		t.Error("second take on a allowed")
+	}
+	if result, _ := store.Take(ctx, "b", limit); !result.Allowed {
+		t.Error("take on b counted against a")
+	}
+
+	now = now.Add(2 * time.Minute)
+	store.Take(ctx, "c", limit)
+	if _, ok := store.buckets["a"]; ok {
+		t.Error("refilled bucket a not swept")
+	}
+	if _, ok := store.buckets["c"]; !ok {
+		t.Error("bucket c missing")
+	}
+}
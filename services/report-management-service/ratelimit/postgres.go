@@ -0,0 +1,44 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"reportmaxxing/services/report-management-service/repository"
+)
+
+// PostgresStore keeps buckets in the rate_limit_buckets table so every API
+// instance shares the same limits. Each Take locks the bucket's row for the
+// duration of a short transaction.
+type PostgresStore struct {
+	store repository.Store
+}
+
+func NewPostgresStore(store repository.Store) *PostgresStore {
+	return &PostgresStore{store: store}
+}
+
+func (s *PostgresStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	var result Result
+	err := s.store.Transaction(ctx, func(tx repository.Store) error {
+		now := time.Now()
+
+		row, err := tx.RateLimits().Lock(ctx, key, float64(limit.Requests), now)
+		if err != nil {
+			return err
+		}
+
+		b := bucket{tokens: row.Tokens, updatedAt: row.UpdatedAt}
+		result = b.take(limit, now)
+
+		row.Tokens, row.UpdatedAt = b.tokens, b.updatedAt
+		return tx.RateLimits().Save(ctx, row)
+	})
+	return result, err
+}
+
+// Prune deletes buckets that have not been touched for longer than idle.
+// Pass the longest configured period so no partially drained bucket is lost.
+func (s *PostgresStore) Prune(ctx context.Context, idle time.Duration) (int64, error) {
+	return s.store.RateLimits().Prune(ctx, time.Now().Add(-idle))
+}
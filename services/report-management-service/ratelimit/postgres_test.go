@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"reportmaxxing/services/report-management-service/models"
+	"reportmaxxing/services/report-management-service/repository"
+)
+
+// fakeStore keeps rate-limit buckets in memory. The other repositories are
+// left nil, so a call to one fails the test with a panic.
+type fakeStore struct {
+	repository.Store
+	buckets map[string]models.RateLimitBucket
+	saveErr error
+}
+
+func (s *fakeStore) RateLimits() repository.RateLimits { return fakeRateLimits{s: s} }
+
+func (s *fakeStore) Transaction(ctx context.Context, fn func(tx repository.Store) error) error {
+	saved := make(map[string]models.RateLimitBucket, len(s.buckets))
+	for key, bucket := range s.buckets {
+		saved[key] = bucket
+	}
+	if err := fn(s); err != nil {
+		s.buckets = saved
+		return err
+	}
+	return nil
+}
+
+type fakeRateLimits struct {
+	repository.RateLimits
+	s *fakeStore
+}
+
+func (r fakeRateLimits) Lock(ctx context.Context, key string, tokens float64, now time.Time) (*models.RateLimitBucket, error) {
+	bucket, ok := r.s.buckets[key]
+	if !ok {
+		bucket = models.RateLimitBucket{Key: key, Tokens: tokens, UpdatedAt: now}
+		r.s.buckets[key] = bucket
+	}
+	return &bucket, nil
+}
+
+func (r fakeRateLimits) Save(ctx context.Context, bucket *models.RateLimitBucket) error {
+	if r.s.saveErr != nil {
+		return r.s.saveErr
+	}
+	r.s.buckets[bucket.Key] = *bucket
+	return nil
+}
+
+func (r fakeRateLimits) Prune(ctx context.Context, idleSince time.Time) (int64, error) {
+	var pruned int64
+	for key, bucket := range r.s.buckets {
+		if bucket.UpdatedAt.Before(idleSince) {
+			delete(r.s.buckets, key)
+			pruned++
+		}
+	}
+	return pruned, nil
+}
+
+func TestPostgresStoreTake(t *testing.T) {
+	store := &fakeStore{buckets: map[string]models.RateLimitBucket{}}
+	limiter := NewPostgresStore(store)
+	limit := Limit{Requests: 2, Period: time.Hour}
+	ctx := context.Background()
+
+	for i, want := range []bool{true, true, false} {
+		result, err := limiter.Take(ctx, "user:1", limit)
+		if err != nil {
+			t.Fatalf("Take #%d: %v", i+1, err)
+		}
+		if result.Allowed != want {
+			t.Errorf("Take #%d allowed = %v, want %v", i+1, result.Allowed, want)
+		}
+	}
+	if tokens := store.buckets["user:1"].Tokens; tokens >= 1 {
+		t.Errorf("stored bucket has %v tokens, want it drained", tokens)
+	}
+
+	// A failed write rolls the new bucket back.
+	errWrite := errors.New("write failed")
+	store.saveErr = errWrite
+	if _, err := limiter.Take(ctx, "user:2", limit); !errors.Is(err, errWrite) {
+		t.Fatalf("Take with a failing write: err = %v, want %v", err, errWrite)
+	}
+	if _, ok := store.buckets["user:2"]; ok {
+		t.Error("bucket kept after the transaction failed")
+	}
+	store.saveErr = nil
+
+	pruned, err := limiter.Prune(ctx, -time.Minute)
+	if err != nil || pruned != 1 {
+		t.Errorf("Prune = %d, %v; want the one bucket pruned", pruned, err)
+	}
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// GormStore is the Postgres implementation of Store.
+type GormStore struct {
+	db *gorm.DB
+}
+
+func NewGormStore(db *gorm.DB) *GormStore {
+	return &GormStore{db: db}
+}
+
+func (s *GormStore) Reports() Reports         { return gormReports{s.db} }
+func (s *GormStore) Updates() Updates         { return gormUpdates{s.db} }
+func (s *GormStore) Comments() Comments       { return gormComments{s.db} }
+func (s *GormStore) Attachments() Attachments { return gormAttachments{s.db} }
+func (s *GormStore) Users() Users             { return gormUsers{s.db} }
+func (s *GormStore) Outbox() Outbox           { return gormOutbox{s.db} }
+func (s *GormStore) WorkOrders() WorkOrders   { return gormWorkOrders{s.db} }
+func (s *GormStore) ProcessedEvents() ProcessedEvents {
+	return gormProcessedEvents{s.db}
+}
+func (s *GormStore) Revocations() Revocations { return gormRevocations{s.db} }
+func (s *GormStore) RateLimits() RateLimits   { return gormRateLimits{s.db} }
+
+func (s *GormStore) Transaction(ctx context.Context, fn func(tx Store) error) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&GormStore{db: tx})
+	})
+}
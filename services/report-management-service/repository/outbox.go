@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"reportmaxxing/services/report-management-service/models"
+)
+
+type gormOutbox struct {
+	db *gorm.DB
+}
+
+func (r gormOutbox) Create(ctx context.Context, event *models.OutboxEvent) error {
+	return r.db.WithContext(ctx).Create(event).Error
+}
+
+func (r gormOutbox) MarkPublished(ctx context.Context, event *models.OutboxEvent) error {
+	return r.db.WithContext(ctx).Model(event).Update("published_at", time.Now()).Error
+}
+
+func (r gormOutbox) ClaimUnpublished(ctx context.Context, createdBefore time.Time, limit int) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	err := r.db.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("published_at IS NULL AND created_at < ?", createdBefore).
+		Order("created_at, id").
+		Limit(limit).
+		Find(&events).Error
+	return events, err
+}
+
+func (r gormOutbox) List(ctx context.Context, filter OutboxFilter, limit int) ([]models.OutboxEvent, error) {
+	query := r.db.WithContext(ctx).Where("created_at >= ?", filter.From)
+	if !filter.To.IsZero() {
+		query = query.Where("created_at < ?", filter.To)
+	}
+	if len(filter.EventTypes) > 0 {
+		query = query.Where("event_type IN ?", filter.EventTypes)
+	}
+	if filter.AfterID != "" {
+		query = query.Where("(created_at, id) > (?, ?)", filter.AfterCreatedAt, filter.AfterID)
+	}
+
+	var events []models.OutboxEvent
+	err := query.Order("created_at, id").Limit(limit).Find(&events).Error
+	return events, err
+}
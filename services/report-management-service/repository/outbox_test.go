@@ -0,0 +1,188 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"reportmaxxing/services/report-management-service/kafka"
+	"reportmaxxing/services/report-management-service/models"
+	"reportmaxxing/services/report-management-service/repository"
+	"reportmaxxing/services/report-management-service/repository/repotest"
+)
+
+func newOutboxEvent(reportID string) *models.OutboxEvent {
+	return &models.OutboxEvent{
+		ID:        uuid.New().String(),
+		EventType: models.EventTypeReportCreated,
+		Topic:     kafka.ReportsCreatedTopic,
+		Key:       reportID,
+		Payload:   []byte(`{"report_id":"` + reportID + `"}`),
+		CreatedAt: time.Now(),
+	}
+}
+
+// The outbox row is only as durable as the change that produced it: a
+// failed transaction must leave neither behind.
+func TestOutboxRollsBackWithTheTransaction(t *testing.T) {
+	t.Parallel()
+	db := repotest.OpenDB(t)
+	store := repository.NewGormStore(db)
+	ctx := context.Background()
+
+	user := repotest.CreateUser(t, store)
+	report := &models.Report{
+		ID: "T-ROLLBACK", Title: "Graffiti", Description: "On the bridge", Category: models.CategorySanitation,
+		Status: models.StatusOpen, Visibility: models.VisibilityPublic, Priority: models.PriorityNormal,
+		IntakeChannel: models.IntakeChannelApp, UserID: user.ID,
+	}
+	event := newOutboxEvent(report.ID)
+
+	errPublish := errors.New("validation failed after the writes")
+	err := store.Transaction(ctx, func(tx repository.Store) error {
+		if err := tx.Reports().Create(ctx, report); err != nil {
+			return err
+		}
+		if err := tx.Outbox().Create(ctx, event); err != nil {
+			return err
+		}
+		return errPublish
+	})
+	if !errors.Is(err, errPublish) {
+		t.Fatalf("Transaction: err = %v, want the callback's error", err)
+	}
+
+	if _, err := store.Reports().Get(ctx, report.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("report after rollback: err = %v, want ErrNotFound", err)
+	}
+	var count int64
+	if err := db.Model(&models.OutboxEvent{}).Where("id = ?", event.ID).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("%d outbox rows after rollback, want 0", count)
+	}
+}
+
+func TestOutboxCommitAndMarkPublished(t *testing.T) {
+	t.Parallel()
+	db := repotest.OpenDB(t)
+	store := repository.NewGormStore(db)
+	ctx := context.Background()
+
+	report := repotest.CreateReport(t, store, repotest.CreateUser(t, store).ID)
+	event := newOutboxEvent(report.ID)
+	err := store.Transaction(ctx, func(tx repository.Store) error {
+		return tx.Outbox().Create(ctx, event)
+	})
+	if err != nil {
+		t.Fatalf("Transaction: %v", err)
+	}
+
+	var saved models.OutboxEvent
+	if err := db.First(&saved, "id = ?", event.ID).Error; err != nil {
+		t.Fatalf("load committed event: %v", err)
+	}
+	if saved.PublishedAt != nil {
+		t.Error("event published before MarkPublished")
+	}
+
+	if err := store.Outbox().MarkPublished(ctx, event); err != nil {
+		t.Fatalf("MarkPublished: %v", err)
+	}
+	if err := db.First(&saved, "id = ?", event.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if saved.PublishedAt == nil {
+		t.Error("published_at not set")
+	}
+}
+
+func TestOutboxClaimUnpublished(t *testing.T) {
+	t.Parallel()
+	db := repotest.OpenDB(t)
+	store := repository.NewGormStore(db)
+	ctx := context.Background()
+
+	report := repotest.CreateReport(t, store, repotest.CreateUser(t, store).ID)
+	base := time.Now().Add(-time.Hour)
+	older, newer, published, recent := newOutboxEvent(report.ID), newOutboxEvent(report.ID), newOutboxEvent(report.ID), newOutboxEvent(report.ID)
+	older.CreatedAt = base
+	newer.CreatedAt = base.Add(time.Minute)
+	published.CreatedAt = base.Add(-time.Minute)
+	for _, event := range []*models.OutboxEvent{newer, older, published, recent} {
+		if err := store.Outbox().Create(ctx, event); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.Outbox().MarkPublished(ctx, published); err != nil {
+		t.Fatal(err)
+	}
+
+	cutoff := time.Now().Add(-time.Second)
+	holder := db.Begin()
+	if holder.Error != nil {
+		t.Fatal(holder.Error)
+	}
+	defer holder.Rollback()
+	claimed, err := repository.NewGormStore(holder).Outbox().ClaimUnpublished(ctx, cutoff, 1)
+	if err != nil {
+		t.Fatalf("ClaimUnpublished: %v", err)
+	}
+	if len(claimed) != 1 || claimed[0].ID != older.ID {
+		t.Fatalf("first claim = %+v, want only the oldest unpublished event", claimed)
+	}
+
+	// Another relay skips the locked row instead of waiting for it.
+	err = store.Transaction(ctx, func(tx repository.Store) error {
+		claimed, err = tx.Outbox().ClaimUnpublished(ctx, cutoff, 10)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("ClaimUnpublished while a row is held: %v", err)
+	}
+	if len(claimed) != 1 || claimed[0].ID != newer.ID {
+		t.Errorf("second claim = %+v, want only the newer event", claimed)
+	}
+}
+
+func TestOutboxListPagesInWriteOrder(t *testing.T) {
+	t.Parallel()
+	store := repotest.Open(t)
+	ctx := context.Background()
+
+	base := time.Now().Add(-time.Hour).Truncate(time.Microsecond)
+	var written []*models.OutboxEvent
+	for i := 0; i < 5; i++ {
+		event := newOutboxEvent("T-1")
+		event.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+		if i == 3 {
+			event.EventType = models.EventTypeReportStatusChanged
+		}
+		if err := store.Outbox().Create(ctx, event); err != nil {
+			t.Fatal(err)
+		}
+		written = append(written, event)
+	}
+
+	filter := repository.OutboxFilter{From: base.Add(time.Minute), To: base.Add(4 * time.Minute), EventTypes: []string{models.EventTypeReportCreated}}
+	page, err := store.Outbox().List(ctx, filter, 1)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(page) != 1 || page[0].ID != written[1].ID {
+		t.Fatalf("first page = %+v, want event 1", page)
+	}
+
+	filter.AfterCreatedAt, filter.AfterID = page[0].CreatedAt, page[0].ID
+	page, err = store.Outbox().List(ctx, filter, 10)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(page) != 1 || page[0].ID != written[2].ID {
+		t.Errorf("second page = %+v, want only event 2", page)
+	}
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"reportmaxxing/services/report-management-service/models"
+)
+
+type gormReports struct {
+	db *gorm.DB
+}
+
+func (r gormReports) Get(ctx context.Context, id string) (*models.Report, error) {
+	var report models.Report
+	err := r.db.WithContext(ctx).Preload("Updates").
+		Preload("Comments", func(db *gorm.DB) *gorm.DB { return db.Order("created_at") }).
+		Preload("Attachments", func(db *gorm.DB) *gorm.DB { return db.Order("created_at") }).
+		Where("id = ?", id).
+		First(&report).Error
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (r gormReports) GetWithUpdates(ctx context.Context, id string) (*models.Report, error) {
+	var report models.Report
+	if err := r.db.WithContext(ctx).Unscoped().Preload("Updates").First(&report, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (r gormReports) Lock(ctx context.Context, id string) (*models.Report, error) {
+	var report models.Report
+	if err := r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).First(&report, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (r gormReports) ListAll(ctx context.Context) ([]models.Report, error) {
+	var reports []models.Report
+	err := r.db.WithContext(ctx).Preload("Updates").
+		Order("created_at DESC").
+		Find(&reports).Error
+	return reports, err
+}
+
+func (r gormReports) ListVisible(ctx context.Context, categories []string) ([]models.Report, error) {
+	var reports []models.Report
+	query := r.db.WithContext(ctx).Preload("Updates").
+		Where("visibility != ?", models.VisibilityPrivate)
+	if len(categories) > 0 {
+		query = query.Where("category IN ?", categories)
+	}
+	err := query.Order("created_at DESC").
+		Find(&reports).Error
+	return reports, err
+}
+
+func (r gormReports) ListByUser(ctx context.Context, userID string) ([]models.Report, error) {
+	var reports []models.Report
+	err := r.db.WithContext(ctx).Preload("Updates").
+		Where("user_id = ? OR created_by_id = ?", userID, userID).
+		Order("created_at DESC").
+		Find(&reports).Error
+	return reports, err
+}
+
+func (r gormReports) CountByUser(ctx context.Context, userID string, statuses ...models.ReportStatus) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Report{}).
+		Where("user_id = ? AND status IN ?", userID, statuses).
+		Count(&count).Error
+	return count, err
+}
+
+func (r gormReports) CountAll(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Unscoped().Model(&models.Report{}).Count(&count).Error
+	return count, err
+}
+
+func (r gormReports) Page(ctx context.Context, afterID string, limit int) ([]models.Report, error) {
+	var reports []models.Report
+	err := r.db.WithContext(ctx).Preload("Updates").
+		Preload("Comments", func(db *gorm.DB) *gorm.DB { return db.Order("created_at") }).
+		Preload("Attachments", func(db *gorm.DB) *gorm.DB { return db.Order("created_at") }).
+		Where("id > ?", afterID).
+		Order("id").
+		Limit(limit).
+		Find(&reports).Error
+	return reports, err
+}
+
+func (r gormReports) DeletedIDs(ctx context.Context, afterID string, limit int) ([]string, error) {
+	var ids []string
+	err := r.db.WithContext(ctx).Unscoped().Model(&models.Report{}).
+		Where("deleted_at IS NOT NULL AND id > ?", afterID).
+		Order("id").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+func (r gormReports) Create(ctx context.Context, report *models.Report) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(report).Error
+}
+
+func (r gormReports) Save(ctx context.Context, report *models.Report) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(report).Error
+}
+
+func (r gormReports) Delete(ctx context.Context, report *models.Report) error {
+	return r.db.WithContext(ctx).Delete(report).Error
+}
+
+type gormUpdates struct {
+	db *gorm.DB
+}
+
+func (r gormUpdates) Create(ctx context.Context, update *models.ReportUpdate) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(update).Error
+}
+
+type gormComments struct {
+	db *gorm.DB
+}
+
+func (r gormComments) Create(ctx context.Context, comment *models.ReportComment) error {
+	return r.db.WithContext(ctx).Create(comment).Error
+}
+
+type gormAttachments struct {
+	db *gorm.DB
+}
+
+func (r gormAttachments) Create(ctx context.Context, attachment *models.ReportAttachment) error {
+	return r.db.WithContext(ctx).Create(attachment).Error
+}
@@ -0,0 +1,232 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"reportmaxxing/services/report-management-service/models"
+	"reportmaxxing/services/report-management-service/repository"
+	"reportmaxxing/services/report-management-service/repository/repotest"
+)
+
+func TestReportCreateAndGet(t *testing.T) {
+	t.Parallel()
+	store := repotest.Open(t)
+	ctx := context.Background()
+
+	user := repotest.CreateUser(t, store)
+	created := repotest.CreateReport(t, store, user.ID, func(r *models.Report) {
+		r.Category = models.CategoryHealth
+		r.Priority = models.PriorityUrgent
+		r.IntakeChannel = models.IntakeChannelPhone
+		r.CreatedByID = "staff-1"
+	})
+
+	report, err := store.Reports().Get(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if report.UserID != user.ID || report.CreatedByID != "staff-1" {
+		t.Errorf("user_id, created_by_id = %q, %q; want %q, staff-1", report.UserID, report.CreatedByID, user.ID)
+	}
+	if report.Category != models.CategoryHealth || report.Priority != models.PriorityUrgent || report.IntakeChannel != models.IntakeChannelPhone {
+		t.Errorf("category, priority, channel = %s, %s, %s", report.Category, report.Priority, report.IntakeChannel)
+	}
+	if len(report.Updates) != 1 || report.Updates[0].Title != "Report Received" {
+		t.Errorf("updates = %+v, want the initial Report Received", report.Updates)
+	}
+
+	if _, err := store.Reports().Get(ctx, "T-MISSING"); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Get missing report: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestReportDeleteIsSoft(t *testing.T) {
+	t.Parallel()
+	store := repotest.Open(t)
+	ctx := context.Background()
+
+	report := repotest.CreateReport(t, store, repotest.CreateUser(t, store).ID)
+	if err := store.Reports().Delete(ctx, report); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	if _, err := store.Reports().Get(ctx, report.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Get deleted report: err = %v, want ErrNotFound", err)
+	}
+	if _, err := store.Reports().Lock(ctx, report.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Lock deleted report: err = %v, want ErrNotFound", err)
+	}
+	deleted, err := store.Reports().GetWithUpdates(ctx, report.ID)
+	if err != nil {
+		t.Fatalf("GetWithUpdates deleted report: %v", err)
+	}
+	if !deleted.DeletedAt.Valid {
+		t.Error("deleted_at not set")
+	}
+	if count, err := store.Reports().CountAll(ctx); err != nil || count != 1 {
+		t.Errorf("CountAll = %d, %v; want 1 including the deleted report", count, err)
+	}
+}
+
+func TestReportPaging(t *testing.T) {
+	t.Parallel()
+	store := repotest.Open(t)
+	ctx := context.Background()
+
+	userID := repotest.CreateUser(t, store).ID
+	a := repotest.CreateReport(t, store, userID, func(r *models.Report) { r.ID = "T-A" })
+	b := repotest.CreateReport(t, store, userID, func(r *models.Report) { r.ID = "T-B" })
+	c := repotest.CreateReport(t, store, userID, func(r *models.Report) { r.ID = "T-C" })
+	if err := store.Reports().Delete(ctx, b); err != nil {
+		t.Fatal(err)
+	}
+
+	page, err := store.Reports().Page(ctx, "", 1)
+	if err != nil {
+		t.Fatalf("Page: %v", err)
+	}
+	if len(page) != 1 || page[0].ID != a.ID || len(page[0].Updates) != 1 {
+		t.Fatalf("first page = %+v, want %s with its update", page, a.ID)
+	}
+	page, err = store.Reports().Page(ctx, a.ID, 10)
+	if err != nil {
+		t.Fatalf("Page: %v", err)
+	}
+	if len(page) != 1 || page[0].ID != c.ID {
+		t.Errorf("second page = %+v, want only %s", page, c.ID)
+	}
+
+	ids, err := store.Reports().DeletedIDs(ctx, "", 10)
+	if err != nil {
+		t.Fatalf("DeletedIDs: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != b.ID {
+		t.Errorf("DeletedIDs = %v, want [%s]", ids, b.ID)
+	}
+}
+
+func TestReportLockBlocksOtherTransactions(t *testing.T) {
+	t.Parallel()
+	db := repotest.OpenDB(t)
+	store := repository.NewGormStore(db)
+	ctx := context.Background()
+
+	report := repotest.CreateReport(t, store, repotest.CreateUser(t, store).ID)
+
+	holder := db.Begin()
+	if holder.Error != nil {
+		t.Fatal(holder.Error)
+	}
+	defer holder.Rollback()
+	if _, err := repository.NewGormStore(holder).Reports().Lock(ctx, report.ID); err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+
+	lockElsewhere := func() error {
+		return db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec("SET LOCAL lock_timeout = '200ms'").Error; err != nil {
+				return err
+			}
+			_, err := repository.NewGormStore(tx).Reports().Lock(ctx, report.ID)
+			return err
+		})
+	}
+
+	err := lockElsewhere()
+	if err == nil || !strings.Contains(err.Error(), "lock timeout") {
+		t.Fatalf("second Lock while the row is held: err = %v, want a lock timeout", err)
+	}
+
+	if err := holder.Rollback().Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := lockElsewhere(); err != nil {
+		t.Errorf("Lock after the holder rolled back: %v", err)
+	}
+}
+
+func TestReportLists(t *testing.T) {
+	t.Parallel()
+	store := repotest.Open(t)
+	ctx := context.Background()
+
+	alice := repotest.CreateUser(t, store)
+	bob := repotest.CreateUser(t, store)
+	base := time.Now().Add(-time.Hour)
+	at := func(minutes int) func(*models.Report) {
+		return func(r *models.Report) {
+			r.CreatedAt = base.Add(time.Duration(minutes) * time.Minute)
+			r.UpdatedAt = r.CreatedAt
+		}
+	}
+
+	alicePublic := repotest.CreateReport(t, store, alice.ID, at(1))
+	alicePrivate := repotest.CreateReport(t, store, alice.ID, at(2), func(r *models.Report) {
+		r.Visibility = models.VisibilityPrivate
+		r.Category = models.CategoryCrime
+	})
+	bobCrime := repotest.CreateReport(t, store, bob.ID, at(3), func(r *models.Report) {
+		r.Category = models.CategoryCrime
+		r.Status = models.StatusResolved
+		r.CreatedByID = "staff-1"
+	})
+	bobDeleted := repotest.CreateReport(t, store, bob.ID, at(4))
+	if err := store.Reports().Delete(ctx, bobDeleted); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	ids := func(reports []models.Report, err error) []string {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := make([]string, len(reports))
+		for i, report := range reports {
+			ids[i] = report.ID
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name string
+		got  []string
+		want []string
+	}{
+		{name: "all", got: ids(store.Reports().ListAll(ctx)), want: []string{bobCrime.ID, alicePrivate.ID, alicePublic.ID}},
+		{name: "visible", got: ids(store.Reports().ListVisible(ctx, nil)), want: []string{bobCrime.ID, alicePublic.ID}},
+		{name: "visible in crime", got: ids(store.Reports().ListVisible(ctx, []string{"CRIME"})), want: []string{bobCrime.ID}},
+		{name: "by user", got: ids(store.Reports().ListByUser(ctx, alice.ID)), want: []string{alicePrivate.ID, alicePublic.ID}},
+		{name: "filed on behalf", got: ids(store.Reports().ListByUser(ctx, "staff-1")), want: []string{bobCrime.ID}},
+		{name: "by user without reports", got: ids(store.Reports().ListByUser(ctx, "nobody")), want: []string{}},
+	}
+	for _, tt := range tests {
+		if strings.Join(tt.got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	reports, err := store.Reports().ListByUser(ctx, alice.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, report := range reports {
+		if len(report.Updates) != 1 {
+			t.Errorf("report %s listed with %d updates, want 1", report.ID, len(report.Updates))
+		}
+	}
+
+	open, err := store.Reports().CountByUser(ctx, bob.ID, models.StatusOpen, models.StatusInProgress)
+	if err != nil || open != 0 {
+		t.Errorf("bob's open reports = %d, %v; want 0, the deleted one excluded", open, err)
+	}
+	resolved, err := store.Reports().CountByUser(ctx, bob.ID, models.StatusResolved)
+	if err != nil || resolved != 1 {
+		t.Errorf("bob's resolved reports = %d, %v; want 1", resolved, err)
+	}
+}
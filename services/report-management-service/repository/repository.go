@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"reportmaxxing/services/report-management-service/models"
+)
+
+// ErrNotFound is returned when a lookup matches no row. It is gorm's own
+// sentinel, so callers can check either.
+var ErrNotFound = gorm.ErrRecordNotFound
+
+// Store hands out the repositories. Repositories from the Store passed to a
+// Transaction callback all run in that transaction.
+type Store interface {
+	Reports() Reports
+	Updates() Updates
+	Comments() Comments
+	Attachments() Attachments
+	Users() Users
+	Outbox() Outbox
+	WorkOrders() WorkOrders
+	ProcessedEvents() ProcessedEvents
+	Revocations() Revocations
+	RateLimits() RateLimits
+
+	// Transaction commits if fn returns nil and rolls back otherwise.
+	Transaction(ctx context.Context, fn func(tx Store) error) error
+}
+
+type Reports interface {
+	// Get loads a report with its updates, comments and attachments.
+	Get(ctx context.Context, id string) (*models.Report, error)
+	// GetWithUpdates loads a report and its updates, including a
+	// soft-deleted one, to return the result of a change.
+	GetWithUpdates(ctx context.Context, id string) (*models.Report, error)
+	// Lock loads a report and holds its row lock until the transaction
+	// ends. Outside a transaction the lock is released at once.
+	Lock(ctx context.Context, id string) (*models.Report, error)
+	// ListAll returns every report, private ones included, newest first.
+	ListAll(ctx context.Context) ([]models.Report, error)
+	// ListVisible returns every non-private report, newest first, limited
+	// to the given categories when any are passed.
+	ListVisible(ctx context.Context, categories []string) ([]models.Report, error)
+	// ListByUser returns a user's own reports, newest first: those filed by
+	// them or for them.
+	ListByUser(ctx context.Context, userID string) ([]models.Report, error)
+	// CountByUser counts a user's reports in any of statuses.
+	CountByUser(ctx context.Context, userID string, statuses ...models.ReportStatus) (int64, error)
+	// CountAll counts every report ever created, deleted ones included.
+	CountAll(ctx context.Context) (int64, error)
+	// Page returns up to limit reports with IDs after afterID, in ID order,
+	// with their updates, comments and attachments, to walk every report.
+	Page(ctx context.Context, afterID string, limit int) ([]models.Report, error)
+	// DeletedIDs returns up to limit IDs after afterID of soft-deleted
+	// reports, in order.
+	DeletedIDs(ctx context.Context, afterID string, limit int) ([]string, error)
+
+	// Create inserts the report row only; its updates, comments and
+	// attachments are written through their own repositories.
+	Create(ctx context.Context, report *models.Report) error
+	Save(ctx context.Context, report *models.Report) error
+	// Delete soft-deletes the report.
+	Delete(ctx context.Context, report *models.Report) error
+}
+
+type Updates interface {
+	Create(ctx context.Context, update *models.ReportUpdate) error
+}
+
+type Comments interface {
+	Create(ctx context.Context, comment *models.ReportComment) error
+}
+
+type Attachments interface {
+	Create(ctx context.Context, attachment *models.ReportAttachment) error
+}
+
+type Users interface {
+	Get(ctx context.Context, id string) (*models.User, error)
+	// Lock loads a user and holds its row lock until the transaction ends.
+	Lock(ctx context.Context, id string) (*models.User, error)
+	// FindByEmail matches case-insensitively.
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	// FindByPhone returns the oldest user with the normalized phone number.
+	FindByPhone(ctx context.Context, phone string) (*models.User, error)
+	Create(ctx context.Context, user *models.User) error
+	// SaveModeration writes the user's status and suspension fields.
+	SaveModeration(ctx context.Context, user *models.User) error
+	// ListSuspended returns the ID and suspension fields of every user with
+	// suspended status, expired suspensions included.
+	ListSuspended(ctx context.Context) ([]models.User, error)
+
+	// Sync inserts the user from a sign-in, or overwrites the profile fields
+	// and roles of their existing row.
+	Sync(ctx context.Context, user *models.User) error
+	// LockByEmail finds a user other than exceptID by email,
+	// case-insensitively, and holds its row lock until the transaction ends.
+	LockByEmail(ctx context.Context, email, exceptID string) (*models.User, error)
+	// Reassign moves the reports filed by or for fromID, and its moderation
+	// history, to toID.
+	Reassign(ctx context.Context, fromID, toID string) error
+	// ChangeID gives the user's row a new primary key.
+	ChangeID(ctx context.Context, user *models.User, id string) error
+	Delete(ctx context.Context, user *models.User) error
+
+	ListModerationActions(ctx context.Context, userID string) ([]models.UserModerationAction, error)
+	CreateModerationAction(ctx context.Context, action *models.UserModerationAction) error
+}
+
+type Outbox interface {
+	Create(ctx context.Context, event *models.OutboxEvent) error
+	MarkPublished(ctx context.Context, event *models.OutboxEvent) error
+	// ClaimUnpublished locks up to limit unpublished events created before
+	// the given time, oldest first, skipping rows another transaction
+	// already holds. The locks last until the transaction ends.
+	ClaimUnpublished(ctx context.Context, createdBefore time.Time, limit int) ([]models.OutboxEvent, error)
+	// List returns up to limit events matching filter, in the order they
+	// were written.
+	List(ctx context.Context, filter OutboxFilter, limit int) ([]models.OutboxEvent, error)
+}
+
+// OutboxFilter selects events created in [From, To), or from From on when To
+// is zero, optionally of the given types. AfterCreatedAt and AfterID resume
+// after the last event of a previous page.
+type OutboxFilter struct {
+	From           time.Time
+	To             time.Time
+	EventTypes     []string
+	AfterCreatedAt time.Time
+	AfterID        string
+}
+
+type WorkOrders interface {
+	// Link points the work order at link.ReportID, moving an existing link.
+	Link(ctx context.Context, link *models.WorkOrderLink) error
+	// LinkIfMissing creates the link unless the work order has one already.
+	LinkIfMissing(ctx context.Context, link *models.WorkOrderLink) error
+	// Lock loads a work order's link and holds its row lock until the
+	// transaction ends.
+	Lock(ctx context.Context, workOrderID string) (*models.WorkOrderLink, error)
+	// RecordState stores the work order's latest state, and the updated_at
+	// of the event that carried it unless eventAt is zero.
+	RecordState(ctx context.Context, link *models.WorkOrderLink, state string, eventAt time.Time) error
+}
+
+type ProcessedEvents interface {
+	// Claim records an inbound event as processed. It returns false when
+	// the event was claimed before, so a redelivery can be skipped.
+	Claim(ctx context.Context, source, eventID string) (bool, error)
+}
+
+type Revocations interface {
+	// List returns every revocation, newest first.
+	List(ctx context.Context) ([]models.TokenRevocation, error)
+	// ListActive returns the subject revocations and the session
+	// revocations created after sessionCutoff.
+	ListActive(ctx context.Context, sessionCutoff time.Time) ([]models.TokenRevocation, error)
+	Create(ctx context.Context, revocation *models.TokenRevocation) error
+	// Delete returns ErrNotFound when no revocation has the ID.
+	Delete(ctx context.Context, id string) error
+	// PruneSessions deletes the session revocations created up to cutoff
+	// and returns how many there were.
+	PruneSessions(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+type RateLimits interface {
+	// Lock loads a bucket, creating it full with tokens if it is new, and
+	// holds its row lock until the transaction ends.
+	Lock(ctx context.Context, key string, tokens float64, now time.Time) (*models.RateLimitBucket, error)
+	Save(ctx context.Context, bucket *models.RateLimitBucket) error
+	// Prune deletes buckets last updated before idleSince and returns how
+	// many there were.
+	Prune(ctx context.Context, idleSince time.Time) (int64, error)
+}
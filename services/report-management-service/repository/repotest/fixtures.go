@@ -0,0 +1,77 @@
+package repotest
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"reportmaxxing/services/report-management-service/models"
+	"reportmaxxing/services/report-management-service/repository"
+)
+
+// CreateUser inserts an active citizen with a unique email. opts can change
+// any field before the insert.
+func CreateUser(tb testing.TB, store repository.Store, opts ...func(*models.User)) *models.User {
+	tb.Helper()
+	id := uuid.New().String()
+	user := &models.User{
+		ID:     id,
+		Email:  id + "@example.test",
+		Name:   "Test User",
+		Status: models.UserStatusActive,
+	}
+	for _, opt := range opts {
+		opt(user)
+	}
+	if err := store.Users().Create(context.Background(), user); err != nil {
+		tb.Fatalf("create user fixture: %v", err)
+	}
+	return user
+}
+
+// CreateReport inserts an open, public, normal-priority report filed by
+// userID, with the initial "Report Received" update. opts can change any
+// field before the insert.
+func CreateReport(tb testing.TB, store repository.Store, userID string, opts ...func(*models.Report)) *models.Report {
+	tb.Helper()
+	now := time.Now()
+	report := &models.Report{
+		ID:            "T-" + strings.ToUpper(uuid.New().String()[:8]),
+		Title:         "Streetlight out",
+		Description:   "The streetlight on the corner has been out for a week.",
+		Category:      models.CategorySanitation,
+		Status:        models.StatusOpen,
+		Visibility:    models.VisibilityPublic,
+		Priority:      models.PriorityNormal,
+		IntakeChannel: models.IntakeChannelApp,
+		UserID:        userID,
+		CreatedByID:   userID,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	for _, opt := range opts {
+		opt(report)
+	}
+
+	ctx := context.Background()
+	err := store.Transaction(ctx, func(tx repository.Store) error {
+		if err := tx.Reports().Create(ctx, report); err != nil {
+			return err
+		}
+		return tx.Updates().Create(ctx, &models.ReportUpdate{
+			ID:        uuid.New().String(),
+			ReportID:  report.ID,
+			Title:     "Report Received",
+			Date:      now.Format("Jan 02, 2006"),
+			IsActive:  true,
+			CreatedAt: now,
+		})
+	})
+	if err != nil {
+		tb.Fatalf("create report fixture: %v", err)
+	}
+	return report
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"reportmaxxing/services/report-management-service/models"
+)
+
+type gormRevocations struct {
+	db *gorm.DB
+}
+
+func (r gormRevocations) List(ctx context.Context) ([]models.TokenRevocation, error) {
+	var revocations []models.TokenRevocation
+	err := r.db.WithContext(ctx).Order("created_at DESC").Find(&revocations).Error
+	return revocations, err
+}
+
+func (r gormRevocations) ListActive(ctx context.Context, sessionCutoff time.Time) ([]models.TokenRevocation, error) {
+	var revocations []models.TokenRevocation
+	err := r.db.WithContext(ctx).
+		Where("session_id IS NULL OR session_id = '' OR created_at > ?", sessionCutoff).
+		Find(&revocations).Error
+	return revocations, err
+}
+
+func (r gormRevocations) Create(ctx context.Context, revocation *models.TokenRevocation) error {
+	return r.db.WithContext(ctx).Create(revocation).Error
+}
+
+func (r gormRevocations) Delete(ctx context.Context, id string) error {
+	result := r.db.WithContext(ctx).Delete(&models.TokenRevocation{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r gormRevocations) PruneSessions(ctx context.Context, cutoff time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("session_id <> '' AND created_at <= ?", cutoff).
+		Delete(&models.TokenRevocation{})
+	return result.RowsAffected, result.Error
+}
+
+type gormRateLimits struct {
+	db *gorm.DB
+}
+
+func (r gormRateLimits) Lock(ctx context.Context, key string, tokens float64, now time.Time) (*models.RateLimitBucket, error) {
+	bucket := models.RateLimitBucket{Key: key, Tokens: tokens, UpdatedAt: now}
+	if err := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&bucket).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).First(&bucket, "key = ?", key).Error; err != nil {
+		return nil, err
+	}
+	return &bucket, nil
+}
+
+func (r gormRateLimits) Save(ctx context.Context, bucket *models.RateLimitBucket) error {
+	return r.db.WithContext(ctx).Model(bucket).Updates(map[string]interface{}{
+		"tokens":     bucket.Tokens,
+		"updated_at": bucket.UpdatedAt,
+	}).Error
+}
+
+func (r gormRateLimits) Prune(ctx context.Context, idleSince time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("updated_at < ?", idleSince).
+		Delete(&models.RateLimitBucket{})
+	return result.RowsAffected, result.Error
+}
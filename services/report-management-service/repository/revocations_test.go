@@ -0,0 +1,94 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"reportmaxxing/services/report-management-service/models"
+	"reportmaxxing/services/report-management-service/repository"
+	"reportmaxxing/services/report-management-service/repository/repotest"
+)
+
+func TestRevocationsActiveAndPrune(t *testing.T) {
+	t.Parallel()
+	store := repotest.Open(t)
+	ctx := context.Background()
+
+	cutoff := time.Now().Add(-time.Hour)
+	subject := &models.TokenRevocation{ID: uuid.New().String(), Subject: "user-1", CreatedAt: cutoff.Add(-time.Hour)}
+	expired := &models.TokenRevocation{ID: uuid.New().String(), SessionID: "session-1", CreatedAt: cutoff.Add(-time.Minute)}
+	live := &models.TokenRevocation{ID: uuid.New().String(), SessionID: "session-2", CreatedAt: cutoff.Add(time.Minute)}
+	for _, revocation := range []*models.TokenRevocation{subject, expired, live} {
+		if err := store.Revocations().Create(ctx, revocation); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	active, err := store.Revocations().ListActive(ctx, cutoff)
+	if err != nil {
+		t.Fatalf("ListActive: %v", err)
+	}
+	if len(active) != 2 {
+		t.Errorf("ListActive = %+v, want the subject and the live session revocation", active)
+	}
+
+	pruned, err := store.Revocations().PruneSessions(ctx, cutoff)
+	if err != nil {
+		t.Fatalf("PruneSessions: %v", err)
+	}
+	if pruned != 1 {
+		t.Errorf("PruneSessions = %d, want 1", pruned)
+	}
+	all, err := store.Revocations().List(ctx)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(all) != 2 || all[0].ID != live.ID {
+		t.Errorf("List = %+v, want the live session revocation first", all)
+	}
+
+	if err := store.Revocations().Delete(ctx, expired.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("Delete pruned revocation: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestRateLimitBuckets(t *testing.T) {
+	t.Parallel()
+	store := repotest.Open(t)
+	ctx := context.Background()
+
+	now := time.Now().Truncate(time.Microsecond)
+	err := store.Transaction(ctx, func(tx repository.Store) error {
+		bucket, err := tx.RateLimits().Lock(ctx, "ip:1", 10, now)
+		if err != nil {
+			return err
+		}
+		if bucket.Tokens != 10 {
+			t.Errorf("new bucket has %v tokens, want 10", bucket.Tokens)
+		}
+		bucket.Tokens, bucket.UpdatedAt = 9, now
+		return tx.RateLimits().Save(ctx, bucket)
+	})
+	if err != nil {
+		t.Fatalf("Lock and Save: %v", err)
+	}
+
+	bucket, err := store.RateLimits().Lock(ctx, "ip:1", 10, now.Add(time.Second))
+	if err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+	if bucket.Tokens != 9 {
+		t.Errorf("existing bucket has %v tokens, want 9", bucket.Tokens)
+	}
+
+	if pruned, err := store.RateLimits().Prune(ctx, now); err != nil || pruned != 0 {
+		t.Errorf("Prune before the last update = %d, %v; want 0", pruned, err)
+	}
+	if pruned, err := store.RateLimits().Prune(ctx, now.Add(time.Second)); err != nil || pruned != 1 {
+		t.Errorf("Prune after the last update = %d, %v; want 1", pruned, err)
+	}
+}
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"reportmaxxing/services/report-management-service/models"
+)
+
+type gormUsers struct {
+	db *gorm.DB
+}
+
+func (r gormUsers) Get(ctx context.Context, id string) (*models.User, error) {
+	return r.first(r.db.WithContext(ctx), "id = ?", id)
+}
+
+func (r gormUsers) Lock(ctx context.Context, id string) (*models.User, error) {
+	return r.first(r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}), "id = ?", id)
+}
+
+func (r gormUsers) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.first(r.db.WithContext(ctx), "LOWER(email) = LOWER(?)", email)
+}
+
+func (r gormUsers) FindByPhone(ctx context.Context, phone string) (*models.User, error) {
+	return r.first(r.db.WithContext(ctx).Order("created_at"), "phone = ?", phone)
+}
+
+func (r gormUsers) first(db *gorm.DB, query string, args ...interface{}) (*models.User, error) {
+	var user models.User
+	if err := db.Where(query, args...).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r gormUsers) Create(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Create(user).Error
+}
+
+func (r gormUsers) SaveModeration(ctx context.Context, user *models.User) error {
+	user.UpdatedAt = time.Now()
+	return r.db.WithContext(ctx).Model(user).Updates(map[string]interface{}{
+		"status":            user.Status,
+		"suspended_until":   user.SuspendedUntil,
+		"suspension_reason": user.SuspensionReason,
+		"updated_at":        user.UpdatedAt,
+	}).Error
+}
+
+func (r gormUsers) ListModerationActions(ctx context.Context, userID string) ([]models.UserModerationAction, error) {
+	var actions []models.UserModerationAction
+	err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&actions).Error
+	return actions, err
+}
+
+func (r gormUsers) CreateModerationAction(ctx context.Context, action *models.UserModerationAction) error {
+	return r.db.WithContext(ctx).Create(action).Error
+}
+
+func (r gormUsers) ListSuspended(ctx context.Context) ([]models.User, error) {
+	var users []models.User
+	err := r.db.WithContext(ctx).
+		Select("id", "status", "suspended_until", "suspension_reason").
+		Where("status = ?", models.UserStatusSuspended).
+		Find(&users).Error
+	return users, err
+}
+
+// Sync upserts so concurrent first requests from a new user don't race on
+// the primary key.
+func (r gormUsers) Sync(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Clauses(
+		clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"email", "name", "preferred_username", "locale", "phone", "roles", "updated_at"}),
+		},
+	).Create(user).Error
+}
+
+func (r gormUsers) LockByEmail(ctx context.Context, email, exceptID string) (*models.User, error) {
+	return r.first(r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}), "LOWER(email) = LOWER(?) AND id <> ?", email, exceptID)
+}
+
+func (r gormUsers) Reassign(ctx context.Context, fromID, toID string) error {
+	moves := []struct {
+		model  interface{}
+		column string
+	}{
+		{&models.Report{}, "user_id"},
+		{&models.Report{}, "created_by_id"},
+		{&models.UserModerationAction{}, "user_id"},
+	}
+	for _, move := range moves {
+		err := r.db.WithContext(ctx).Model(move.model).Unscoped().
+			Where(move.column+" = ?", fromID).
+			Update(move.column, toID).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r gormUsers) ChangeID(ctx context.Context, user *models.User, id string) error {
+	if err := r.db.WithContext(ctx).Model(user).Update("id", id).Error; err != nil {
+		return err
+	}
+	user.ID = id
+	return nil
+}
+
+func (r gormUsers) Delete(ctx context.Context, user *models.User) error {
+	return r.db.WithContext(ctx).Delete(user).Error
+}
@@ -0,0 +1,98 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"reportmaxxing/services/report-management-service/models"
+	"reportmaxxing/services/report-management-service/repository"
+	"reportmaxxing/services/report-management-service/repository/repotest"
+)
+
+func TestUserLookups(t *testing.T) {
+	t.Parallel()
+	store := repotest.Open(t)
+	ctx := context.Background()
+
+	older := repotest.CreateUser(t, store, func(u *models.User) {
+		u.Email = "Resident@Example.test"
+		u.Phone = "+15550100000"
+		u.CreatedAt = time.Now().Add(-time.Hour)
+	})
+	newer := repotest.CreateUser(t, store, func(u *models.User) {
+		u.Phone = "+15550100000"
+	})
+
+	tests := []struct {
+		name   string
+		lookup func() (*models.User, error)
+		want   string
+	}{
+		{name: "get", lookup: func() (*models.User, error) { return store.Users().Get(ctx, newer.ID) }, want: newer.ID},
+		{name: "lock", lookup: func() (*models.User, error) { return store.Users().Lock(ctx, older.ID) }, want: older.ID},
+		{name: "email ignores case", lookup: func() (*models.User, error) { return store.Users().FindByEmail(ctx, "resident@EXAMPLE.test") }, want: older.ID},
+		{name: "shared phone returns the oldest user", lookup: func() (*models.User, error) { return store.Users().FindByPhone(ctx, "+15550100000") }, want: older.ID},
+	}
+	for _, tt := range tests {
+		user, err := tt.lookup()
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if user.ID != tt.want {
+			t.Errorf("%s = %s, want %s", tt.name, user.ID, tt.want)
+		}
+	}
+
+	misses := map[string]func() (*models.User, error){
+		"get":   func() (*models.User, error) { return store.Users().Get(ctx, "missing") },
+		"email": func() (*models.User, error) { return store.Users().FindByEmail(ctx, "nobody@example.test") },
+		"phone": func() (*models.User, error) { return store.Users().FindByPhone(ctx, "+15559999999") },
+	}
+	for name, lookup := range misses {
+		if _, err := lookup(); !errors.Is(err, repository.ErrNotFound) {
+			t.Errorf("%s miss: err = %v, want ErrNotFound", name, err)
+		}
+	}
+}
+
+func TestUserModeration(t *testing.T) {
+	t.Parallel()
+	store := repotest.Open(t)
+	ctx := context.Background()
+
+	user := repotest.CreateUser(t, store)
+	until := time.Now().Add(24 * time.Hour).Truncate(time.Microsecond)
+	user.Status = models.UserStatusSuspended
+	user.SuspendedUntil = &until
+	user.SuspensionReason = "spam"
+	if err := store.Users().SaveModeration(ctx, user); err != nil {
+		t.Fatalf("SaveModeration: %v", err)
+	}
+
+	saved, err := store.Users().Get(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.Status != models.UserStatusSuspended || saved.SuspensionReason != "spam" ||
+		saved.SuspendedUntil == nil || !saved.SuspendedUntil.Equal(until) {
+		t.Errorf("saved suspension = %s, %q, %v", saved.Status, saved.SuspensionReason, saved.SuspendedUntil)
+	}
+
+	first := &models.UserModerationAction{ID: "MOD-1", UserID: user.ID, Action: models.ModerationActionSuspend, Reason: "spam", ActorID: "supervisor-1", CreatedAt: time.Now().Add(-time.Minute)}
+	second := &models.UserModerationAction{ID: "MOD-2", UserID: user.ID, Action: models.ModerationActionUnsuspend, Reason: "appeal", ActorID: "supervisor-1", CreatedAt: time.Now()}
+	for _, action := range []*models.UserModerationAction{first, second} {
+		if err := store.Users().CreateModerationAction(ctx, action); err != nil {
+			t.Fatalf("CreateModerationAction: %v", err)
+		}
+	}
+	actions, err := store.Users().ListModerationActions(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actions) != 2 || actions[0].ID != second.ID {
+		t.Errorf("moderation actions = %+v, want MOD-2 then MOD-1", actions)
+	}
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"reportmaxxing/services/report-management-service/models"
+)
+
+type gormWorkOrders struct {
+	db *gorm.DB
+}
+
+func (r gormWorkOrders) Link(ctx context.Context, link *models.WorkOrderLink) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "work_order_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"report_id", "updated_at"}),
+	}).Create(link).Error
+}
+
+func (r gormWorkOrders) LinkIfMissing(ctx context.Context, link *models.WorkOrderLink) error {
+	return r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(link).Error
+}
+
+func (r gormWorkOrders) Lock(ctx context.Context, workOrderID string) (*models.WorkOrderLink, error) {
+	var link models.WorkOrderLink
+	err := r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).First(&link, "work_order_id = ?", workOrderID).Error
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+func (r gormWorkOrders) RecordState(ctx context.Context, link *models.WorkOrderLink, state string, eventAt time.Time) error {
+	updates := map[string]interface{}{
+		"last_state": state,
+		"updated_at": time.Now(),
+	}
+	if !eventAt.IsZero() {
+		updates["last_event_at"] = eventAt
+	}
+	return r.db.WithContext(ctx).Model(link).Updates(updates).Error
+}
+
+type gormProcessedEvents struct {
+	db *gorm.DB
+}
+
+func (r gormProcessedEvents) Claim(ctx context.Context, source, eventID string) (bool, error) {
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&models.ProcessedEvent{
+		EventID:     eventID,
+		Source:      source,
+		ProcessedAt: time.Now(),
+	})
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,67 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"reportmaxxing/services/report-management-service/models"
+	"reportmaxxing/services/report-management-service/repository/repotest"
+)
+
+func TestWorkOrderLinks(t *testing.T) {
+	t.Parallel()
+	store := repotest.Open(t)
+	ctx := context.Background()
+
+	userID := repotest.CreateUser(t, store).ID
+	first, second := repotest.CreateReport(t, store, userID), repotest.CreateReport(t, store, userID)
+
+	if err := store.WorkOrders().LinkIfMissing(ctx, &models.WorkOrderLink{WorkOrderID: "WO-1", ReportID: first.ID}); err != nil {
+		t.Fatalf("LinkIfMissing: %v", err)
+	}
+	if err := store.WorkOrders().LinkIfMissing(ctx, &models.WorkOrderLink{WorkOrderID: "WO-1", ReportID: second.ID}); err != nil {
+		t.Fatalf("LinkIfMissing again: %v", err)
+	}
+	link, err := store.WorkOrders().Lock(ctx, "WO-1")
+	if err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+	if link.ReportID != first.ID {
+		t.Errorf("LinkIfMissing moved the link to %s", link.ReportID)
+	}
+
+	if err := store.WorkOrders().Link(ctx, &models.WorkOrderLink{WorkOrderID: "WO-1", ReportID: second.ID}); err != nil {
+		t.Fatalf("Link: %v", err)
+	}
+	eventAt := time.Now().Truncate(time.Microsecond)
+	if err := store.WorkOrders().RecordState(ctx, &models.WorkOrderLink{WorkOrderID: "WO-1"}, "COMPLETED", eventAt); err != nil {
+		t.Fatalf("RecordState: %v", err)
+	}
+	link, err = store.WorkOrders().Lock(ctx, "WO-1")
+	if err != nil {
+		t.Fatalf("Lock: %v", err)
+	}
+	if link.ReportID != second.ID || link.LastState != "COMPLETED" || link.LastEventAt == nil || !link.LastEventAt.Equal(eventAt) {
+		t.Errorf("link = %+v, want it moved to %s with the recorded state", link, second.ID)
+	}
+}
+
+func TestProcessedEventsClaimOnce(t *testing.T) {
+	t.Parallel()
+	store := repotest.Open(t)
+	ctx := context.Background()
+
+	for i, want := range []bool{true, false} {
+		claimed, err := store.ProcessedEvents().Claim(ctx, "workorders", "evt-1")
+		if err != nil {
+			t.Fatalf("Claim #%d: %v", i+1, err)
+		}
+		if claimed != want {
+			t.Errorf("Claim #%d = %v, want %v", i+1, claimed, want)
+		}
+	}
+	if claimed, err := store.ProcessedEvents().Claim(ctx, "keycloak", "evt-2"); err != nil || !claimed {
+		t.Errorf("Claim of another event = %v, %v; want true", claimed, err)
+	}
+}
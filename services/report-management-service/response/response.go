@@ -68,6 +68,18 @@ func Unauthorized(c *gin.Context, message string) {
 	})
 }
 
+// UnauthorizedWithCode reports an authentication failure with a specific
+// error code (e.g. TOKEN_EXPIRED) so clients can tell failures apart.
+func UnauthorizedWithCode(c *gin.Context, code, message string) {
+	c.JSON(http.StatusUnauthorized, Response{
+		Success: false,
+		Error: &ErrorInfo{
+			Code:    code,
+			Message: message,
+		},
+	})
+}
+
 func Forbidden(c *gin.Context, message string) {
 	c.JSON(http.StatusForbidden, Response{
 		Success: false,
@@ -78,6 +90,18 @@ func Forbidden(c *gin.Context, message string) {
 	})
 }
 
+// ForbiddenWithCode rejects an authenticated caller with a specific error
+// code (e.g. USER_SUSPENDED).
+func ForbiddenWithCode(c *gin.Context, code, message string) {
+	c.JSON(http.StatusForbidden, Response{
+		Success: false,
+		Error: &ErrorInfo{
+			Code:    code,
+			Message: message,
+		},
+	})
+}
+
 func NotFound(c *gin.Context, message string) {
 	c.JSON(http.StatusNotFound, Response{
 		Success: false,
@@ -98,6 +122,26 @@ func Conflict(c *gin.Context, message string) {
 	})
 }
 
+func TooManyRequests(c *gin.Context, message string) {
+	c.JSON(http.StatusTooManyRequests, Response{
+		Success: false,
+		Error: &ErrorInfo{
+			Code:    "RATE_LIMITED",
+			Message: message,
+		},
+	})
+}
+
+func ServiceUnavailable(c *gin.Context, message string) {
+	c.JSON(http.StatusServiceUnavailable, Response{
+		Success: false,
+		Error: &ErrorInfo{
+			Code:    "SERVICE_UNAVAILABLE",
+			Message: message,
+		},
+	})
+}
+
 func InternalError(c *gin.Context, message string) {
 	c.JSON(http.StatusInternalServerError, Response{
 		Success: false,
@@ -0,0 +1,717 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	kafkago "github.com/segmentio/kafka-go"
+	"gorm.io/gorm"
+
+	"reportmaxxing/services/report-management-service/models"
+	"reportmaxxing/services/report-management-service/repository"
+)
+
+// fakeStore keeps every repository in memory. Transaction runs the callback
+// against the same store and, on error, restores a copy taken before it, so
+// rollbacks behave like Postgres for the single-goroutine tests here.
+type fakeStore struct {
+	mu          sync.Mutex
+	reports     map[string]models.Report
+	updates     []models.ReportUpdate
+	comments    []models.ReportComment
+	attachments []models.ReportAttachment
+	users       map[string]models.User
+	moderation  []models.UserModerationAction
+	outbox      []models.OutboxEvent
+	links       map[string]models.WorkOrderLink
+	processed   map[string]bool
+	revocations []models.TokenRevocation
+	buckets     map[string]models.RateLimitBucket
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		reports:   map[string]models.Report{},
+		users:     map[string]models.User{},
+		links:     map[string]models.WorkOrderLink{},
+		processed: map[string]bool{},
+		buckets:   map[string]models.RateLimitBucket{},
+	}
+}
+
+func (s *fakeStore) Reports() repository.Reports         { return fakeReports{s} }
+func (s *fakeStore) Updates() repository.Updates         { return fakeUpdates{s} }
+func (s *fakeStore) Comments() repository.Comments       { return fakeComments{s} }
+func (s *fakeStore) Attachments() repository.Attachments { return fakeAttachments{s} }
+func (s *fakeStore) Users() repository.Users             { return fakeUsers{s} }
+func (s *fakeStore) Outbox() repository.Outbox           { return fakeOutbox{s} }
+func (s *fakeStore) WorkOrders() repository.WorkOrders   { return fakeWorkOrders{s} }
+func (s *fakeStore) ProcessedEvents() repository.ProcessedEvents {
+	return fakeProcessedEvents{s}
+}
+func (s *fakeStore) Revocations() repository.Revocations { return fakeRevocations{s} }
+func (s *fakeStore) RateLimits() repository.RateLimits   { return fakeRateLimits{s} }
+
+func (s *fakeStore) Transaction(ctx context.Context, fn func(tx repository.Store) error) error {
+	s.mu.Lock()
+	saved := s.copy()
+	s.mu.Unlock()
+
+	if err := fn(s); err != nil {
+		s.mu.Lock()
+		s.reports, s.updates, s.comments, s.attachments = saved.reports, saved.updates, saved.comments, saved.attachments
+		s.users, s.moderation, s.outbox = saved.users, saved.moderation, saved.outbox
+		s.links, s.processed, s.revocations, s.buckets = saved.links, saved.processed, saved.revocations, saved.buckets
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (s *fakeStore) copy() *fakeStore {
+	c := &fakeStore{
+		reports:     make(map[string]models.Report, len(s.reports)),
+		updates:     append([]models.ReportUpdate(nil), s.updates...),
+		comments:    append([]models.ReportComment(nil), s.comments...),
+		attachments: append([]models.ReportAttachment(nil), s.attachments...),
+		users:       make(map[string]models.User, len(s.users)),
+		moderation:  append([]models.UserModerationAction(nil), s.moderation...),
+		outbox:      append([]models.OutboxEvent(nil), s.outbox...),
+		links:       make(map[string]models.WorkOrderLink, len(s.links)),
+		processed:   make(map[string]bool, len(s.processed)),
+		revocations: append([]models.TokenRevocation(nil), s.revocations...),
+		buckets:     make(map[string]models.RateLimitBucket, len(s.buckets)),
+	}
+	for id, report := range s.reports {
+		c.reports[id] = report
+	}
+	for id, user := range s.users {
+		c.users[id] = user
+	}
+	for id, link := range s.links {
+		c.links[id] = link
+	}
+	for id := range s.processed {
+		c.processed[id] = true
+	}
+	for key, bucket := range s.buckets {
+		c.buckets[key] = bucket
+	}
+	return c
+}
+
+type fakeReports struct{ s *fakeStore }
+
+func (r fakeReports) load(id string, withDeleted bool) (*models.Report, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	report, ok := r.s.reports[id]
+	if !ok || (report.DeletedAt.Valid && !withDeleted) {
+		return nil, repository.ErrNotFound
+	}
+	for _, update := range r.s.updates {
+		if update.ReportID == id {
+			report.Updates = append(report.Updates, update)
+		}
+	}
+	for _, comment := range r.s.comments {
+		if comment.ReportID == id {
+			report.Comments = append(report.Comments, comment)
+		}
+	}
+	for _, attachment := range r.s.attachments {
+		if attachment.ReportID == id {
+			report.Attachments = append(report.Attachments, attachment)
+		}
+	}
+	return &report, nil
+}
+
+func (r fakeReports) Get(ctx context.Context, id string) (*models.Report, error) {
+	return r.load(id, false)
+}
+
+func (r fakeReports) GetWithUpdates(ctx context.Context, id string) (*models.Report, error) {
+	return r.load(id, true)
+}
+
+func (r fakeReports) Lock(ctx context.Context, id string) (*models.Report, error) {
+	return r.load(id, false)
+}
+
+// byID returns the stored reports, deleted ones included, in ID order.
+func (r fakeReports) byID() []models.Report {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	reports := make([]models.Report, 0, len(r.s.reports))
+	for _, report := range r.s.reports {
+		reports = append(reports, report)
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].ID < reports[j].ID })
+	return reports
+}
+
+func (r fakeReports) Page(ctx context.Context, afterID string, limit int) ([]models.Report, error) {
+	var reports []models.Report
+	for _, report := range r.byID() {
+		if report.ID > afterID && !report.DeletedAt.Valid && len(reports) < limit {
+			loaded, err := r.load(report.ID, false)
+			if err != nil {
+				return nil, err
+			}
+			reports = append(reports, *loaded)
+		}
+	}
+	return reports, nil
+}
+
+func (r fakeReports) DeletedIDs(ctx context.Context, afterID string, limit int) ([]string, error) {
+	var ids []string
+	for _, report := range r.byID() {
+		if report.ID > afterID && report.DeletedAt.Valid && len(ids) < limit {
+			ids = append(ids, report.ID)
+		}
+	}
+	return ids, nil
+}
+
+func (r fakeReports) list(keep func(models.Report) bool) []models.Report {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	reports := []models.Report{}
+	for _, report := range r.s.reports {
+		if !report.DeletedAt.Valid && keep(report) {
+			reports = append(reports, report)
+		}
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].CreatedAt.After(reports[j].CreatedAt) })
+	return reports
+}
+
+func (r fakeReports) ListAll(ctx context.Context) ([]models.Report, error) {
+	return r.list(func(models.Report) bool { return true }), nil
+}
+
+func (r fakeReports) ListVisible(ctx context.Context, categories []string) ([]models.Report, error) {
+	return r.list(func(report models.Report) bool {
+		if report.Visibility == models.VisibilityPrivate {
+			return false
+		}
+		if len(categories) == 0 {
+			return true
+		}
+		for _, category := range categories {
+			if strings.EqualFold(category, string(report.Category)) {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+func (r fakeReports) ListByUser(ctx context.Context, userID string) ([]models.Report, error) {
+	return r.list(func(report models.Report) bool { return report.UserID == userID }), nil
+}
+
+func (r fakeReports) CountByUser(ctx context.Context, userID string, statuses ...models.ReportStatus) (int64, error) {
+	reports := r.list(func(report models.Report) bool {
+		for _, status := range statuses {
+			if report.UserID == userID && report.Status == status {
+				return true
+			}
+		}
+		return false
+	})
+	return int64(len(reports)), nil
+}
+
+func (r fakeReports) CountAll(ctx context.Context) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	return int64(len(r.s.reports)), nil
+}
+
+func (r fakeReports) Create(ctx context.Context, report *models.Report) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.reports[report.ID]; ok {
+		return gorm.ErrDuplicatedKey
+	}
+	r.s.reports[report.ID] = withoutAssociations(*report)
+	return nil
+}
+
+func (r fakeReports) Save(ctx context.Context, report *models.Report) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.reports[report.ID] = withoutAssociations(*report)
+	return nil
+}
+
+func (r fakeReports) Delete(ctx context.Context, report *models.Report) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	stored := r.s.reports[report.ID]
+	stored.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	r.s.reports[report.ID] = stored
+	report.DeletedAt = stored.DeletedAt
+	return nil
+}
+
+// withoutAssociations drops the preloaded children, which the real store
+// writes through their own repositories.
+func withoutAssociations(report models.Report) models.Report {
+	report.Updates, report.Comments, report.Attachments = nil, nil, nil
+	return report
+}
+
+type fakeUpdates struct{ s *fakeStore }
+
+func (r fakeUpdates) Create(ctx context.Context, update *models.ReportUpdate) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.updates = append(r.s.updates, *update)
+	return nil
+}
+
+type fakeComments struct{ s *fakeStore }
+
+func (r fakeComments) Create(ctx context.Context, comment *models.ReportComment) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.comments = append(r.s.comments, *comment)
+	return nil
+}
+
+type fakeAttachments struct{ s *fakeStore }
+
+func (r fakeAttachments) Create(ctx context.Context, attachment *models.ReportAttachment) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.attachments = append(r.s.attachments, *attachment)
+	return nil
+}
+
+type fakeUsers struct{ s *fakeStore }
+
+func (r fakeUsers) find(match func(models.User) bool) (*models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var found *models.User
+	for _, user := range r.s.users {
+		if match(user) && (found == nil || user.CreatedAt.Before(found.CreatedAt)) {
+			copied := user
+			found = &copied
+		}
+	}
+	if found == nil {
+		return nil, repository.ErrNotFound
+	}
+	return found, nil
+}
+
+func (r fakeUsers) Get(ctx context.Context, id string) (*models.User, error) {
+	return r.find(func(u models.User) bool { return u.ID == id })
+}
+
+func (r fakeUsers) Lock(ctx context.Context, id string) (*models.User, error) {
+	return r.Get(ctx, id)
+}
+
+func (r fakeUsers) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.find(func(u models.User) bool { return strings.EqualFold(u.Email, email) })
+}
+
+func (r fakeUsers) FindByPhone(ctx context.Context, phone string) (*models.User, error) {
+	return r.find(func(u models.User) bool { return u.Phone == phone })
+}
+
+func (r fakeUsers) Create(ctx context.Context, user *models.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.users[user.ID]; ok {
+		return gorm.ErrDuplicatedKey
+	}
+	if user.Status == "" {
+		user.Status = models.UserStatusActive
+	}
+	r.s.users[user.ID] = *user
+	return nil
+}
+
+func (r fakeUsers) SaveModeration(ctx context.Context, user *models.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	stored, ok := r.s.users[user.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	stored.Status, stored.SuspendedUntil, stored.SuspensionReason = user.Status, user.SuspendedUntil, user.SuspensionReason
+	r.s.users[user.ID] = stored
+	return nil
+}
+
+func (r fakeUsers) ListSuspended(ctx context.Context) ([]models.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var users []models.User
+	for _, user := range r.s.users {
+		if user.Status == models.UserStatusSuspended {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (r fakeUsers) Sync(ctx context.Context, user *models.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	stored, ok := r.s.users[user.ID]
+	if !ok {
+		stored = *user
+		if stored.Status == "" {
+			stored.Status = models.UserStatusActive
+		}
+	}
+	stored.Email, stored.Name, stored.PreferredUsername = user.Email, user.Name, user.PreferredUsername
+	stored.Locale, stored.Phone, stored.Roles = user.Locale, user.Phone, user.Roles
+	r.s.users[user.ID] = stored
+	return nil
+}
+
+func (r fakeUsers) LockByEmail(ctx context.Context, email, exceptID string) (*models.User, error) {
+	return r.find(func(u models.User) bool { return strings.EqualFold(u.Email, email) && u.ID != exceptID })
+}
+
+func (r fakeUsers) Reassign(ctx context.Context, fromID, toID string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for id, report := range r.s.reports {
+		if report.UserID == fromID {
+			report.UserID = toID
+		}
+		if report.CreatedByID == fromID {
+			report.CreatedByID = toID
+		}
+		r.s.reports[id] = report
+	}
+	for i := range r.s.moderation {
+		if r.s.moderation[i].UserID == fromID {
+			r.s.moderation[i].UserID = toID
+		}
+	}
+	return nil
+}
+
+func (r fakeUsers) ChangeID(ctx context.Context, user *models.User, id string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	stored := r.s.users[user.ID]
+	delete(r.s.users, user.ID)
+	stored.ID = id
+	r.s.users[id] = stored
+	user.ID = id
+	return nil
+}
+
+func (r fakeUsers) Delete(ctx context.Context, user *models.User) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	delete(r.s.users, user.ID)
+	return nil
+}
+
+func (r fakeUsers) ListModerationActions(ctx context.Context, userID string) ([]models.UserModerationAction, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var actions []models.UserModerationAction
+	for i := len(r.s.moderation) - 1; i >= 0; i-- {
+		if r.s.moderation[i].UserID == userID {
+			actions = append(actions, r.s.moderation[i])
+		}
+	}
+	return actions, nil
+}
+
+func (r fakeUsers) CreateModerationAction(ctx context.Context, action *models.UserModerationAction) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.moderation = append(r.s.moderation, *action)
+	return nil
+}
+
+type fakeOutbox struct{ s *fakeStore }
+
+func (r fakeOutbox) Create(ctx context.Context, event *models.OutboxEvent) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.outbox = append(r.s.outbox, *event)
+	return nil
+}
+
+func (r fakeOutbox) MarkPublished(ctx context.Context, event *models.OutboxEvent) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for i := range r.s.outbox {
+		if r.s.outbox[i].ID == event.ID {
+			now := time.Now()
+			r.s.outbox[i].PublishedAt = &now
+		}
+	}
+	return nil
+}
+
+func (r fakeOutbox) ClaimUnpublished(ctx context.Context, createdBefore time.Time, limit int) ([]models.OutboxEvent, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var events []models.OutboxEvent
+	for _, event := range r.s.outbox {
+		if event.PublishedAt == nil && event.CreatedAt.Before(createdBefore) {
+			events = append(events, event)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].CreatedAt.Before(events[j].CreatedAt) })
+	if len(events) > limit {
+		events = events[:limit]
+	}
+	return events, nil
+}
+
+func (r fakeOutbox) List(ctx context.Context, filter repository.OutboxFilter, limit int) ([]models.OutboxEvent, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var events []models.OutboxEvent
+	for _, event := range r.s.outbox {
+		if event.CreatedAt.Before(filter.From) || (!filter.To.IsZero() && !event.CreatedAt.Before(filter.To)) {
+			continue
+		}
+		if len(filter.EventTypes) > 0 && !slices.Contains(filter.EventTypes, event.EventType) {
+			continue
+		}
+		if filter.AfterID != "" && (event.CreatedAt.Before(filter.AfterCreatedAt) ||
+			(event.CreatedAt.Equal(filter.AfterCreatedAt) && event.ID <= filter.AfterID)) {
+			continue
+		}
+		events = append(events, event)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].CreatedAt.Equal(events[j].CreatedAt) {
+			return events[i].CreatedAt.Before(events[j].CreatedAt)
+		}
+		return events[i].ID < events[j].ID
+	})
+	if len(events) > limit {
+		events = events[:limit]
+	}
+	return events, nil
+}
+
+type fakeWorkOrders struct{ s *fakeStore }
+
+func (r fakeWorkOrders) Link(ctx context.Context, link *models.WorkOrderLink) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	stored, ok := r.s.links[link.WorkOrderID]
+	if !ok {
+		stored = *link
+	}
+	stored.ReportID = link.ReportID
+	r.s.links[link.WorkOrderID] = stored
+	return nil
+}
+
+func (r fakeWorkOrders) LinkIfMissing(ctx context.Context, link *models.WorkOrderLink) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.links[link.WorkOrderID]; !ok {
+		r.s.links[link.WorkOrderID] = *link
+	}
+	return nil
+}
+
+func (r fakeWorkOrders) Lock(ctx context.Context, workOrderID string) (*models.WorkOrderLink, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	link, ok := r.s.links[workOrderID]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &link, nil
+}
+
+func (r fakeWorkOrders) RecordState(ctx context.Context, link *models.WorkOrderLink, state string, eventAt time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	stored := r.s.links[link.WorkOrderID]
+	stored.LastState = state
+	if !eventAt.IsZero() {
+		stored.LastEventAt = &eventAt
+	}
+	r.s.links[link.WorkOrderID] = stored
+	return nil
+}
+
+type fakeProcessedEvents struct{ s *fakeStore }
+
+func (r fakeProcessedEvents) Claim(ctx context.Context, source, eventID string) (bool, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	key := source + "/" + eventID
+	if r.s.processed[key] {
+		return false, nil
+	}
+	r.s.processed[key] = true
+	return true, nil
+}
+
+type fakeRevocations struct{ s *fakeStore }
+
+func (r fakeRevocations) List(ctx context.Context) ([]models.TokenRevocation, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var revocations []models.TokenRevocation
+	for i := len(r.s.revocations) - 1; i >= 0; i-- {
+		revocations = append(revocations, r.s.revocations[i])
+	}
+	return revocations, nil
+}
+
+func (r fakeRevocations) ListActive(ctx context.Context, sessionCutoff time.Time) ([]models.TokenRevocation, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var revocations []models.TokenRevocation
+	for _, revocation := range r.s.revocations {
+		if revocation.SessionID == "" || revocation.CreatedAt.After(sessionCutoff) {
+			revocations = append(revocations, revocation)
+		}
+	}
+	return revocations, nil
+}
+
+func (r fakeRevocations) Create(ctx context.Context, revocation *models.TokenRevocation) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.revocations = append(r.s.revocations, *revocation)
+	return nil
+}
+
+func (r fakeRevocations) Delete(ctx context.Context, id string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for i, revocation := range r.s.revocations {
+		if revocation.ID == id {
+			r.s.revocations = append(r.s.revocations[:i], r.s.revocations[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (r fakeRevocations) PruneSessions(ctx context.Context, cutoff time.Time) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	kept := r.s.revocations[:0]
+	for _, revocation := range r.s.revocations {
+		if revocation.SessionID == "" || revocation.CreatedAt.After(cutoff) {
+			kept = append(kept, revocation)
+		}
+	}
+	pruned := int64(len(r.s.revocations) - len(kept))
+	r.s.revocations = kept
+	return pruned, nil
+}
+
+type fakeRateLimits struct{ s *fakeStore }
+
+func (r fakeRateLimits) Lock(ctx context.Context, key string, tokens float64, now time.Time) (*models.RateLimitBucket, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	bucket, ok := r.s.buckets[key]
+	if !ok {
+		bucket = models.RateLimitBucket{Key: key, Tokens: tokens, UpdatedAt: now}
+		r.s.buckets[key] = bucket
+	}
+	return &bucket, nil
+}
+
+func (r fakeRateLimits) Save(ctx context.Context, bucket *models.RateLimitBucket) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	r.s.buckets[bucket.Key] = *bucket
+	return nil
+}
+
+func (r fakeRateLimits) Prune(ctx context.Context, idleSince time.Time) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	var pruned int64
+	for key, bucket := range r.s.buckets {
+		if bucket.UpdatedAt.Before(idleSince) {
+			delete(r.s.buckets, key)
+			pruned++
+		}
+	}
+	return pruned, nil
+}
+
+// unpublished returns the IDs of the outbox events not marked published.
+func (s *fakeStore) unpublished() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var ids []string
+	for _, event := range s.outbox {
+		if event.PublishedAt == nil {
+			ids = append(ids, event.ID)
+		}
+	}
+	return ids
+}
+
+var errBrokerDown = errors.New("kafka: broker down")
+
+// fakePublisher records what was published, keyed by topic, or fails every
+// publish while down is set.
+type fakePublisher struct {
+	mu        sync.Mutex
+	down      bool
+	published []kafkago.Message
+	topics    []string
+}
+
+func (p *fakePublisher) record(topic string, msgs ...kafkago.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.down {
+		return errBrokerDown
+	}
+	for _, msg := range msgs {
+		p.published = append(p.published, msg)
+		p.topics = append(p.topics, topic)
+	}
+	return nil
+}
+
+func (p *fakePublisher) PublishReportCreated(ctx context.Context, event *models.ReportCreatedEvent) error {
+	return p.record(event.EventType, kafkago.Message{Key: []byte(event.ReportID)})
+}
+
+func (p *fakePublisher) PublishReportStatusChanged(ctx context.Context, event *models.ReportStatusChangedEvent) error {
+	return p.record(event.EventType, kafkago.Message{Key: []byte(event.ReportID)})
+}
+
+func (p *fakePublisher) PublishReportEvent(ctx context.Context, event *models.ReportEvent) error {
+	return p.record(event.EventType, kafkago.Message{Key: []byte(event.ReportID)})
+}
+
+func (p *fakePublisher) PublishUserModerationEvent(ctx context.Context, event *models.UserModerationEvent) error {
+	return p.record(event.EventType, kafkago.Message{Key: []byte(event.UserID)})
+}
+
+func (p *fakePublisher) WriteMessages(ctx context.Context, topic string, msgs ...kafkago.Message) error {
+	return p.record(topic, msgs...)
+}
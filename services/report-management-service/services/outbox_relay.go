@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	kafkago "github.com/segmentio/kafka-go"
+
+	"reportmaxxing/services/report-management-service/logging"
+	"reportmaxxing/services/report-management-service/models"
+	"reportmaxxing/services/report-management-service/repository"
+)
+
+const (
+	outboxRelayBatchSize = 100
+	// outboxRelayGrace leaves a new event to the request that wrote it,
+	// which publishes it right after commit, so the relay only picks up
+	// events whose publish failed or never ran.
+	outboxRelayGrace = 30 * time.Second
+)
+
+// OutboxRelay publishes outbox events that were committed but never marked
+// published, e.g. because Kafka was down or the process died in between.
+// Consumers must already tolerate duplicates, since an event can be
+// published and then fail to be marked.
+type OutboxRelay struct {
+	store    repository.Store
+	producer EventPublisher
+}
+
+func NewOutboxRelay(store repository.Store, producer EventPublisher) *OutboxRelay {
+	return &OutboxRelay{store: store, producer: producer}
+}
+
+// Run relays pending events every interval until ctx is cancelled.
+func (r *OutboxRelay) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.RelayPending(ctx); err != nil && ctx.Err() == nil {
+				slog.Error("outbox: relay failed", "error", err)
+			}
+		}
+	}
+}
+
+// RelayPending publishes unpublished events one by one, oldest first, until
+// none are left or a publish fails. It returns how many were published.
+// Each batch stays locked while it is published, so several replicas can
+// relay at once without publishing the same event twice.
+func (r *OutboxRelay) RelayPending(ctx context.Context) (int, error) {
+	createdBefore := time.Now().Add(-outboxRelayGrace)
+	published := 0
+	for {
+		var claimed int
+		var publishErr error
+		err := r.store.Transaction(ctx, func(tx repository.Store) error {
+			events, err := tx.Outbox().ClaimUnpublished(ctx, createdBefore, outboxRelayBatchSize)
+			if err != nil {
+				return err
+			}
+			claimed = len(events)
+			for i := range events {
+				// Stop at the first failure but still commit the events
+				// marked so far, so they are not published again.
+				if publishErr = r.publish(ctx, &events[i]); publishErr != nil {
+					return nil
+				}
+				if err := tx.Outbox().MarkPublished(ctx, &events[i]); err != nil {
+					return err
+				}
+				published++
+			}
+			return nil
+		})
+		if err != nil {
+			return published, err
+		}
+		if publishErr != nil {
+			return published, publishErr
+		}
+		if claimed == 0 {
+			return published, nil
+		}
+		logging.FromContext(ctx).Info("outbox: relayed batch", "events", claimed, "total", published)
+	}
+}
+
+func (r *OutboxRelay) publish(ctx context.Context, event *models.OutboxEvent) error {
+	return r.producer.WriteMessages(ctx, event.Topic, kafkago.Message{
+		Key:   []byte(event.Key),
+		Value: event.Payload,
+		Time:  event.CreatedAt,
+		Headers: []kafkago.Header{
+			{Key: "event_type", Value: []byte(event.EventType)},
+		},
+	})
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"reportmaxxing/services/report-management-service/models"
+)
+
+func TestOutboxRelayPublishesPendingEvents(t *testing.T) {
+	store := newFakeStore()
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		err := store.Outbox().Create(context.Background(), &models.OutboxEvent{
+			ID:        fmt.Sprintf("evt-%d", i),
+			EventType: models.EventTypeReportCreated,
+			Topic:     "reports.created",
+			Key:       fmt.Sprintf("RPT-%d", i),
+			Payload:   []byte(`{}`),
+			CreatedAt: now.Add(-time.Hour + time.Duration(i)*time.Minute),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Left to the request that wrote it.
+	store.Outbox().Create(context.Background(), &models.OutboxEvent{ID: "evt-new", Topic: "reports.created", CreatedAt: now})
+
+	publisher := &fakePublisher{down: true}
+	relay := NewOutboxRelay(store, publisher)
+
+	published, err := relay.RelayPending(context.Background())
+	if !errors.Is(err, errBrokerDown) || published != 0 {
+		t.Fatalf("RelayPending while Kafka is down = %d, %v; want 0, %v", published, err, errBrokerDown)
+	}
+	if pending := store.unpublished(); len(pending) != 4 {
+		t.Fatalf("unpublished = %v, want every event still pending", pending)
+	}
+
+	publisher.down = false
+	published, err = relay.RelayPending(context.Background())
+	if err != nil || published != 3 {
+		t.Fatalf("RelayPending = %d, %v; want 3 events", published, err)
+	}
+	if pending := store.unpublished(); len(pending) != 1 || pending[0] != "evt-new" {
+		t.Errorf("unpublished = %v, want only the event inside the grace period", pending)
+	}
+	for i, msg := range publisher.published {
+		if want := fmt.Sprintf("RPT-%d", i); string(msg.Key) != want {
+			t.Errorf("message %d key = %s, want %s in write order", i, msg.Key, want)
+		}
+	}
+
+	if published, err := relay.RelayPending(context.Background()); err != nil || published != 0 {
+		t.Errorf("second RelayPending = %d, %v; want nothing left", published, err)
+	}
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+
+	"reportmaxxing/services/report-management-service/models"
+)
+
+// openStatuses are the statuses a report still needs work in.
+var openStatuses = []models.ReportStatus{StatusOpen, StatusInProgress}
+
+// ReportMetrics exposes report volumes and SLA breaches as gauges. They are
+// counted in Postgres on each scrape, so they stay correct across replicas.
+type ReportMetrics struct {
+	db         *gorm.DB
+	slaTargets map[models.ReportPriority]time.Duration
+
+	open     *prometheus.Desc
+	breached *prometheus.Desc
+}
+
+// NewReportMetrics counts a report as breaching its SLA when it is still open
+// longer than the target for its priority. Priorities without a target are
+// never breached.
+func NewReportMetrics(db *gorm.DB, slaTargets map[models.ReportPriority]time.Duration) *ReportMetrics {
+	return &ReportMetrics{
+		db:         db,
+		slaTargets: slaTargets,
+		open: prometheus.NewDesc("reports_open",
+			"Reports still OPEN or IN_PROGRESS, by category and status.",
+			[]string{"category", "status"}, nil),
+		breached: prometheus.NewDesc("reports_sla_breached",
+			"Open reports older than the resolution target for their priority.",
+			[]string{"category", "priority"}, nil),
+	}
+}
+
+func (m *ReportMetrics) Describe(ch chan<- *prometheus.Desc) {
+	ch <- m.open
+	ch <- m.breached
+}
+
+func (m *ReportMetrics) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var open []struct {
+		Category string
+		Status   string
+		Count    int64
+	}
+	err := m.db.WithContext(ctx).Model(&models.Report{}).
+		Select("category, status, COUNT(*) AS count").
+		Where("status IN ?", openStatuses).
+		Group("category, status").
+		Scan(&open).Error
+	if err != nil {
+		slog.Error("metrics: counting open reports failed", "error", err)
+	}
+	for _, row := range open {
+		ch <- prometheus.MustNewConstMetric(m.open, prometheus.GaugeValue, float64(row.Count), row.Category, row.Status)
+	}
+
+	if len(m.slaTargets) == 0 {
+		return
+	}
+	now := time.Now()
+	// Start from a false condition so each priority can be OR'd on.
+	overdue := m.db.Session(&gorm.Session{NewDB: true}).Where("1 = 0")
+	for priority, target := range m.slaTargets {
+		overdue = overdue.Or("priority = ? AND created_at < ?", priority, now.Add(-target))
+	}
+
+	var breached []struct {
+		Category string
+		Priority string
+		Count    int64
+	}
+	err = m.db.WithContext(ctx).Model(&models.Report{}).
+		Select("category, priority, COUNT(*) AS count").
+		Where("status IN ?", openStatuses).
+		Where(overdue).
+		Group("category, priority").
+		Scan(&breached).Error
+	if err != nil {
+		slog.Error("metrics: counting SLA breaches failed", "error", err)
+	}
+	for _, row := range breached {
+		ch <- prometheus.MustNewConstMetric(m.breached, prometheus.GaugeValue, float64(row.Count), row.Category, row.Priority)
+	}
+}
@@ -0,0 +1,306 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"reportmaxxing/services/report-management-service/logging"
+	"reportmaxxing/services/report-management-service/models"
+	"reportmaxxing/services/report-management-service/repository"
+)
+
+var (
+	ErrInvalidTransition = errors.New("report cannot transition from its current state")
+	ErrInvalidPriority   = errors.New("invalid report priority")
+	ErrInvalidMerge      = errors.New("report cannot be merged into the target report")
+	ErrNoChanges         = errors.New("no changes supplied")
+	ErrInvalidCategory   = errors.New("category must be one of CRIME, SANITATION or HEALTH")
+	ErrInvalidVisibility = errors.New("visibility must be one of PUBLIC, PRIVATE or ANONYMOUS")
+	ErrEmptyTitle        = errors.New("title cannot be empty")
+	ErrEmptyDescription  = errors.New("description cannot be empty")
+
+	ErrInvalidIntakeChannel = errors.New("channel must be one of PHONE, EMAIL, WALK_IN or PARTNER")
+)
+
+// Actor identifies who triggered a mutation and the request it came from.
+// They are copied onto every event the mutation emits. ClientID is set when
+// the actor is a partner system's service account. Roles are only used to
+// rank moderators against the users they moderate.
+type Actor struct {
+	UserID        string
+	ClientID      string
+	CorrelationID string
+	Roles         []string
+}
+
+type mutationResult struct {
+	changes map[string]models.FieldChange
+	data    interface{}
+}
+
+// mutateReport loads the report under a row lock, applies fn, saves it and
+// records the resulting event in the outbox, all in one transaction. The
+// event is published after commit.
+func (s *ReportService) mutateReport(ctx context.Context, actor Actor, reportID, eventType string, fn func(tx repository.Store, report *models.Report) (*mutationResult, error)) (*models.Report, error) {
+	var event *models.ReportEvent
+	var outbox *models.OutboxEvent
+
+	err := s.store.Transaction(ctx, func(tx repository.Store) error {
+		report, err := tx.Reports().Lock(ctx, reportID)
+		if err != nil {
+			return err
+		}
+
+		result, err := fn(tx, report)
+		if err != nil {
+			return err
+		}
+
+		event = &models.ReportEvent{
+			EventID:       uuid.New().String(),
+			EventType:     eventType,
+			Timestamp:     time.Now(),
+			ReportID:      report.ID,
+			UserID:        report.UserID,
+			ActorID:       actor.UserID,
+			ActorClientID: actor.ClientID,
+			CorrelationID: actor.CorrelationID,
+			Changes:       result.changes,
+			Data:          result.data,
+		}
+		outbox, err = newOutboxEvent(event.EventID, event.EventType, eventType, report.ID, event)
+		if err != nil {
+			return err
+		}
+		return tx.Outbox().Create(ctx, outbox)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.producer.PublishReportEvent(ctx, event); err != nil {
+		logging.FromContext(ctx).Warn("kafka publish failed, left in outbox", "event_id", outbox.ID, "error", err)
+	} else {
+		markPublished(ctx, s.store, outbox)
+	}
+
+	return s.store.Reports().GetWithUpdates(ctx, reportID)
+}
+
+func changed(changes map[string]models.FieldChange, field string, before, after interface{}) {
+	if before != after {
+		changes[field] = models.FieldChange{Before: before, After: after}
+	}
+}
+
+func (s *ReportService) EditReport(ctx context.Context, actor Actor, reportID string, req models.UpdateReportRequest) (*models.Report, error) {
+	if req.Title != nil && strings.TrimSpace(*req.Title) == "" {
+		return nil, ErrEmptyTitle
+	}
+	if req.Description != nil && strings.TrimSpace(*req.Description) == "" {
+		return nil, ErrEmptyDescription
+	}
+	if req.Category != nil {
+		switch models.ReportCategory(strings.ToUpper(*req.Category)) {
+		case models.CategoryCrime, models.CategorySanitation, models.CategoryHealth:
+		default:
+			return nil, ErrInvalidCategory
+		}
+	}
+	if req.Visibility != nil {
+		switch models.ReportVisibility(strings.ToUpper(*req.Visibility)) {
+		case models.VisibilityPublic, models.VisibilityPrivate, models.VisibilityAnonymous:
+		default:
+			return nil, ErrInvalidVisibility
+		}
+	}
+
+	return s.mutateReport(ctx, actor, reportID, models.EventTypeReportEdited, func(tx repository.Store, report *models.Report) (*mutationResult, error) {
+		changes := map[string]models.FieldChange{}
+		if req.Title != nil {
+			changed(changes, "title", report.Title, *req.Title)
+			report.Title = *req.Title
+		}
+		if req.Description != nil {
+			changed(changes, "description", report.Description, *req.Description)
+			report.Description = *req.Description
+		}
+		if req.Category != nil {
+			category := models.ReportCategory(strings.ToUpper(*req.Category))
+			changed(changes, "category", string(report.Category), string(category))
+			report.Category = category
+		}
+		if req.Visibility != nil {
+			visibility := models.ReportVisibility(strings.ToUpper(*req.Visibility))
+			changed(changes, "visibility", string(report.Visibility), string(visibility))
+			report.Visibility = visibility
+		}
+		if len(changes) == 0 {
+			return nil, ErrNoChanges
+		}
+
+		report.UpdatedAt = time.Now()
+		if err := tx.Reports().Save(ctx, report); err != nil {
+			return nil, err
+		}
+		return &mutationResult{changes: changes}, nil
+	})
+}
+
+func (s *ReportService) AssignReport(ctx context.Context, actor Actor, reportID, assigneeID string) (*models.Report, error) {
+	return s.mutateReport(ctx, actor, reportID, models.EventTypeReportAssigned, func(tx repository.Store, report *models.Report) (*mutationResult, error) {
+		changes := map[string]models.FieldChange{}
+		changed(changes, "assignee_id", report.AssigneeID, assigneeID)
+		if len(changes) == 0 {
+			return nil, ErrNoChanges
+		}
+
+		report.AssigneeID = assigneeID
+		report.UpdatedAt = time.Now()
+		if err := tx.Reports().Save(ctx, report); err != nil {
+			return nil, err
+		}
+		return &mutationResult{changes: changes}, nil
+	})
+}
+
+func (s *ReportService) ChangePriority(ctx context.Context, actor Actor, reportID string, priority models.ReportPriority) (*models.Report, error) {
+	switch priority {
+	case models.PriorityLow, models.PriorityNormal, models.PriorityHigh, models.PriorityUrgent:
+	default:
+		return nil, ErrInvalidPriority
+	}
+
+	return s.mutateReport(ctx, actor, reportID, models.EventTypeReportPriorityChanged, func(tx repository.Store, report *models.Report) (*mutationResult, error) {
+		changes := map[string]models.FieldChange{}
+		changed(changes, "priority", string(report.Priority), string(priority))
+		if len(changes) == 0 {
+			return nil, ErrNoChanges
+		}
+
+		report.Priority = priority
+		report.UpdatedAt = time.Now()
+		if err := tx.Reports().Save(ctx, report); err != nil {
+			return nil, err
+		}
+		return &mutationResult{changes: changes}, nil
+	})
+}
+
+func (s *ReportService) AddComment(ctx context.Context, actor Actor, reportID, body string) (*models.Report, error) {
+	return s.mutateReport(ctx, actor, reportID, models.EventTypeReportCommentAdded, func(tx repository.Store, report *models.Report) (*mutationResult, error) {
+		comment := models.ReportComment{
+			ID:        uuid.New().String(),
+			ReportID:  report.ID,
+			AuthorID:  actor.UserID,
+			Body:      body,
+			CreatedAt: time.Now(),
+		}
+		if err := tx.Comments().Create(ctx, &comment); err != nil {
+			return nil, err
+		}
+		return &mutationResult{data: comment}, nil
+	})
+}
+
+func (s *ReportService) AddAttachment(ctx context.Context, actor Actor, reportID string, req models.AddAttachmentRequest) (*models.Report, error) {
+	return s.mutateReport(ctx, actor, reportID, models.EventTypeReportAttachmentAdded, func(tx repository.Store, report *models.Report) (*mutationResult, error) {
+		attachment := models.ReportAttachment{
+			ID:          uuid.New().String(),
+			ReportID:    report.ID,
+			UploaderID:  actor.UserID,
+			URL:         req.URL,
+			ContentType: req.ContentType,
+			CreatedAt:   time.Now(),
+		}
+		if err := tx.Attachments().Create(ctx, &attachment); err != nil {
+			return nil, err
+		}
+		return &mutationResult{data: attachment}, nil
+	})
+}
+
+func (s *ReportService) WithdrawReport(ctx context.Context, actor Actor, reportID string) (*models.Report, error) {
+	return s.mutateReport(ctx, actor, reportID, models.EventTypeReportWithdrawn, func(tx repository.Store, report *models.Report) (*mutationResult, error) {
+		if report.Status != StatusOpen && report.Status != StatusInProgress {
+			return nil, ErrInvalidTransition
+		}
+		return setStatus(ctx, tx, report, models.StatusWithdrawn)
+	})
+}
+
+func (s *ReportService) ReopenReport(ctx context.Context, actor Actor, reportID string) (*models.Report, error) {
+	return s.mutateReport(ctx, actor, reportID, models.EventTypeReportReopened, func(tx repository.Store, report *models.Report) (*mutationResult, error) {
+		if report.Status != StatusResolved && report.Status != models.StatusWithdrawn {
+			return nil, ErrInvalidTransition
+		}
+
+		mergedIntoID := report.MergedIntoID
+		report.MergedIntoID = ""
+		result, err := setStatus(ctx, tx, report, StatusOpen)
+		if err != nil {
+			return nil, err
+		}
+		changed(result.changes, "merged_into_id", mergedIntoID, "")
+		return result, nil
+	})
+}
+
+// MergeReport closes reportID as a duplicate of targetID.
+func (s *ReportService) MergeReport(ctx context.Context, actor Actor, reportID, targetID string) (*models.Report, error) {
+	if reportID == targetID {
+		return nil, ErrInvalidMerge
+	}
+
+	return s.mutateReport(ctx, actor, reportID, models.EventTypeReportMerged, func(tx repository.Store, report *models.Report) (*mutationResult, error) {
+		if report.MergedIntoID != "" {
+			return nil, ErrInvalidMerge
+		}
+
+		target, err := tx.Reports().Get(ctx, targetID)
+		if err != nil {
+			return nil, err
+		}
+		if target.MergedIntoID != "" {
+			return nil, ErrInvalidMerge
+		}
+
+		report.MergedIntoID = target.ID
+		result, err := setStatus(ctx, tx, report, StatusResolved)
+		if err != nil {
+			return nil, err
+		}
+		changed(result.changes, "merged_into_id", "", target.ID)
+		return result, nil
+	})
+}
+
+func (s *ReportService) DeleteReport(ctx context.Context, actor Actor, reportID string) error {
+	_, err := s.mutateReport(ctx, actor, reportID, models.EventTypeReportDeleted, func(tx repository.Store, report *models.Report) (*mutationResult, error) {
+		if err := tx.Reports().Delete(ctx, report); err != nil {
+			return nil, err
+		}
+		return &mutationResult{
+			changes: map[string]models.FieldChange{
+				"deleted": {Before: false, After: true},
+			},
+		}, nil
+	})
+	return err
+}
+
+func setStatus(ctx context.Context, tx repository.Store, report *models.Report, status models.ReportStatus) (*mutationResult, error) {
+	changes := map[string]models.FieldChange{}
+	changed(changes, "status", string(report.Status), string(status))
+
+	report.Status = status
+	report.UpdatedAt = time.Now()
+	if err := tx.Reports().Save(ctx, report); err != nil {
+		return nil, err
+	}
+	return &mutationResult{changes: changes}, nil
+}
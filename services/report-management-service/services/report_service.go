@@ -1,15 +1,18 @@
 package services
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/google/uuid"
-	"gorm.io/gorm"
+	kafkago "github.com/segmentio/kafka-go"
 
 	"reportmaxxing/services/report-management-service/kafka"
+	"reportmaxxing/services/report-management-service/logging"
 	"reportmaxxing/services/report-management-service/models"
+	"reportmaxxing/services/report-management-service/repository"
 )
 
 const (
@@ -18,62 +21,106 @@ const (
 	StatusResolved   = models.ReportStatus("RESOLVED")
 )
 
+// EventPublisher is the part of kafka.Producer the services publish
+// through, so they can be tested without a broker.
+type EventPublisher interface {
+	PublishReportCreated(ctx context.Context, event *models.ReportCreatedEvent) error
+	PublishReportStatusChanged(ctx context.Context, event *models.ReportStatusChangedEvent) error
+	PublishReportEvent(ctx context.Context, event *models.ReportEvent) error
+	PublishUserModerationEvent(ctx context.Context, event *models.UserModerationEvent) error
+	WriteMessages(ctx context.Context, topic string, msgs ...kafkago.Message) error
+}
+
 type ReportService struct {
-	db       *gorm.DB
-	producer *kafka.Producer
+	store    repository.Store
+	producer EventPublisher
 }
 
-func NewReportService(db *gorm.DB, producer *kafka.Producer) *ReportService {
-	return &ReportService{db: db, producer: producer}
+func NewReportService(store repository.Store, producer EventPublisher) *ReportService {
+	return &ReportService{store: store, producer: producer}
 }
 
+// GetAllReports returns every report, private ones included.
 func (s *ReportService) GetAllReports() ([]models.Report, error) {
-	var reports []models.Report
-	err := s.db.Preload("Updates").
-		Where("visibility != ?", models.VisibilityPrivate).
-		Order("created_at DESC").
-		Find(&reports).Error
-	return reports, err
+	return s.store.Reports().ListAll(context.Background())
+}
+
+// GetVisibleReports returns every non-private report, limited to the given
+// departments' categories when any are passed.
+func (s *ReportService) GetVisibleReports(departments []string) ([]models.Report, error) {
+	return s.store.Reports().ListVisible(context.Background(), departments)
 }
 
 func (s *ReportService) GetReportByID(id string) (*models.Report, error) {
-	var report models.Report
-	err := s.db.Preload("Updates").
-		Where("id = ?", id).
-		First(&report).Error
-	if err != nil {
+	return s.store.Reports().Get(context.Background(), id)
+}
+
+func (s *ReportService) GetReportsByUserID(userID string) ([]models.Report, error) {
+	return s.store.Reports().ListByUser(context.Background(), userID)
+}
+
+// ReportStats counts a user's reports that are still being worked on and
+// those that have been resolved.
+type ReportStats struct {
+	Open     int64
+	Resolved int64
+}
+
+func (s *ReportService) GetReportStats(ctx context.Context, userID string) (*ReportStats, error) {
+	var stats ReportStats
+	var err error
+	reports := s.store.Reports()
+	if stats.Open, err = reports.CountByUser(ctx, userID, openStatuses...); err != nil {
 		return nil, err
 	}
-	return &report, nil
+	if stats.Resolved, err = reports.CountByUser(ctx, userID, StatusResolved); err != nil {
+		return nil, err
+	}
+	return &stats, nil
 }
 
-func (s *ReportService) GetReportsByUserID(userID string) ([]models.Report, error) {
-	var reports []models.Report
-	err := s.db.Preload("Updates").
-		Where("user_id = ?", userID).
-		Order("created_at DESC").
-		Find(&reports).Error
-	return reports, err
+// CreateReport files a report for the caller. Reports filed by a partner's
+// service account are tagged with the PARTNER intake channel.
+func (s *ReportService) CreateReport(ctx context.Context, actor Actor, req models.CreateReportRequest) (*models.Report, error) {
+	channel := models.IntakeChannelApp
+	if actor.ClientID != "" {
+		channel = models.IntakeChannelPartner
+	}
+	return s.createReport(ctx, actor, actor.UserID, channel, req)
+}
+
+// CreateReportOnBehalf files a report for reporterID, recording actor as its
+// creator. The report belongs to the reporter for visibility purposes.
+func (s *ReportService) CreateReportOnBehalf(ctx context.Context, actor Actor, reporterID string, channel models.IntakeChannel, req models.CreateReportRequest) (*models.Report, error) {
+	switch channel {
+	case models.IntakeChannelPhone, models.IntakeChannelEmail, models.IntakeChannelWalkIn, models.IntakeChannelPartner:
+	default:
+		return nil, ErrInvalidIntakeChannel
+	}
+	return s.createReport(ctx, actor, reporterID, channel, req)
 }
 
-func (s *ReportService) CreateReport(userID string, req models.CreateReportRequest) (*models.Report, error) {
-	reportID, err := s.generateReportID()
+func (s *ReportService) createReport(ctx context.Context, actor Actor, userID string, channel models.IntakeChannel, req models.CreateReportRequest) (*models.Report, error) {
+	reportID, err := s.generateReportID(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	now := time.Now()
 	report := models.Report{
-		ID:          reportID,
-		Title:       req.Title,
-		Description: req.Description,
-		Category:    models.ReportCategory(req.Category),
-		Status:      StatusOpen,
-		Visibility:  models.ReportVisibility(req.Visibility),
-		ImageURL:    req.ImageURL,
-		CreatedAt:   now,
-		UpdatedAt:   now,
-		UserID:      userID,
+		ID:            reportID,
+		Title:         req.Title,
+		Description:   req.Description,
+		Category:      models.ReportCategory(req.Category),
+		Status:        StatusOpen,
+		Visibility:    models.ReportVisibility(req.Visibility),
+		Priority:      models.PriorityNormal,
+		ImageURL:      req.ImageURL,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		UserID:        userID,
+		CreatedByID:   actor.UserID,
+		IntakeChannel: channel,
 		Updates: []models.ReportUpdate{
 			{
 				ID:        uuid.New().String(),
@@ -99,35 +146,79 @@ func (s *ReportService) CreateReport(userID string, req models.CreateReportReque
 		},
 	}
 
-	if err := s.db.Create(&report).Error; err != nil {
+	event := &models.ReportCreatedEvent{
+		EventID:       uuid.New().String(),
+		EventType:     models.EventTypeReportCreated,
+		Timestamp:     time.Now(),
+		ReportID:      report.ID,
+		UserID:        userID,
+		ActorID:       actor.UserID,
+		ActorClientID: actor.ClientID,
+		CorrelationID: actor.CorrelationID,
+		IntakeChannel: string(channel),
+		Title:         report.Title,
+		Description:   report.Description,
+		Category:      string(report.Category),
+		Status:        string(report.Status),
+		Visibility:    string(report.Visibility),
+	}
+	outbox, err := newOutboxEvent(event.EventID, event.EventType, kafka.ReportsCreatedTopic, report.ID, event)
+	if err != nil {
 		return nil, err
 	}
 
-	s.db.Preload("Updates").First(&report, "id = ?", report.ID)
-
-	event := &models.ReportCreatedEvent{
-		EventID:     uuid.New().String(),
-		EventType:   models.EventTypeReportCreated,
-		Timestamp:   time.Now(),
-		ReportID:    report.ID,
-		UserID:      userID,
-		Title:       report.Title,
-		Description: report.Description,
-		Category:    string(report.Category),
-		Status:      string(report.Status),
-		Visibility:  string(report.Visibility),
+	err = s.store.Transaction(ctx, func(tx repository.Store) error {
+		if err := tx.Reports().Create(ctx, &report); err != nil {
+			return err
+		}
+		for i := range report.Updates {
+			report.Updates[i].ReportID = report.ID
+			if err := tx.Updates().Create(ctx, &report.Updates[i]); err != nil {
+				return err
+			}
+		}
+		return tx.Outbox().Create(ctx, outbox)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	if err := s.producer.PublishReportCreated(event); err != nil {
-		log.Printf("Kafka publish failed (continuing): %v", err)
+	if err := s.producer.PublishReportCreated(ctx, event); err != nil {
+		logging.FromContext(ctx).Warn("kafka publish failed, left in outbox", "event_id", outbox.ID, "error", err)
+	} else {
+		markPublished(ctx, s.store, outbox)
 	}
 
 	return &report, nil
 }
 
-func (s *ReportService) generateReportID() (string, error) {
-	var count int64
-	s.db.Model(&models.Report{}).Count(&count)
+func newOutboxEvent(eventID, eventType, topic, key string, event interface{}) (*models.OutboxEvent, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+	return &models.OutboxEvent{
+		ID:        eventID,
+		EventType: eventType,
+		Topic:     topic,
+		Key:       key,
+		Payload:   payload,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+func markPublished(ctx context.Context, store repository.Store, outbox *models.OutboxEvent) {
+	if err := store.Outbox().MarkPublished(ctx, outbox); err != nil {
+		logging.FromContext(ctx).Error("outbox: failed to mark event published", "event_id", outbox.ID, "error", err)
+	}
+}
+
+func (s *ReportService) generateReportID(ctx context.Context) (string, error) {
+	// Count soft-deleted reports too so their IDs are never reused.
+	count, err := s.store.Reports().CountAll(ctx)
+	if err != nil {
+		return "", err
+	}
 	seq := count + 1
 	return fmt.Sprintf("R-2025-%03d", seq), nil
 }
@@ -136,35 +227,66 @@ func formatDateLabel(t time.Time) string {
 	return t.Format("Jan 02, 2006")
 }
 
-func (s *ReportService) UpdateReportStatus(reportID string, newStatus models.ReportStatus) (*models.Report, error) {
-	var report models.Report
-	if err := s.db.First(&report, "id = ?", reportID).Error; err != nil {
+func (s *ReportService) UpdateReportStatus(ctx context.Context, actor Actor, reportID string, newStatus models.ReportStatus) (*models.Report, error) {
+	var event *models.ReportStatusChangedEvent
+	var outbox *models.OutboxEvent
+
+	err := s.store.Transaction(ctx, func(tx repository.Store) error {
+		report, err := tx.Reports().Lock(ctx, reportID)
+		if err != nil {
+			return err
+		}
+		event, outbox, err = changeStatus(ctx, tx, actor, report, newStatus)
+		return err
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	oldStatus := report.Status
+	s.publishStatusChanged(ctx, event, outbox)
+	return s.store.Reports().GetWithUpdates(ctx, reportID)
+}
 
+// changeStatus saves a locked report's new status and its outbox row in tx.
+// The caller publishes the event once tx has committed.
+func changeStatus(ctx context.Context, tx repository.Store, actor Actor, report *models.Report, newStatus models.ReportStatus) (*models.ReportStatusChangedEvent, *models.OutboxEvent, error) {
+	oldStatus := report.Status
 	report.Status = newStatus
 	report.UpdatedAt = time.Now()
-	if err := s.db.Save(&report).Error; err != nil {
-		return nil, err
-	}
-
-	s.db.Preload("Updates").First(&report, "id = ?", report.ID)
 
 	event := &models.ReportStatusChangedEvent{
-		EventID:   uuid.New().String(),
-		EventType: models.EventTypeReportStatusChanged,
-		Timestamp: time.Now(),
-		ReportID:  report.ID,
-		UserID:    report.UserID,
-		OldStatus: string(oldStatus),
-		NewStatus: string(newStatus),
+		EventID:       uuid.New().String(),
+		EventType:     models.EventTypeReportStatusChanged,
+		Timestamp:     time.Now(),
+		ReportID:      report.ID,
+		UserID:        report.UserID,
+		ActorID:       actor.UserID,
+		ActorClientID: actor.ClientID,
+		CorrelationID: actor.CorrelationID,
+		OldStatus:     string(oldStatus),
+		NewStatus:     string(newStatus),
+		Changes: map[string]models.FieldChange{
+			"status": {Before: string(oldStatus), After: string(newStatus)},
+		},
+	}
+	outbox, err := newOutboxEvent(event.EventID, event.EventType, kafka.ReportsStatusChangedTopic, report.ID, event)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	if err := s.producer.PublishReportStatusChanged(event); err != nil {
-		log.Printf("Kafka publish failed (continuing): %v", err)
+	if err := tx.Reports().Save(ctx, report); err != nil {
+		return nil, nil, err
+	}
+	if err := tx.Outbox().Create(ctx, outbox); err != nil {
+		return nil, nil, err
 	}
+	return event, outbox, nil
+}
 
-	return &report, nil
+func (s *ReportService) publishStatusChanged(ctx context.Context, event *models.ReportStatusChangedEvent, outbox *models.OutboxEvent) {
+	if err := s.producer.PublishReportStatusChanged(ctx, event); err != nil {
+		logging.FromContext(ctx).Warn("kafka publish failed, left in outbox", "event_id", outbox.ID, "error", err)
+	} else {
+		markPublished(ctx, s.store, outbox)
+	}
 }
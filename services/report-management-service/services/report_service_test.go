@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"reportmaxxing/services/report-management-service/models"
+)
+
+func TestCreateReportKeepsTheEventWhenKafkaIsDown(t *testing.T) {
+	store := newFakeStore()
+	publisher := &fakePublisher{down: true}
+	service := NewReportService(store, publisher)
+	actor := Actor{UserID: "citizen-1", CorrelationID: "corr-1"}
+	req := models.CreateReportRequest{Title: "Overflowing bin", Description: "On Main St", Category: "SANITATION", Visibility: "PUBLIC"}
+
+	report, err := service.CreateReport(context.Background(), actor, req)
+	if err != nil {
+		t.Fatalf("CreateReport while Kafka is down: %v", err)
+	}
+	if report.UserID != "citizen-1" || report.CreatedByID != "citizen-1" || report.IntakeChannel != models.IntakeChannelApp {
+		t.Errorf("report = %+v, want it filed by and for the citizen through the app", report)
+	}
+	if pending := store.unpublished(); len(pending) != 1 {
+		t.Fatalf("unpublished = %v, want the created event left in the outbox", pending)
+	}
+
+	publisher.down = false
+	if _, err := service.CreateReport(context.Background(), actor, req); err != nil {
+		t.Fatalf("CreateReport: %v", err)
+	}
+	if pending := store.unpublished(); len(pending) != 1 {
+		t.Errorf("unpublished = %v, want the new event marked published", pending)
+	}
+}
+
+func TestMutationsRollBackOnError(t *testing.T) {
+	store := newFakeStore()
+	for _, report := range []models.Report{
+		{ID: "RPT-1", UserID: "citizen-1", Status: models.StatusOpen},
+		{ID: "RPT-2", UserID: "citizen-2", Status: models.StatusResolved, MergedIntoID: "RPT-3"},
+		{ID: "RPT-3", UserID: "citizen-2", Status: models.StatusOpen},
+	} {
+		if err := store.Reports().Create(context.Background(), &report); err != nil {
+			t.Fatal(err)
+		}
+	}
+	service := NewReportService(store, &fakePublisher{})
+	actor := Actor{UserID: "supervisor-1"}
+
+	if _, err := service.MergeReport(context.Background(), actor, "RPT-1", "RPT-2"); !errors.Is(err, ErrInvalidMerge) {
+		t.Fatalf("merge into a merged report: err = %v, want %v", err, ErrInvalidMerge)
+	}
+	if _, err := service.ReopenReport(context.Background(), actor, "RPT-1"); !errors.Is(err, ErrInvalidTransition) {
+		t.Fatalf("reopen an open report: err = %v, want %v", err, ErrInvalidTransition)
+	}
+	report, _ := store.Reports().Get(context.Background(), "RPT-1")
+	if report.Status != models.StatusOpen || report.MergedIntoID != "" {
+		t.Errorf("report = %+v, want it unchanged", report)
+	}
+	if len(store.outbox) != 0 {
+		t.Errorf("outbox = %+v, want no events from failed mutations", store.outbox)
+	}
+
+	merged, err := service.MergeReport(context.Background(), actor, "RPT-1", "RPT-3")
+	if err != nil {
+		t.Fatalf("MergeReport: %v", err)
+	}
+	if merged.Status != models.StatusResolved || merged.MergedIntoID != "RPT-3" {
+		t.Errorf("merged report = %+v, want it resolved into RPT-3", merged)
+	}
+	reopened, err := service.ReopenReport(context.Background(), actor, "RPT-1")
+	if err != nil {
+		t.Fatalf("ReopenReport: %v", err)
+	}
+	if reopened.Status != models.StatusOpen || reopened.MergedIntoID != "" {
+		t.Errorf("reopened report = %+v, want it open and no longer merged", reopened)
+	}
+	if pending := store.unpublished(); len(store.outbox) != 2 || len(pending) != 0 {
+		t.Errorf("outbox has %d events, %d unpublished; want 2 published", len(store.outbox), len(pending))
+	}
+}
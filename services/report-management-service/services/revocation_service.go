@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	kafkago "github.com/segmentio/kafka-go"
+
+	"reportmaxxing/services/report-management-service/logging"
+	"reportmaxxing/services/report-management-service/models"
+	"reportmaxxing/services/report-management-service/repository"
+)
+
+const (
+	RevocationSourceAdmin    = "admin"
+	RevocationSourceKeycloak = "keycloak"
+
+	keycloakEventSource = "keycloak"
+)
+
+var ErrInvalidRevocation = errors.New("exactly one of subject or session_id is required")
+
+type RevocationService struct {
+	store repository.Store
+}
+
+func NewRevocationService(store repository.Store) *RevocationService {
+	return &RevocationService{store: store}
+}
+
+func (s *RevocationService) ListRevocations(ctx context.Context) ([]models.TokenRevocation, error) {
+	return s.store.Revocations().List(ctx)
+}
+
+func (s *RevocationService) Revoke(ctx context.Context, actor Actor, req models.RevokeTokensRequest) (*models.TokenRevocation, error) {
+	if (req.Subject == "") == (req.SessionID == "") {
+		return nil, ErrInvalidRevocation
+	}
+	return s.create(ctx, s.store, models.TokenRevocation{
+		Subject:   req.Subject,
+		SessionID: req.SessionID,
+		Reason:    req.Reason,
+		Source:    RevocationSourceAdmin,
+		RevokedBy: actor.UserID,
+	})
+}
+
+func (s *RevocationService) create(ctx context.Context, store repository.Store, revocation models.TokenRevocation) (*models.TokenRevocation, error) {
+	revocation.ID = uuid.New().String()
+	revocation.CreatedAt = time.Now()
+	if err := store.Revocations().Create(ctx, &revocation); err != nil {
+		return nil, err
+	}
+	logging.FromContext(ctx).Info("revocations: revoked", "subject", revocation.Subject, "session_id", revocation.SessionID, "source", revocation.Source)
+	return &revocation, nil
+}
+
+// DeleteRevocation lifts a revocation. Tokens issued before a lifted subject
+// revocation become valid again if they haven't expired.
+func (s *RevocationService) DeleteRevocation(ctx context.Context, id string) error {
+	return s.store.Revocations().Delete(ctx, id)
+}
+
+// keycloakEvent covers both Keycloak event kinds as exported by event
+// listener SPIs: admin events (operationType/resourceType) and user events
+// (type).
+type keycloakEvent struct {
+	ID             string `json:"id"`
+	Type           string `json:"type"`
+	UserID         string `json:"userId"`
+	SessionID      string `json:"sessionId"`
+	OperationType  string `json:"operationType"`
+	ResourceType   string `json:"resourceType"`
+	ResourcePath   string `json:"resourcePath"`
+	Representation string `json:"representation"`
+}
+
+// HandleKeycloakEvent is the kafka.MessageHandler for Keycloak events. It
+// revokes subjects that are deleted, disabled or logged out by an admin, and
+// sessions that end.
+func (s *RevocationService) HandleKeycloakEvent(ctx context.Context, msg kafkago.Message) error {
+	var event keycloakEvent
+	if err := json.Unmarshal(msg.Value, &event); err != nil {
+		return fmt.Errorf("invalid keycloak event: %w", err)
+	}
+	if event.ID == "" {
+		return errors.New("keycloak event is missing id")
+	}
+
+	revocation, reason := keycloakRevocation(event)
+	if revocation == nil {
+		return nil
+	}
+	revocation.Reason = reason
+	revocation.Source = RevocationSourceKeycloak
+
+	// Claiming the event and revoking commit together, so a redelivery
+	// either sees the claim or finds nothing revoked.
+	return s.store.Transaction(ctx, func(tx repository.Store) error {
+		claimed, err := tx.ProcessedEvents().Claim(ctx, keycloakEventSource, event.ID)
+		if err != nil || !claimed {
+			return err
+		}
+		_, err = s.create(ctx, tx, *revocation)
+		return err
+	})
+}
+
+func keycloakRevocation(event keycloakEvent) (*models.TokenRevocation, string) {
+	if event.Type == "LOGOUT" && event.SessionID != "" {
+		return &models.TokenRevocation{SessionID: event.SessionID}, "session logged out"
+	}
+
+	path := strings.Split(strings.Trim(event.ResourcePath, "/"), "/")
+	switch {
+	case event.ResourceType == "USER" && len(path) >= 2 && path[0] == "users":
+		subject := &models.TokenRevocation{Subject: path[1]}
+		switch {
+		case event.OperationType == "DELETE" && len(path) == 2:
+			return subject, "user deleted"
+		case event.OperationType == "UPDATE" && len(path) == 2 && userDisabled(event.Representation):
+			return subject, "user disabled"
+		case event.OperationType == "ACTION" && len(path) == 3 && path[2] == "logout":
+			return subject, "user logged out by admin"
+		}
+	case event.ResourceType == "USER_SESSION" && event.OperationType == "DELETE" && len(path) == 2 && path[0] == "sessions":
+		return &models.TokenRevocation{SessionID: path[1]}, "session deleted by admin"
+	}
+	return nil, ""
+}
+
+func userDisabled(representation string) bool {
+	var user struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if err := json.Unmarshal([]byte(representation), &user); err != nil {
+		return false
+	}
+	return user.Enabled != nil && !*user.Enabled
+}
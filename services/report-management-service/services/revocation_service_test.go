@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	kafkago "github.com/segmentio/kafka-go"
+
+	"reportmaxxing/services/report-management-service/models"
+	"reportmaxxing/services/report-management-service/repository"
+)
+
+func TestHandleKeycloakEvent(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   keycloakEvent
+		subject string
+		session string
+	}{
+		{name: "user deleted", event: keycloakEvent{ResourceType: "USER", OperationType: "DELETE", ResourcePath: "users/u-1"}, subject: "u-1"},
+		{name: "user disabled", event: keycloakEvent{ResourceType: "USER", OperationType: "UPDATE", ResourcePath: "users/u-1", Representation: `{"enabled":false}`}, subject: "u-1"},
+		{name: "user logged out by admin", event: keycloakEvent{ResourceType: "USER", OperationType: "ACTION", ResourcePath: "users/u-1/logout"}, subject: "u-1"},
+		{name: "session deleted", event: keycloakEvent{ResourceType: "USER_SESSION", OperationType: "DELETE", ResourcePath: "sessions/s-1"}, session: "s-1"},
+		{name: "logout", event: keycloakEvent{Type: "LOGOUT", SessionID: "s-1"}, session: "s-1"},
+		{name: "user updated", event: keycloakEvent{ResourceType: "USER", OperationType: "UPDATE", ResourcePath: "users/u-1", Representation: `{"enabled":true}`}},
+		{name: "login", event: keycloakEvent{Type: "LOGIN", UserID: "u-1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newFakeStore()
+			service := NewRevocationService(store)
+			tt.event.ID = "kc-1"
+			value, err := json.Marshal(tt.event)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// The redelivery is skipped.
+			for i := 0; i < 2; i++ {
+				if err := service.HandleKeycloakEvent(context.Background(), kafkago.Message{Value: value}); err != nil {
+					t.Fatalf("HandleKeycloakEvent: %v", err)
+				}
+			}
+
+			revocations, _ := service.ListRevocations(context.Background())
+			if tt.subject == "" && tt.session == "" {
+				if len(revocations) != 0 {
+					t.Errorf("revocations = %+v, want none", revocations)
+				}
+				return
+			}
+			if len(revocations) != 1 {
+				t.Fatalf("revocations = %+v, want exactly one", revocations)
+			}
+			got := revocations[0]
+			if got.Subject != tt.subject || got.SessionID != tt.session || got.Source != RevocationSourceKeycloak {
+				t.Errorf("revocation = %+v, want subject %q, session %q from keycloak", got, tt.subject, tt.session)
+			}
+		})
+	}
+}
+
+func TestRevokeAndDeleteRevocation(t *testing.T) {
+	store := newFakeStore()
+	service := NewRevocationService(store)
+	ctx := context.Background()
+	admin := Actor{UserID: "admin-1"}
+
+	for _, req := range []models.RevokeTokensRequest{{}, {Subject: "u-1", SessionID: "s-1"}} {
+		if _, err := service.Revoke(ctx, admin, req); !errors.Is(err, ErrInvalidRevocation) {
+			t.Errorf("Revoke(%+v): err = %v, want %v", req, err, ErrInvalidRevocation)
+		}
+	}
+
+	revocation, err := service.Revoke(ctx, admin, models.RevokeTokensRequest{Subject: "u-1", Reason: "lost phone"})
+	if err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if revocation.RevokedBy != "admin-1" || revocation.Source != RevocationSourceAdmin {
+		t.Errorf("revocation = %+v, want it attributed to the admin", revocation)
+	}
+	if err := service.DeleteRevocation(ctx, revocation.ID); err != nil {
+		t.Fatalf("DeleteRevocation: %v", err)
+	}
+	if err := service.DeleteRevocation(ctx, revocation.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("DeleteRevocation twice: err = %v, want ErrNotFound", err)
+	}
+}
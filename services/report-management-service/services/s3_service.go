@@ -3,38 +3,47 @@ package services
 import (
 	"context"
 	"fmt"
-	"log"
-	"os"
 	"path"
 	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"reportmaxxing/services/report-management-service/logging"
+	"reportmaxxing/services/report-management-service/metrics"
+	"reportmaxxing/services/report-management-service/tracing"
 )
 
+type S3Config struct {
+	Endpoint      string
+	PublicBaseURL string
+	Region        string
+	AccessKey     string
+	SecretKey     string
+	Bucket        string
+}
+
 type S3Service struct {
+	client        *s3.Client
 	presign       *s3.PresignClient
 	bucket        string
 	publicBaseURL string
 }
 
-func NewS3ServiceFromEnv() (*S3Service, error) {
-	endpoint := getEnvDefault("S3_ENDPOINT", "http://localhost:9001")
-	publicBaseURL := getEnvDefault("S3_PUBLIC_BASE_URL", endpoint)
-	region := getEnvDefault("S3_REGION", "us-east-1")
-	accessKey := getEnvDefault("S3_ACCESS_KEY", "minioadmin")
-	secretKey := getEnvDefault("S3_SECRET_KEY", "minioadmin")
-	bucket := getEnvDefault("S3_BUCKET", "report-images")
+func NewS3Service(s3Config S3Config) (*S3Service, error) {
+	endpoint := s3Config.Endpoint
 
-	cfg, err := config.LoadDefaultConfig(
+	cfg, err := awsconfig.LoadDefaultConfig(
 		context.Background(),
-		config.WithRegion(region),
-		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKey, secretKey, "")),
-		config.WithEndpointResolverWithOptions(aws.EndpointResolverWithOptionsFunc(
+		awsconfig.WithRegion(s3Config.Region),
+		awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(s3Config.AccessKey, s3Config.SecretKey, "")),
+		awsconfig.WithEndpointResolverWithOptions(aws.EndpointResolverWithOptionsFunc(
 			func(service, region string, _ ...interface{}) (aws.Endpoint, error) {
 				if service == s3.ServiceID {
 					return aws.Endpoint{
@@ -56,9 +65,10 @@ func NewS3ServiceFromEnv() (*S3Service, error) {
 	})
 
 	return &S3Service{
+		client:        client,
 		presign:       s3.NewPresignClient(client),
-		bucket:        bucket,
-		publicBaseURL: strings.TrimRight(publicBaseURL, "/"),
+		bucket:        s3Config.Bucket,
+		publicBaseURL: strings.TrimRight(s3Config.PublicBaseURL, "/"),
 	}, nil
 }
 
@@ -76,25 +86,32 @@ func (s *S3Service) GenerateUploadURL(ctx context.Context, userID, fileName, con
 		ContentType: aws.String(contentType),
 	}
 
+	ctx, span := tracing.Tracer().Start(ctx, "s3 PresignPutObject", trace.WithAttributes(
+		attribute.String("aws.s3.bucket", s.bucket),
+		attribute.String("aws.s3.key", objectKey),
+	))
+	defer span.End()
+
 	presigned, err := s.presign.PresignPutObject(ctx, input, s3.WithPresignExpires(10*time.Minute))
+	metrics.ObservePresign(err)
 	if err != nil {
-		log.Printf("s3-presign: failed bucket=%s object_key=%s content_type=%s err=%v",
-			s.bucket,
-			objectKey,
-			contentType,
-			err,
+		tracing.Fail(span, err)
+		logging.FromContext(ctx).Error("s3-presign: failed",
+			"bucket", s.bucket,
+			"object_key", objectKey,
+			"content_type", contentType,
+			"error", err,
 		)
 		return "", "", "", err
 	}
 
 	imageURL := fmt.Sprintf("%s/%s/%s", s.publicBaseURL, s.bucket, objectKey)
-	log.Printf("s3-presign: success bucket=%s object_key=%s", s.bucket, objectKey)
+	logging.FromContext(ctx).Info("s3-presign: success", "bucket", s.bucket, "object_key", objectKey)
 	return presigned.URL, imageURL, objectKey, nil
 }
 
-func getEnvDefault(key, fallback string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return fallback
+// Ping checks that the bucket exists and the credentials can reach it.
+func (s *S3Service) Ping(ctx context.Context) error {
+	_, err := s.client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(s.bucket)})
+	return err
 }
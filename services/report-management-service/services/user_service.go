@@ -0,0 +1,191 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"reportmaxxing/services/report-management-service/logging"
+	"reportmaxxing/services/report-management-service/models"
+	"reportmaxxing/services/report-management-service/policy"
+	"reportmaxxing/services/report-management-service/repository"
+)
+
+var (
+	ErrInvalidSuspension = errors.New("suspension must end in the future")
+	ErrNotSuspended      = errors.New("user is not suspended")
+	ErrSelfModeration    = errors.New("you cannot suspend your own account")
+	ErrModerationRank    = errors.New("you cannot moderate a user whose role is at or above yours")
+	ErrReporterRequired  = errors.New("reporter email or phone is required")
+	ErrReporterSuspended = errors.New("reporter is suspended")
+)
+
+type UserService struct {
+	store    repository.Store
+	producer EventPublisher
+}
+
+func NewUserService(store repository.Store, producer EventPublisher) *UserService {
+	return &UserService{store: store, producer: producer}
+}
+
+func (s *UserService) GetUserByID(ctx context.Context, userID string) (*models.User, error) {
+	return s.store.Users().Get(ctx, userID)
+}
+
+// FindOrCreateReporter resolves the resident a report is filed for by email,
+// then phone, creating a citizen record when neither matches. Residents
+// known only by phone get a placeholder address under the reserved .invalid
+// domain, since users.email is required and unique.
+func (s *UserService) FindOrCreateReporter(ctx context.Context, req models.ReporterRequest) (*models.User, error) {
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	phone := models.NormalizePhone(req.Phone)
+	if email == "" && phone == "" {
+		return nil, ErrReporterRequired
+	}
+
+	users := s.store.Users()
+	var user *models.User
+	err := repository.ErrNotFound
+	if email != "" {
+		user, err = users.FindByEmail(ctx, email)
+	}
+	if errors.Is(err, repository.ErrNotFound) && phone != "" {
+		user, err = users.FindByPhone(ctx, phone)
+	}
+	switch {
+	case err == nil:
+		if user.Suspended(time.Now()) {
+			return nil, ErrReporterSuspended
+		}
+		return user, nil
+	case !errors.Is(err, repository.ErrNotFound):
+		return nil, err
+	}
+
+	if email == "" {
+		email = strings.TrimPrefix(phone, "+") + "@phone.invalid"
+	}
+	user = &models.User{
+		ID:    uuid.New().String(),
+		Email: email,
+		Name:  strings.TrimSpace(req.Name),
+		Phone: phone,
+	}
+	if err := users.Create(ctx, user); err != nil {
+		return nil, err
+	}
+	logging.FromContext(ctx).Info("users: created reporter", "reporter_id", user.ID)
+	return user, nil
+}
+
+func (s *UserService) GetModerationHistory(ctx context.Context, userID string) ([]models.UserModerationAction, error) {
+	return s.store.Users().ListModerationActions(ctx, userID)
+}
+
+func (s *UserService) SuspendUser(ctx context.Context, actor Actor, userID string, req models.SuspendUserRequest) (*models.User, error) {
+	if actor.UserID == userID {
+		return nil, ErrSelfModeration
+	}
+	if req.Until != nil && !req.Until.After(time.Now()) {
+		return nil, ErrInvalidSuspension
+	}
+
+	return s.moderate(ctx, actor, userID, models.ModerationActionSuspend, req.Reason, req.Until, func(user *models.User) error {
+		user.Status = models.UserStatusSuspended
+		user.SuspendedUntil = req.Until
+		user.SuspensionReason = req.Reason
+		return nil
+	})
+}
+
+func (s *UserService) UnsuspendUser(ctx context.Context, actor Actor, userID string, req models.UnsuspendUserRequest) (*models.User, error) {
+	return s.moderate(ctx, actor, userID, models.ModerationActionUnsuspend, req.Reason, nil, func(user *models.User) error {
+		if user.Status != models.UserStatusSuspended {
+			return ErrNotSuspended
+		}
+		user.Status = models.UserStatusActive
+		user.SuspendedUntil = nil
+		user.SuspensionReason = ""
+		return nil
+	})
+}
+
+// moderate applies fn to the locked user row and records the audit entry and
+// outbox event in the same transaction. The event is published after commit.
+// Only users whose roles, as of their last sign-in, rank below the actor's
+// can be moderated.
+func (s *UserService) moderate(ctx context.Context, actor Actor, userID, action, reason string, until *time.Time, fn func(user *models.User) error) (*models.User, error) {
+	eventType := models.EventTypeUserSuspended
+	if action == models.ModerationActionUnsuspend {
+		eventType = models.EventTypeUserUnsuspended
+	}
+
+	var user *models.User
+	var event *models.UserModerationEvent
+	var outbox *models.OutboxEvent
+
+	err := s.store.Transaction(ctx, func(tx repository.Store) error {
+		var err error
+		user, err = tx.Users().Lock(ctx, userID)
+		if err != nil {
+			return err
+		}
+		if policy.RoleRank(user.Roles) >= policy.RoleRank(actor.Roles) {
+			return ErrModerationRank
+		}
+		if err := fn(user); err != nil {
+			return err
+		}
+		if err := tx.Users().SaveModeration(ctx, user); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		record := models.UserModerationAction{
+			ID:             uuid.New().String(),
+			UserID:         user.ID,
+			Action:         action,
+			Reason:         reason,
+			SuspendedUntil: until,
+			ActorID:        actor.UserID,
+			CorrelationID:  actor.CorrelationID,
+			CreatedAt:      now,
+		}
+		if err := tx.Users().CreateModerationAction(ctx, &record); err != nil {
+			return err
+		}
+
+		event = &models.UserModerationEvent{
+			EventID:        uuid.New().String(),
+			EventType:      eventType,
+			Timestamp:      now,
+			UserID:         user.ID,
+			ActorID:        actor.UserID,
+			ActorClientID:  actor.ClientID,
+			CorrelationID:  actor.CorrelationID,
+			Reason:         reason,
+			SuspendedUntil: until,
+		}
+		outbox, err = newOutboxEvent(event.EventID, event.EventType, eventType, user.ID, event)
+		if err != nil {
+			return err
+		}
+		return tx.Outbox().Create(ctx, outbox)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.producer.PublishUserModerationEvent(ctx, event); err != nil {
+		logging.FromContext(ctx).Warn("kafka publish failed, left in outbox", "event_id", outbox.ID, "error", err)
+	} else {
+		markPublished(ctx, s.store, outbox)
+	}
+
+	logging.FromContext(ctx).Info("users: moderated", "event_type", eventType, "target_user_id", user.ID, "actor_id", actor.UserID)
+	return user, nil
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"reportmaxxing/services/report-management-service/models"
+)
+
+func TestSuspendUserChecksTheActor(t *testing.T) {
+	store := newFakeStore()
+	for _, user := range []models.User{
+		{ID: "citizen-1", Email: "citizen@example.test", Roles: []string{"CITIZEN"}},
+		{ID: "staff-1", Email: "staff@example.test", Roles: []string{"DEPARTMENT_STAFF"}},
+		{ID: "supervisor-1", Email: "supervisor@example.test", Roles: []string{"SUPERVISOR"}},
+		{ID: "supervisor-2", Email: "supervisor2@example.test", Roles: []string{"SUPERVISOR"}},
+		{ID: "admin-1", Email: "admin@example.test", Roles: []string{"ADMIN"}},
+		{ID: "reporter-1", Email: "reporter@example.test"},
+	} {
+		if err := store.Users().Create(context.Background(), &user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	service := NewUserService(store, &fakePublisher{})
+	supervisor := Actor{UserID: "supervisor-1", Roles: []string{"SUPERVISOR"}}
+	admin := Actor{UserID: "admin-1", Roles: []string{"ADMIN"}}
+
+	tests := []struct {
+		name    string
+		actor   Actor
+		target  string
+		wantErr error
+	}{
+		{name: "self", actor: supervisor, target: "supervisor-1", wantErr: ErrSelfModeration},
+		{name: "same role", actor: supervisor, target: "supervisor-2", wantErr: ErrModerationRank},
+		{name: "higher role", actor: supervisor, target: "admin-1", wantErr: ErrModerationRank},
+		{name: "actor without roles", actor: Actor{UserID: "partner"}, target: "citizen-1", wantErr: ErrModerationRank},
+		{name: "lower role", actor: supervisor, target: "staff-1"},
+		{name: "citizen", actor: supervisor, target: "citizen-1"},
+		{name: "reporter who never signed in", actor: supervisor, target: "reporter-1"},
+		{name: "admin over supervisor", actor: admin, target: "supervisor-2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := service.SuspendUser(context.Background(), tt.actor, tt.target, models.SuspendUserRequest{Reason: "spam"})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("SuspendUser: err = %v, want %v", err, tt.wantErr)
+				}
+				stored, _ := store.Users().Get(context.Background(), tt.target)
+				if stored.Status == models.UserStatusSuspended {
+					t.Error("target suspended despite the error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SuspendUser: %v", err)
+			}
+			if !user.Suspended(time.Now()) {
+				t.Error("target not suspended")
+			}
+		})
+	}
+}
+
+func TestUnsuspendUserChecksTheActor(t *testing.T) {
+	store := newFakeStore()
+	until := time.Now().Add(time.Hour)
+	err := store.Users().Create(context.Background(), &models.User{
+		ID: "supervisor-2", Email: "supervisor2@example.test", Roles: []string{"SUPERVISOR"},
+		Status: models.UserStatusSuspended, SuspendedUntil: &until,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	service := NewUserService(store, &fakePublisher{})
+
+	_, err = service.UnsuspendUser(context.Background(), Actor{UserID: "supervisor-1", Roles: []string{"SUPERVISOR"}}, "supervisor-2", models.UnsuspendUserRequest{Reason: "appeal"})
+	if !errors.Is(err, ErrModerationRank) {
+		t.Fatalf("UnsuspendUser by a peer: err = %v, want ErrModerationRank", err)
+	}
+	if _, err := service.UnsuspendUser(context.Background(), Actor{UserID: "admin-1", Roles: []string{"ADMIN"}}, "supervisor-2", models.UnsuspendUserRequest{Reason: "appeal"}); err != nil {
+		t.Fatalf("UnsuspendUser by an admin: %v", err)
+	}
+}
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	kafkago "github.com/segmentio/kafka-go"
+
+	"reportmaxxing/services/report-management-service/logging"
+	"reportmaxxing/services/report-management-service/models"
+	"reportmaxxing/services/report-management-service/repository"
+	"reportmaxxing/services/report-management-service/tracing"
+)
+
+const (
+	// WorkOrderActorID is recorded as the actor on status changes that
+	// originate from the work-order system.
+	WorkOrderActorID = "system:workorders"
+
+	workOrderEventSource = "workorders"
+)
+
+var ErrUnknownWorkOrder = errors.New("work order is not linked to a report")
+
+// workOrderStatuses maps external work-order states to report statuses.
+// States not listed here (e.g. CANCELLED) are acknowledged but ignored.
+var workOrderStatuses = map[string]models.ReportStatus{
+	"ASSIGNED":    StatusInProgress,
+	"SCHEDULED":   StatusInProgress,
+	"DISPATCHED":  StatusInProgress,
+	"IN_PROGRESS": StatusInProgress,
+	"ON_HOLD":     StatusInProgress,
+	"COMPLETED":   StatusResolved,
+	"CLOSED":      StatusResolved,
+	"REOPENED":    StatusOpen,
+}
+
+type WorkOrderService struct {
+	store         repository.Store
+	reportService *ReportService
+}
+
+func NewWorkOrderService(store repository.Store, reportService *ReportService) *WorkOrderService {
+	return &WorkOrderService{store: store, reportService: reportService}
+}
+
+func (s *WorkOrderService) LinkWorkOrder(ctx context.Context, reportID, workOrderID string) (*models.WorkOrderLink, error) {
+	if _, err := s.reportService.GetReportByID(reportID); err != nil {
+		return nil, err
+	}
+
+	link := models.WorkOrderLink{
+		WorkOrderID: workOrderID,
+		ReportID:    reportID,
+	}
+	if err := s.store.WorkOrders().Link(ctx, &link); err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// HandleMessage is the kafka.MessageHandler for the workorders.updated topic.
+func (s *WorkOrderService) HandleMessage(ctx context.Context, msg kafkago.Message) error {
+	var event models.WorkOrderUpdatedEvent
+	if err := json.Unmarshal(msg.Value, &event); err != nil {
+		return fmt.Errorf("invalid work order event: %w", err)
+	}
+	if event.EventID == "" || event.WorkOrderID == "" || event.State == "" {
+		return errors.New("work order event is missing event_id, work_order_id or state")
+	}
+
+	return s.ApplyUpdate(ctx, event)
+}
+
+// ApplyUpdate applies an external work-order update to its linked report.
+// Processing is idempotent per event ID: the event is claimed, the report
+// changed and the link updated in one transaction. Updates older than the
+// last one applied to the work order are dropped, so a late redelivery can't
+// move the report back.
+func (s *WorkOrderService) ApplyUpdate(ctx context.Context, event models.WorkOrderUpdatedEvent) error {
+	state := strings.ToUpper(event.State)
+	var publish func()
+
+	err := s.store.Transaction(ctx, func(tx repository.Store) error {
+		claimed, err := tx.ProcessedEvents().Claim(ctx, workOrderEventSource, event.EventID)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			logging.FromContext(ctx).Info("workorders: skipping duplicate", "event_id", event.EventID)
+			return nil
+		}
+
+		link, err := s.lockLink(ctx, tx, event)
+		if err != nil {
+			return err
+		}
+		if !event.UpdatedAt.IsZero() && link.LastEventAt != nil && event.UpdatedAt.Before(*link.LastEventAt) {
+			logging.FromContext(ctx).Info("workorders: dropping stale update",
+				"event_id", event.EventID,
+				"work_order_id", event.WorkOrderID,
+				"updated_at", event.UpdatedAt,
+				"last_event_at", *link.LastEventAt,
+			)
+			return nil
+		}
+
+		if target, mapped := workOrderStatuses[state]; mapped {
+			publish, err = s.transition(ctx, tx, link.ReportID, target, event)
+			if err != nil {
+				return err
+			}
+		} else {
+			logging.FromContext(ctx).Info("workorders: ignoring state", "state", state, "work_order_id", event.WorkOrderID)
+		}
+
+		return tx.WorkOrders().RecordState(ctx, link, state, event.UpdatedAt)
+	})
+	if err != nil {
+		return err
+	}
+
+	if publish != nil {
+		publish()
+	}
+	return nil
+}
+
+// lockLink loads the event's work-order link and holds its row lock until tx
+// ends, linking the work order to event.ReportID first if it is new.
+func (s *WorkOrderService) lockLink(ctx context.Context, tx repository.Store, event models.WorkOrderUpdatedEvent) (*models.WorkOrderLink, error) {
+	if event.ReportID != "" {
+		if _, err := tx.Reports().Lock(ctx, event.ReportID); err != nil {
+			return nil, err
+		}
+		err := tx.WorkOrders().LinkIfMissing(ctx, &models.WorkOrderLink{
+			WorkOrderID: event.WorkOrderID,
+			ReportID:    event.ReportID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	link, err := tx.WorkOrders().Lock(ctx, event.WorkOrderID)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownWorkOrder, event.WorkOrderID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// transition changes the report's status in tx and returns the function
+// that publishes the change once tx has committed, or nil if nothing
+// changed.
+func (s *WorkOrderService) transition(ctx context.Context, tx repository.Store, reportID string, target models.ReportStatus, event models.WorkOrderUpdatedEvent) (func(), error) {
+	report, err := tx.Reports().Lock(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+	if report.Status == target {
+		return nil, nil
+	}
+	// Withdrawn and merged reports are closed on our side; the crew's
+	// system has no say over them.
+	if report.Status == models.StatusWithdrawn || report.MergedIntoID != "" {
+		logging.FromContext(ctx).Info("workorders: report is closed, ignoring state", "report_id", reportID, "state", event.State)
+		return nil, nil
+	}
+
+	// Keep the correlation ID of the request that started the chain when the
+	// work-order system propagated it; otherwise correlate by its event ID.
+	correlationID := tracing.CorrelationIDFromContext(ctx)
+	if correlationID == "" {
+		correlationID = event.EventID
+		ctx = tracing.WithCorrelationID(ctx, correlationID)
+	}
+
+	actor := Actor{UserID: WorkOrderActorID, CorrelationID: correlationID}
+	statusEvent, outbox, err := changeStatus(ctx, tx, actor, report, target)
+	if err != nil {
+		return nil, err
+	}
+	return func() {
+		s.reportService.publishStatusChanged(ctx, statusEvent, outbox)
+		logging.FromContext(ctx).Info("workorders: status updated", "report_id", reportID, "status", target, "work_order_id", event.WorkOrderID)
+	}, nil
+}
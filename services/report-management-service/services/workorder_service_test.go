@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"reportmaxxing/services/report-management-service/models"
+)
+
+func TestApplyWorkOrderUpdate(t *testing.T) {
+	store := newFakeStore()
+	err := store.Reports().Create(context.Background(), &models.Report{ID: "RPT-1", UserID: "citizen-1", Status: models.StatusOpen})
+	if err != nil {
+		t.Fatal(err)
+	}
+	publisher := &fakePublisher{}
+	service := NewWorkOrderService(store, NewReportService(store, publisher))
+	if _, err := service.LinkWorkOrder(context.Background(), "RPT-1", "WO-1"); err != nil {
+		t.Fatalf("LinkWorkOrder: %v", err)
+	}
+
+	now := time.Now()
+	apply := func(eventID, state string, updatedAt time.Time) models.ReportStatus {
+		t.Helper()
+		err := service.ApplyUpdate(context.Background(), models.WorkOrderUpdatedEvent{
+			EventID: eventID, WorkOrderID: "WO-1", State: state, UpdatedAt: updatedAt,
+		})
+		if err != nil {
+			t.Fatalf("ApplyUpdate(%s): %v", eventID, err)
+		}
+		report, err := store.Reports().Get(context.Background(), "RPT-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return report.Status
+	}
+
+	if status := apply("evt-1", "dispatched", now); status != models.StatusInProgress {
+		t.Fatalf("after DISPATCHED: status = %s, want %s", status, models.StatusInProgress)
+	}
+	if status := apply("evt-2", "COMPLETED", now.Add(time.Minute)); status != models.StatusResolved {
+		t.Fatalf("after COMPLETED: status = %s, want %s", status, models.StatusResolved)
+	}
+	if status := apply("evt-3", "REOPENED", now.Add(-time.Minute)); status != models.StatusResolved {
+		t.Errorf("stale REOPENED moved the report to %s", status)
+	}
+
+	// A redelivered event is skipped even after the report moved on.
+	store.Reports().Save(context.Background(), &models.Report{ID: "RPT-1", UserID: "citizen-1", Status: models.StatusOpen})
+	if status := apply("evt-2", "COMPLETED", now.Add(time.Minute)); status != models.StatusOpen {
+		t.Errorf("redelivered event applied again: status = %s", status)
+	}
+
+	link, err := store.WorkOrders().Lock(context.Background(), "WO-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.LastState != "COMPLETED" || !link.LastEventAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("link = %+v, want the latest applied event recorded", link)
+	}
+}
+
+func TestApplyWorkOrderUpdateForUnknownWorkOrder(t *testing.T) {
+	store := newFakeStore()
+	service := NewWorkOrderService(store, NewReportService(store, &fakePublisher{}))
+
+	err := service.ApplyUpdate(context.Background(), models.WorkOrderUpdatedEvent{EventID: "evt-1", WorkOrderID: "WO-404", State: "COMPLETED"})
+	if !errors.Is(err, ErrUnknownWorkOrder) {
+		t.Fatalf("err = %v, want %v", err, ErrUnknownWorkOrder)
+	}
+	if claimed, _ := store.ProcessedEvents().Claim(context.Background(), workOrderEventSource, "evt-1"); !claimed {
+		t.Error("event claim kept although the update failed")
+	}
+}
@@ -0,0 +1,57 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	TraceParentHeader   = "traceparent"
+	TraceIDHeader       = "X-Trace-ID"
+	CorrelationIDHeader = "X-Correlation-ID"
+)
+
+type contextKey int
+
+const correlationIDKey contextKey = iota
+
+// MaxCorrelationIDLength matches user_moderation_actions.correlation_id.
+const MaxCorrelationIDLength = 100
+
+// ValidCorrelationID reports whether a caller-supplied ID is safe to store
+// and echo: 1 to MaxCorrelationIDLength letters, digits, '-', '_', '.' or
+// ':'.
+func ValidCorrelationID(id string) bool {
+	if id == "" || len(id) > MaxCorrelationIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey, id)
+}
+
+func CorrelationIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey).(string)
+	return id
+}
+
+// TraceID returns the trace ID of the span in ctx, or "" when there is none.
+func TraceID(ctx context.Context) string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.HasTraceID() {
+		return ""
+	}
+	return sc.TraceID().String()
+}